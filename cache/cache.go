@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long entries of a namespace without a configured TTL are kept
+const DefaultTTL = 24 * time.Hour
+
+// Cache stores values by namespace and key until they expire or are invalidated
+type Cache interface {
+	// Get returns the value stored under a key, if it exists and has not expired
+	Get(namespace, key string) ([]byte, bool)
+
+	// Set stores a value under a key, replacing any previous value, and tags it for invalidation
+	Set(namespace, key string, value []byte, tags ...string)
+
+	// Invalidate removes every entry carrying one of the given tags
+	Invalidate(tags ...string)
+
+	// Purge removes expired entries and enforces the size limit of the cache
+	Purge()
+
+	// Stats returns the hit and miss counters of every namespace
+	Stats() map[string]Stats
+}
+
+// Stats represents the counters of a single cache namespace
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Sets      int64 `json:"sets"`
+	Evictions int64 `json:"evictions"`
+}
+
+// TTLs maps namespaces to how long their entries are kept
+type TTLs map[string]time.Duration
+
+// For returns the TTL of a namespace, falling back to DefaultTTL
+func (t TTLs) For(namespace string) time.Duration {
+	if ttl, ok := t[namespace]; ok && ttl > 0 {
+		return ttl
+	}
+	return DefaultTTL
+}
+
+// UserTag returns the tag of entries fetched on behalf of a user
+func UserTag(user string) string {
+	return "user:" + user
+}
+
+// RepositoryTag returns the tag of entries read from a repository
+func RepositoryTag(owner, repo string) string {
+	return fmt.Sprintf("repository:%s/%s", owner, repo)
+}
+
+// OwnerTag returns the tag of repository listings of a user or organization
+func OwnerTag(owner string) string {
+	return "owner:" + owner
+}
+
+// StartCleaner starts a goroutine to periodically purge expired entries from a cache
+func StartCleaner(c Cache, quit chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				c.Purge()
+			case <-quit:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// counters keeps the per-namespace stats shared by the cache implementations
+type counters struct {
+	lock  sync.Mutex
+	stats map[string]*Stats
+}
+
+// count applies an update to the stats of a namespace
+func (c *counters) count(namespace string, update func(*Stats)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stats == nil {
+		c.stats = map[string]*Stats{}
+	}
+	s, ok := c.stats[namespace]
+	if !ok {
+		s = &Stats{}
+		c.stats[namespace] = s
+	}
+	update(s)
+}
+
+// snapshot returns a copy of the stats of every namespace
+func (c *counters) snapshot() map[string]Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	result := make(map[string]Stats, len(c.stats))
+	for namespace, s := range c.stats {
+		result[namespace] = *s
+	}
+	return result
+}
+
+// logError reports a failure of the on-disk cache, which is treated as a miss
+func logError(action string, err error) {
+	log.Printf("Failed to %s cache entry: %v", action, err)
+}
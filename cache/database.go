@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"static-admin/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Database is a cache stored in the application database so that entries survive restarts
+type Database struct {
+	counters
+
+	db         *gorm.DB
+	maxEntries int
+	ttls       TTLs
+}
+
+// NewDatabase creates a cache stored in the given database, holding at most maxEntries entries
+func NewDatabase(db *gorm.DB, maxEntries int, ttls TTLs) *Database {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	return &Database{
+		db:         db,
+		maxEntries: maxEntries,
+		ttls:       ttls,
+	}
+}
+
+// Get returns the value stored under a key, if it exists and has not expired
+func (d *Database) Get(namespace, key string) ([]byte, bool) {
+	var entry database.CacheEntry
+	err := d.db.Where("namespace = ? AND key = ? AND expires_at > ?", namespace, key, time.Now()).
+		Limit(1).Find(&entry).Error
+	if err != nil {
+		logError("read", err)
+	}
+	if err != nil || entry.ID == 0 {
+		d.count(namespace, func(s *Stats) { s.Misses++ })
+		return nil, false
+	}
+
+	d.count(namespace, func(s *Stats) { s.Hits++ })
+	return entry.Value, true
+}
+
+// Set stores a value under a key, replacing any previous value, and tags it for invalidation
+func (d *Database) Set(namespace, key string, value []byte, tags ...string) {
+	entry := database.CacheEntry{
+		Namespace: namespace,
+		Key:       key,
+		Value:     value,
+		Tags:      database.StringSliceValue(tags),
+		ExpiresAt: time.Now().Add(d.ttls.For(namespace)),
+	}
+	if entry.Tags == nil {
+		entry.Tags = database.StringSliceValue{}
+	}
+
+	err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "namespace"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "tags", "expires_at", "updated_at"}),
+	}).Create(&entry).Error
+	if err != nil {
+		logError("write", err)
+		return
+	}
+	d.count(namespace, func(s *Stats) { s.Sets++ })
+}
+
+// Invalidate removes every entry carrying one of the given tags
+func (d *Database) Invalidate(tags ...string) {
+	for _, tag := range tags {
+		// tags are stored as a JSON array, so a tag matches as a quoted JSON string
+		quoted, err := json.Marshal(tag)
+		if err != nil {
+			continue
+		}
+		err = d.db.Where("tags LIKE ? ESCAPE '\\'", "%"+escapeLike(string(quoted))+"%").
+			Delete(&database.CacheEntry{}).Error
+		if err != nil {
+			logError("invalidate", err)
+		}
+	}
+}
+
+// Purge removes expired entries and the least recently written entries above the size limit
+func (d *Database) Purge() {
+	if err := d.db.Where("expires_at <= ?", time.Now()).Delete(&database.CacheEntry{}).Error; err != nil {
+		logError("purge", err)
+		return
+	}
+
+	var count int64
+	if err := d.db.Model(&database.CacheEntry{}).Count(&count).Error; err != nil {
+		logError("count", err)
+		return
+	}
+	if count <= int64(d.maxEntries) {
+		return
+	}
+
+	var evicted []database.CacheEntry
+	err := d.db.Select("id", "namespace").Order("updated_at ASC").
+		Limit(int(count) - d.maxEntries).Find(&evicted).Error
+	if err != nil {
+		logError("evict", err)
+		return
+	}
+
+	ids := make([]uint, len(evicted))
+	for i, entry := range evicted {
+		ids[i] = entry.ID
+	}
+	if err := d.db.Where("id IN ?", ids).Delete(&database.CacheEntry{}).Error; err != nil {
+		logError("evict", err)
+		return
+	}
+	for _, entry := range evicted {
+		d.count(entry.Namespace, func(s *Stats) { s.Evictions++ })
+	}
+}
+
+// Stats returns the hit and miss counters of every namespace
+func (d *Database) Stats() map[string]Stats {
+	return d.snapshot()
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is the number of entries the in-memory cache holds before evicting
+const DefaultMaxEntries = 10000
+
+// memoryEntry is a single value of the in-memory cache
+type memoryEntry struct {
+	namespace string
+	key       string
+	value     []byte
+	tags      []string
+	expiresAt time.Time
+}
+
+// Memory is an in-memory cache that evicts the least recently used entries above its size limit
+type Memory struct {
+	counters
+
+	lock       sync.Mutex
+	maxEntries int
+	ttls       TTLs
+	order      *list.List
+	entries    map[string]*list.Element
+	tags       map[string]map[string]struct{}
+}
+
+// NewMemory creates an in-memory cache holding at most maxEntries entries
+func NewMemory(maxEntries int, ttls TTLs) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	return &Memory{
+		maxEntries: maxEntries,
+		ttls:       ttls,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+// Get returns the value stored under a key, if it exists and has not expired
+func (m *Memory) Get(namespace, key string) ([]byte, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	element, ok := m.entries[entryKey(namespace, key)]
+	if !ok {
+		m.count(namespace, func(s *Stats) { s.Misses++ })
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.remove(element)
+		m.count(namespace, func(s *Stats) { s.Misses++ })
+		return nil, false
+	}
+
+	m.order.MoveToFront(element)
+	m.count(namespace, func(s *Stats) { s.Hits++ })
+	return entry.value, true
+}
+
+// Set stores a value under a key, replacing any previous value, and tags it for invalidation
+func (m *Memory) Set(namespace, key string, value []byte, tags ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	id := entryKey(namespace, key)
+	if element, ok := m.entries[id]; ok {
+		m.remove(element)
+	}
+
+	element := m.order.PushFront(&memoryEntry{
+		namespace: namespace,
+		key:       key,
+		value:     value,
+		tags:      tags,
+		expiresAt: time.Now().Add(m.ttls.For(namespace)),
+	})
+	m.entries[id] = element
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = map[string]struct{}{}
+		}
+		m.tags[tag][id] = struct{}{}
+	}
+	m.count(namespace, func(s *Stats) { s.Sets++ })
+
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.remove(oldest)
+		m.count(oldest.Value.(*memoryEntry).namespace, func(s *Stats) { s.Evictions++ })
+	}
+}
+
+// Invalidate removes every entry carrying one of the given tags
+func (m *Memory) Invalidate(tags ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, tag := range tags {
+		for id := range m.tags[tag] {
+			if element, ok := m.entries[id]; ok {
+				m.remove(element)
+			}
+		}
+	}
+}
+
+// Purge removes expired entries
+func (m *Memory) Purge() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	for element := m.order.Back(); element != nil; {
+		previous := element.Prev()
+		if entry := element.Value.(*memoryEntry); now.After(entry.expiresAt) {
+			m.remove(element)
+		}
+		element = previous
+	}
+}
+
+// Stats returns the hit and miss counters of every namespace
+func (m *Memory) Stats() map[string]Stats {
+	return m.snapshot()
+}
+
+// remove unlinks an entry from the list, the key map and the tag index, the lock must be held
+func (m *Memory) remove(element *list.Element) {
+	entry := element.Value.(*memoryEntry)
+	id := entryKey(entry.namespace, entry.key)
+
+	m.order.Remove(element)
+	delete(m.entries, id)
+	for _, tag := range entry.tags {
+		delete(m.tags[tag], id)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
+
+// entryKey combines a namespace and key into a single map key
+func entryKey(namespace, key string) string {
+	return namespace + "\x00" + key
+}
@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keys returns the keys of a namespace still in the cache, without touching their recency
+func (m *Memory) keys(namespace string) []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var keys []string
+	for element := m.order.Front(); element != nil; element = element.Next() {
+		if entry := element.Value.(*memoryEntry); entry.namespace == namespace {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+func TestMemoryEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		// ops are "set:<key>" and "get:<key>" operations applied in order
+		ops  []string
+		want []string
+	}{
+		{
+			name: "below the limit",
+			ops:  []string{"set:a", "set:b"},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "oldest entry is evicted",
+			ops:  []string{"set:a", "set:b", "set:c", "set:d"},
+			want: []string{"d", "c", "b"},
+		},
+		{
+			name: "reads keep entries",
+			ops:  []string{"set:a", "set:b", "set:c", "get:a", "set:d"},
+			want: []string{"d", "a", "c"},
+		},
+		{
+			name: "replacing an entry keeps it",
+			ops:  []string{"set:a", "set:b", "set:c", "set:a", "set:d"},
+			want: []string{"d", "a", "c"},
+		},
+		{
+			name: "misses do not change the order",
+			ops:  []string{"set:a", "set:b", "set:c", "get:x", "set:d"},
+			want: []string{"d", "c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory(3, nil)
+			for _, op := range tt.ops {
+				switch action, key := op[:3], op[4:]; action {
+				case "set":
+					m.Set("posts", key, []byte(key))
+				case "get":
+					m.Get("posts", key)
+				}
+			}
+
+			if got := m.keys("posts"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+			for _, key := range tt.want {
+				if value, ok := m.Get("posts", key); !ok || string(value) != key {
+					t.Errorf("Get(%s) = %q, %v", key, value, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryEvictionCounters(t *testing.T) {
+	m := NewMemory(2, nil)
+	m.Set("posts", "a", nil)
+	m.Set("trees", "b", nil)
+	m.Set("trees", "c", nil)
+	m.Set("trees", "d", nil)
+
+	stats := m.Stats()
+	if stats["posts"].Evictions != 1 || stats["trees"].Evictions != 1 {
+		t.Errorf("evictions = posts %d, trees %d, want 1 each", stats["posts"].Evictions, stats["trees"].Evictions)
+	}
+	if stats["trees"].Sets != 3 {
+		t.Errorf("sets = %d, want 3", stats["trees"].Sets)
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	m := NewMemory(10, TTLs{"short": 20 * time.Millisecond})
+	m.Set("short", "a", []byte("a"))
+	m.Set("long", "a", []byte("a"))
+
+	if _, ok := m.Get("short", "a"); !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := m.Get("short", "a"); ok {
+		t.Error("Get() returned an expired entry")
+	}
+	if _, ok := m.Get("long", "a"); !ok {
+		t.Error("entry with the default TTL expired")
+	}
+	if keys := m.keys("short"); len(keys) != 0 {
+		t.Errorf("expired entry was kept after a read: %v", keys)
+	}
+}
+
+func TestMemoryPurge(t *testing.T) {
+	m := NewMemory(10, TTLs{"short": 20 * time.Millisecond})
+	m.Set("short", "a", nil, "tag")
+	m.Set("short", "b", nil)
+	m.Set("long", "c", nil, "tag")
+	time.Sleep(40 * time.Millisecond)
+
+	m.Purge()
+	if keys := m.keys("short"); len(keys) != 0 {
+		t.Errorf("Purge() kept expired entries %v", keys)
+	}
+	if keys := m.keys("long"); !reflect.DeepEqual(keys, []string{"c"}) {
+		t.Errorf("Purge() removed live entries, left %v", keys)
+	}
+	if ids := m.tags["tag"]; len(ids) != 1 {
+		t.Errorf("Purge() left %d ids under the tag, want 1", len(ids))
+	}
+
+	// purging does not count as reads
+	if stats := m.Stats()["short"]; stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want no hits or misses", stats)
+	}
+}
+
+func TestMemoryInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate []string
+		want       []string
+	}{
+		{name: "single tag", invalidate: []string{RepositoryTag("owner", "site")}, want: []string{"other", "user"}},
+		{name: "several tags", invalidate: []string{RepositoryTag("owner", "site"), UserTag("jane")}, want: []string{"other"}},
+		{name: "entry with several tags", invalidate: []string{OwnerTag("owner")}, want: []string{"post", "tree", "user"}},
+		{name: "unknown tag", invalidate: []string{"unknown"}, want: []string{"other", "post", "tree", "user"}},
+		{name: "no tags", want: []string{"other", "post", "tree", "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemory(10, nil)
+			m.Set("files", "post", nil, RepositoryTag("owner", "site"))
+			m.Set("files", "tree", nil, RepositoryTag("owner", "site"))
+			m.Set("files", "other", nil, RepositoryTag("owner", "other"), OwnerTag("owner"))
+			m.Set("files", "user", nil, UserTag("jane"))
+
+			m.Invalidate(tt.invalidate...)
+
+			got := m.keys("files")
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+			for _, tag := range tt.invalidate {
+				if _, ok := m.tags[tag]; ok {
+					t.Errorf("tag %s is still indexed", tag)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryInvalidateReplacedEntry(t *testing.T) {
+	m := NewMemory(10, nil)
+	m.Set("files", "post", []byte("old"), "old-tag")
+	m.Set("files", "post", []byte("new"), "new-tag")
+
+	// the tags of a replaced value no longer apply
+	m.Invalidate("old-tag")
+	if value, ok := m.Get("files", "post"); !ok || string(value) != "new" {
+		t.Fatalf("Get() = %q, %v, want the new value", value, ok)
+	}
+
+	m.Invalidate("new-tag")
+	if _, ok := m.Get("files", "post"); ok {
+		t.Error("Get() returned an invalidated entry")
+	}
+	if len(m.tags) != 0 {
+		t.Errorf("tags = %v, want none", m.tags)
+	}
+}
+
+func TestMemoryStats(t *testing.T) {
+	m := NewMemory(10, nil)
+	m.Set("posts", "a", []byte("a"))
+	m.Get("posts", "a")
+	m.Get("posts", "a")
+	m.Get("posts", "b")
+	m.Get("trees", "a")
+
+	want := map[string]Stats{
+		"posts": {Hits: 2, Misses: 1, Sets: 1},
+		"trees": {Misses: 1},
+	}
+	if got := m.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryConcurrentAccess(t *testing.T) {
+	m := NewMemory(50, TTLs{"short": time.Millisecond})
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				key := fmt.Sprintf("%d", i%100)
+				namespace := []string{"posts", "short"}[i%2]
+				tag := fmt.Sprintf("tag-%d", (worker+i)%5)
+
+				m.Set(namespace, key, []byte(key), tag)
+				m.Get(namespace, key)
+				switch i % 50 {
+				case 0:
+					m.Invalidate(tag)
+				case 25:
+					m.Purge()
+					m.Stats()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.order.Len() > 50 || len(m.entries) != m.order.Len() {
+		t.Errorf("cache holds %d entries in its list and %d in its map, limit 50", m.order.Len(), len(m.entries))
+	}
+	for tag, ids := range m.tags {
+		for id := range ids {
+			if _, ok := m.entries[id]; !ok {
+				t.Errorf("tag %s indexes removed entry %q", tag, id)
+			}
+		}
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"embed"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -25,6 +28,71 @@ type Config struct {
 	// GithubScopes is the list of scopes to request from the GitHub API
 	GithubScopes []string
 
+	// GithubAPIURL is the base URL of the GitHub API
+	GithubAPIURL string
+
+	// GithubTimeout is the timeout of a single request to the GitHub API
+	GithubTimeout time.Duration
+
+	// GitLabURL is the GitLab instance sites can be hosted on
+	GitLabURL string
+
+	// GitLabClientID and GitLabClientSecret identify the GitLab OAuth application, GitLab is disabled without them
+	GitLabClientID     string
+	GitLabClientSecret string
+
+	// GitLabRedirectURL is the URL to redirect to after the GitLab login
+	GitLabRedirectURL string
+
+	// GiteaURL is the Gitea or Forgejo instance sites can be hosted on
+	GiteaURL string
+
+	// GiteaClientID and GiteaClientSecret identify the Gitea OAuth application, Gitea is disabled without them
+	GiteaClientID     string
+	GiteaClientSecret string
+
+	// GiteaRedirectURL is the URL to redirect to after the Gitea login
+	GiteaRedirectURL string
+
+	// LocalSitesRoot is the directory holding git working copies that sites can be stored in, local sites are disabled without it
+	LocalSitesRoot string
+
+	// LocalPushRemote is the name or URL of the remote branches of local sites are pushed to after each commit, nothing is pushed without it
+	LocalPushRemote string
+
+	// LocalAuthorName and LocalAuthorEmail identify the author of commits to local sites
+	LocalAuthorName  string
+	LocalAuthorEmail string
+
+	// PreviewBuildsDir is where preview builds are checked out and their output is kept, builds are disabled without it
+	PreviewBuildsDir string
+
+	// PreviewBuildTimeout limits how long a preview build may take, including the checkout
+	PreviewBuildTimeout time.Duration
+
+	// PreviewBuildConcurrency is the number of preview builds run at the same time
+	PreviewBuildConcurrency int
+
+	// PreviewBuildMaxSizeMB limits the size of the checkout and of each file written by the generator
+	PreviewBuildMaxSizeMB int
+
+	// PreviewBuildMaxMemoryMB limits the virtual memory of the generator, unlimited when zero
+	PreviewBuildMaxMemoryMB int
+
+	// PreviewBuildUID and PreviewBuildGID are the dedicated user and group generators run as, which must
+	// not be able to read the database or the home directory of the server
+	PreviewBuildUID int
+	PreviewBuildGID int
+
+	// CacheBackend is where cached responses are stored, either "memory" or "database"
+	CacheBackend string
+
+	// CacheMaxEntries is the number of entries the cache holds before evicting
+	CacheMaxEntries int
+
+	// CacheTTLs is how long entries of each cache namespace are kept
+	CacheTTLs map[string]time.Duration
+
 	// JWTSecret is the secret used to sign the JWT tokens
 	JWTSecret string
 
@@ -45,27 +113,177 @@ func NewConfig(database *gorm.DB, staticFiles embed.FS) Config {
 		log.Fatalf("Invalid PORT environment variable: %v", err)
 	}
 
+	githubAPIURL := os.Getenv("GITHUB_API_URL")
+	if githubAPIURL == "" {
+		githubAPIURL = "https://api.github.com"
+	}
+
+	githubTimeout := 30 * time.Second
+	if value := os.Getenv("GITHUB_TIMEOUT"); value != "" {
+		githubTimeout, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid GITHUB_TIMEOUT environment variable: %v", err)
+		}
+	}
+
+	gitlabURL := os.Getenv("GITLAB_URL")
+	if gitlabURL == "" {
+		gitlabURL = "https://gitlab.com"
+	}
+
+	localAuthorName := os.Getenv("LOCAL_AUTHOR_NAME")
+	if localAuthorName == "" {
+		localAuthorName = "Static Admin"
+	}
+
+	localAuthorEmail := os.Getenv("LOCAL_AUTHOR_EMAIL")
+	if localAuthorEmail == "" {
+		localAuthorEmail = "static-admin@localhost"
+	}
+
+	cacheBackend := os.Getenv("CACHE_BACKEND")
+	if cacheBackend == "" {
+		cacheBackend = "memory"
+	}
+	if cacheBackend != "memory" && cacheBackend != "database" {
+		log.Fatalf("Invalid CACHE_BACKEND environment variable: %s", cacheBackend)
+	}
+
+	cacheMaxEntries := 10000
+	if value := os.Getenv("CACHE_MAX_ENTRIES"); value != "" {
+		cacheMaxEntries, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid CACHE_MAX_ENTRIES environment variable: %v", err)
+		}
+	}
+
+	previewBuildTimeout := 5 * time.Minute
+	if value := os.Getenv("PREVIEW_BUILD_TIMEOUT"); value != "" {
+		previewBuildTimeout, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid PREVIEW_BUILD_TIMEOUT environment variable: %v", err)
+		}
+	}
+
+	previewBuildConcurrency := 2
+	if value := os.Getenv("PREVIEW_BUILD_CONCURRENCY"); value != "" {
+		previewBuildConcurrency, err = strconv.Atoi(value)
+		if err != nil || previewBuildConcurrency < 1 {
+			log.Fatalf("Invalid PREVIEW_BUILD_CONCURRENCY environment variable: %s", value)
+		}
+	}
+
+	previewBuildMaxSizeMB := 1024
+	if value := os.Getenv("PREVIEW_BUILD_MAX_SIZE_MB"); value != "" {
+		previewBuildMaxSizeMB, err = strconv.Atoi(value)
+		if err != nil || previewBuildMaxSizeMB < 1 {
+			log.Fatalf("Invalid PREVIEW_BUILD_MAX_SIZE_MB environment variable: %s", value)
+		}
+	}
+
+	previewBuildMaxMemoryMB := 0
+	if value := os.Getenv("PREVIEW_BUILD_MAX_MEMORY_MB"); value != "" {
+		previewBuildMaxMemoryMB, err = strconv.Atoi(value)
+		if err != nil || previewBuildMaxMemoryMB < 0 {
+			log.Fatalf("Invalid PREVIEW_BUILD_MAX_MEMORY_MB environment variable: %s", value)
+		}
+	}
+
+	// PREVIEW_BUILD_USER holds the numeric uid:gid generators run as
+	previewBuildUID, previewBuildGID := -1, -1
+	if value := os.Getenv("PREVIEW_BUILD_USER"); value != "" {
+		uid, gid, ok := strings.Cut(value, ":")
+		previewBuildUID, err = strconv.Atoi(uid)
+		if err == nil && ok {
+			previewBuildGID, err = strconv.Atoi(gid)
+		}
+		if err != nil || !ok || previewBuildUID <= 0 || previewBuildGID <= 0 || previewBuildUID == os.Getuid() {
+			log.Fatalf("Invalid PREVIEW_BUILD_USER environment variable: %s", value)
+		}
+	}
+
+	// CACHE_TTLS holds comma separated namespace=duration pairs, e.g. github=1h
+	cacheTTLs := map[string]time.Duration{}
+	for _, pair := range strings.Split(os.Getenv("CACHE_TTLS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		namespace, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			log.Fatalf("Invalid CACHE_TTLS environment variable: %s", pair)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			log.Fatalf("Invalid CACHE_TTLS environment variable: %v", err)
+		}
+		cacheTTLs[strings.TrimSpace(namespace)] = ttl
+	}
+
 	config := Config{
-		Database:           database,
-		GithubRedirectURL:  os.Getenv("GITHUB_REDIRECT_URL"),
-		GithubClientID:     os.Getenv("GITHUB_CLIENT_ID"),
-		GithubClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
-		GithubScopes:       []string{"repo", "read:user"},
-		JWTSecret:          os.Getenv("JWT_SECRET"),
-		StaticFiles:        staticFiles,
-		Port:               port,
+		Database:                database,
+		GithubRedirectURL:       os.Getenv("GITHUB_REDIRECT_URL"),
+		GithubClientID:          os.Getenv("GITHUB_CLIENT_ID"),
+		GithubClientSecret:      os.Getenv("GITHUB_CLIENT_SECRET"),
+		GithubScopes:            []string{"repo", "read:user", "read:org"},
+		GithubAPIURL:            githubAPIURL,
+		GithubTimeout:           githubTimeout,
+		GitLabURL:               gitlabURL,
+		GitLabClientID:          os.Getenv("GITLAB_CLIENT_ID"),
+		GitLabClientSecret:      os.Getenv("GITLAB_CLIENT_SECRET"),
+		GitLabRedirectURL:       os.Getenv("GITLAB_REDIRECT_URL"),
+		GiteaURL:                os.Getenv("GITEA_URL"),
+		GiteaClientID:           os.Getenv("GITEA_CLIENT_ID"),
+		GiteaClientSecret:       os.Getenv("GITEA_CLIENT_SECRET"),
+		GiteaRedirectURL:        os.Getenv("GITEA_REDIRECT_URL"),
+		LocalSitesRoot:          os.Getenv("LOCAL_SITES_ROOT"),
+		LocalPushRemote:         os.Getenv("LOCAL_PUSH_REMOTE"),
+		LocalAuthorName:         localAuthorName,
+		LocalAuthorEmail:        localAuthorEmail,
+		PreviewBuildsDir:        os.Getenv("PREVIEW_BUILDS_DIR"),
+		PreviewBuildTimeout:     previewBuildTimeout,
+		PreviewBuildConcurrency: previewBuildConcurrency,
+		PreviewBuildMaxSizeMB:   previewBuildMaxSizeMB,
+		PreviewBuildMaxMemoryMB: previewBuildMaxMemoryMB,
+		PreviewBuildUID:         previewBuildUID,
+		PreviewBuildGID:         previewBuildGID,
+		CacheBackend:            cacheBackend,
+		CacheMaxEntries:         cacheMaxEntries,
+		CacheTTLs:               cacheTTLs,
+		JWTSecret:               os.Getenv("JWT_SECRET"),
+		StaticFiles:             staticFiles,
+		Port:                    port,
+	}
+
+	// GitHub is optional when sites are stored locally, allowing the admin to run fully offline
+	if config.LocalSitesRoot == "" || config.GithubClientID != "" {
+		if config.GithubRedirectURL == "" {
+			log.Fatal("GITHUB_REDIRECT_URL environment variable is required")
+		}
+
+		if config.GithubClientID == "" {
+			log.Fatal("GITHUB_CLIENT_ID environment variable is required")
+		}
+
+		if config.GithubClientSecret == "" {
+			log.Fatal("GITHUB_CLIENT_SECRET environment variable is required")
+		}
+	}
+
+	if config.LocalSitesRoot != "" && !filepath.IsAbs(config.LocalSitesRoot) {
+		log.Fatal("LOCAL_SITES_ROOT environment variable must be an absolute path")
 	}
 
-	if config.GithubRedirectURL == "" {
-		log.Fatal("GITHUB_REDIRECT_URL environment variable is required")
+	if config.PreviewBuildsDir != "" && !filepath.IsAbs(config.PreviewBuildsDir) {
+		log.Fatal("PREVIEW_BUILDS_DIR environment variable must be an absolute path")
 	}
 
-	if config.GithubClientID == "" {
-		log.Fatal("GITHUB_CLIENT_ID environment variable is required")
+	// generators run the site's own code, so they never run as the user holding the database
+	if config.PreviewBuildsDir != "" && config.PreviewBuildUID < 0 {
+		log.Fatal("PREVIEW_BUILD_USER environment variable is required when PREVIEW_BUILDS_DIR is set")
 	}
 
-	if config.GithubClientSecret == "" {
-		log.Fatal("GITHUB_CLIENT_SECRET environment variable is required")
+	if config.GiteaClientID != "" && config.GiteaURL == "" {
+		log.Fatal("GITEA_URL environment variable is required when GITEA_CLIENT_ID is set")
 	}
 
 	if config.JWTSecret == "" {
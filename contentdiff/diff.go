@@ -0,0 +1,355 @@
+package contentdiff
+
+import (
+	"encoding/json"
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"static-admin/blocks"
+	"static-admin/markdown"
+)
+
+const (
+	// Added is a field or block that only exists in the new revision
+	Added = "added"
+
+	// Removed is a field or block that only exists in the old revision
+	Removed = "removed"
+
+	// Changed is a field whose value differs between revisions
+	Changed = "changed"
+
+	// Edited is a block replaced by a block of the same type
+	Edited = "edited"
+)
+
+// excerptLength is the number of characters kept in block excerpts
+const excerptLength = 80
+
+// tagRegex matches HTML tags in the text of blocks
+var tagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// FieldChange describes a frontmatter field that differs between two revisions
+type FieldChange struct {
+	Name   string                     `json:"name"`
+	Change string                     `json:"change"`
+	Old    *markdown.FrontmatterField `json:"old,omitempty"`
+	New    *markdown.FrontmatterField `json:"new,omitempty"`
+}
+
+// BlockChange describes a content block that differs between two revisions.
+// Indexes are -1 for blocks missing from one of the revisions.
+type BlockChange struct {
+	Change     string        `json:"change"`
+	Type       string        `json:"type"`
+	OldIndex   int           `json:"old_index"`
+	NewIndex   int           `json:"new_index"`
+	Old        *blocks.Block `json:"old,omitempty"`
+	New        *blocks.Block `json:"new,omitempty"`
+	OldExcerpt string        `json:"old_excerpt,omitempty"`
+	NewExcerpt string        `json:"new_excerpt,omitempty"`
+}
+
+// Diff is the structured difference between two revisions of a post
+type Diff struct {
+	Frontmatter []FieldChange `json:"frontmatter"`
+	Blocks      []BlockChange `json:"blocks"`
+}
+
+// Content is a revision of a post parsed into frontmatter and blocks
+type Content struct {
+	Frontmatter []markdown.FrontmatterField `json:"frontmatter"`
+	Blocks      []blocks.Block              `json:"blocks"`
+}
+
+// Parse splits the markdown of a post into frontmatter and blocks, an empty string is an empty post
+func Parse(content string) (Content, error) {
+	if content == "" {
+		return Content{Frontmatter: []markdown.FrontmatterField{}, Blocks: []blocks.Block{}}, nil
+	}
+
+	fields, body, err := markdown.ExtractFrontMatter([]byte(content))
+	if err != nil {
+		return Content{}, err
+	}
+
+	parsed, err := markdown.ParseMarkdownToBlocks(body)
+	if err != nil {
+		return Content{}, err
+	}
+	return Content{Frontmatter: fields, Blocks: parsed}, nil
+}
+
+// CompareContent parses two revisions of a post and compares them
+func CompareContent(oldContent, newContent string) (Diff, error) {
+	oldParsed, err := Parse(oldContent)
+	if err != nil {
+		return Diff{}, err
+	}
+	newParsed, err := Parse(newContent)
+	if err != nil {
+		return Diff{}, err
+	}
+	return Compare(oldParsed, newParsed), nil
+}
+
+// Compare computes the field-level and block-level differences between two revisions
+func Compare(oldContent, newContent Content) Diff {
+	return Diff{
+		Frontmatter: compareFields(oldContent.Frontmatter, newContent.Frontmatter),
+		Blocks:      compareBlocks(Normalize(oldContent.Blocks), Normalize(newContent.Blocks)),
+	}
+}
+
+// Normalize converts the data of blocks to the types decoded from JSON, so blocks parsed from
+// markdown and blocks sent by the editor can be compared and inspected the same way
+func Normalize(list []blocks.Block) []blocks.Block {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return list
+	}
+
+	var normalized []blocks.Block
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return list
+	}
+	return normalized
+}
+
+// Empty returns true if the revisions have the same frontmatter and blocks
+func (d Diff) Empty() bool {
+	return len(d.Frontmatter) == 0 && len(d.Blocks) == 0
+}
+
+// compareFields matches frontmatter fields by name, keeping the order of the new revision
+func compareFields(oldFields, newFields []markdown.FrontmatterField) []FieldChange {
+	oldByName := make(map[string]*markdown.FrontmatterField, len(oldFields))
+	for i := range oldFields {
+		oldByName[oldFields[i].Name] = &oldFields[i]
+	}
+
+	changes := []FieldChange{}
+	seen := make(map[string]bool, len(newFields))
+	for i := range newFields {
+		field := &newFields[i]
+		seen[field.Name] = true
+
+		old, ok := oldByName[field.Name]
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Name: field.Name, Change: Added, New: field})
+		case !sameValue(*old, *field):
+			changes = append(changes, FieldChange{Name: field.Name, Change: Changed, Old: old, New: field})
+		}
+	}
+
+	for i := range oldFields {
+		if !seen[oldFields[i].Name] {
+			changes = append(changes, FieldChange{Name: oldFields[i].Name, Change: Removed, Old: &oldFields[i]})
+		}
+	}
+	return changes
+}
+
+// sameValue returns true if two fields hold the same typed value
+func sameValue(a, b markdown.FrontmatterField) bool {
+	if a.Type != b.Type {
+		return false
+	}
+
+	switch a.Type {
+	case "bool":
+		return a.BoolValue == b.BoolValue
+	case "number":
+		return a.NumberValue == b.NumberValue
+	case "dateTime":
+		return a.DateTimeValue.Equal(b.DateTimeValue)
+	case "stringSlice":
+		return reflect.DeepEqual(nonNil(a.StringSliceValue), nonNil(b.StringSliceValue))
+	default:
+		return a.StringValue == b.StringValue
+	}
+}
+
+// nonNil treats nil and empty slices the same
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// compareBlocks aligns the blocks of two revisions on their longest common subsequence.
+// Runs of removed and added blocks are paired into edits when their types match.
+func compareBlocks(oldBlocks, newBlocks []blocks.Block) []BlockChange {
+	oldKeys := blockKeys(oldBlocks)
+	newKeys := blockKeys(newBlocks)
+
+	// lengths[i][j] is the length of the common subsequence of oldKeys[i:] and newKeys[j:]
+	lengths := make([][]int, len(oldKeys)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newKeys)+1)
+	}
+	for i := len(oldKeys) - 1; i >= 0; i-- {
+		for j := len(newKeys) - 1; j >= 0; j-- {
+			if oldKeys[i] == newKeys[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	changes := []BlockChange{}
+	var removed, added []int
+	flush := func() {
+		changes = append(changes, pairBlocks(oldBlocks, newBlocks, removed, added)...)
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(oldKeys) || j < len(newKeys) {
+		switch {
+		case i < len(oldKeys) && j < len(newKeys) && oldKeys[i] == newKeys[j]:
+			flush()
+			i++
+			j++
+		case j < len(newKeys) && (i == len(oldKeys) || lengths[i][j+1] >= lengths[i+1][j]):
+			added = append(added, j)
+			j++
+		default:
+			removed = append(removed, i)
+			i++
+		}
+	}
+	flush()
+
+	return changes
+}
+
+// pairBlocks turns a run of removed and added blocks into edits, additions and removals
+func pairBlocks(oldBlocks, newBlocks []blocks.Block, removed, added []int) []BlockChange {
+	var changes []BlockChange
+	used := make([]bool, len(removed))
+	for _, j := range added {
+		paired := false
+		for k, i := range removed {
+			if used[k] || oldBlocks[i].Type != newBlocks[j].Type {
+				continue
+			}
+			used[k] = true
+			paired = true
+			changes = append(changes, BlockChange{
+				Change:     Edited,
+				Type:       newBlocks[j].Type,
+				OldIndex:   i,
+				NewIndex:   j,
+				Old:        &oldBlocks[i],
+				New:        &newBlocks[j],
+				OldExcerpt: Excerpt(oldBlocks[i]),
+				NewExcerpt: Excerpt(newBlocks[j]),
+			})
+			break
+		}
+
+		if !paired {
+			changes = append(changes, BlockChange{
+				Change:     Added,
+				Type:       newBlocks[j].Type,
+				OldIndex:   -1,
+				NewIndex:   j,
+				New:        &newBlocks[j],
+				NewExcerpt: Excerpt(newBlocks[j]),
+			})
+		}
+	}
+
+	for k, i := range removed {
+		if used[k] {
+			continue
+		}
+		changes = append(changes, BlockChange{
+			Change:     Removed,
+			Type:       oldBlocks[i].Type,
+			OldIndex:   i,
+			NewIndex:   -1,
+			Old:        &oldBlocks[i],
+			OldExcerpt: Excerpt(oldBlocks[i]),
+		})
+	}
+	return changes
+}
+
+// blockKeys serializes blocks so they can be compared, map keys are sorted by the encoder
+func blockKeys(list []blocks.Block) []string {
+	keys := make([]string, len(list))
+	for i, block := range list {
+		data, _ := json.Marshal(block)
+		keys[i] = string(data)
+	}
+	return keys
+}
+
+// Excerpt returns a short plain text summary of a block
+func Excerpt(block blocks.Block) string {
+	var text string
+	switch block.Type {
+	case "image":
+		text, _ = block.Data["caption"].(string)
+		if text == "" {
+			text = ImageURL(block)
+		}
+	case "code":
+		text, _ = block.Data["code"].(string)
+	case "list":
+		text = strings.Join(listTexts(block.Data["items"]), ", ")
+	case "table":
+		if rows, ok := block.Data["content"].([]interface{}); ok && len(rows) > 0 {
+			if cells, ok := rows[0].([]interface{}); ok {
+				for _, cell := range cells {
+					if value, ok := cell.(string); ok {
+						text += value + " "
+					}
+				}
+			}
+		}
+	default:
+		text, _ = block.Data["text"].(string)
+	}
+
+	text = strings.Join(strings.Fields(html.UnescapeString(tagRegex.ReplaceAllString(text, ""))), " ")
+	if utf8.RuneCountInString(text) > excerptLength {
+		text = string([]rune(text)[:excerptLength]) + "…"
+	}
+	return text
+}
+
+// ImageURL returns the URL of an image block, or an empty string for other blocks
+func ImageURL(block blocks.Block) string {
+	if block.Type != "image" {
+		return ""
+	}
+	file, _ := block.Data["file"].(map[string]interface{})
+	url, _ := file["url"].(string)
+	return url
+}
+
+// listTexts collects the text of list items, which are either strings or objects with content
+func listTexts(items interface{}) []string {
+	list, _ := items.([]interface{})
+	texts := make([]string, 0, len(list))
+	for _, item := range list {
+		switch value := item.(type) {
+		case string:
+			texts = append(texts, value)
+		case map[string]interface{}:
+			if content, ok := value["content"].(string); ok {
+				texts = append(texts, content)
+			}
+		}
+	}
+	return texts
+}
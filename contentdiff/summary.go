@@ -0,0 +1,105 @@
+package contentdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"static-admin/markdown"
+)
+
+// ImagesAdded returns the URLs of images in the new revision that the old revision does not contain
+func (d Diff) ImagesAdded() []string {
+	removed := map[string]bool{}
+	for _, change := range d.Blocks {
+		if change.Old != nil {
+			removed[ImageURL(*change.Old)] = true
+		}
+	}
+
+	images := []string{}
+	for _, change := range d.Blocks {
+		if change.New == nil {
+			continue
+		}
+		if url := ImageURL(*change.New); url != "" && !removed[url] {
+			images = append(images, url)
+		}
+	}
+	return images
+}
+
+// Markdown describes the diff for reviewers, listing changed fields, changed blocks with excerpts and added images
+func (d Diff) Markdown() string {
+	if d.Empty() {
+		return "No content changes."
+	}
+
+	var builder strings.Builder
+	if len(d.Frontmatter) > 0 {
+		builder.WriteString("### Fields changed\n\n")
+		for _, change := range d.Frontmatter {
+			switch change.Change {
+			case Added:
+				fmt.Fprintf(&builder, "- **%s** added: %s\n", change.Name, formatValue(*change.New))
+			case Removed:
+				fmt.Fprintf(&builder, "- **%s** removed (was %s)\n", change.Name, formatValue(*change.Old))
+			default:
+				fmt.Fprintf(&builder, "- **%s**: %s → %s\n", change.Name, formatValue(*change.Old), formatValue(*change.New))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(d.Blocks) > 0 {
+		builder.WriteString("### Content changed\n\n")
+		for _, change := range d.Blocks {
+			switch change.Change {
+			case Added:
+				fmt.Fprintf(&builder, "- Added %s %d: %s\n", change.Type, change.NewIndex+1, quote(change.NewExcerpt))
+			case Removed:
+				fmt.Fprintf(&builder, "- Removed %s %d: %s\n", change.Type, change.OldIndex+1, quote(change.OldExcerpt))
+			default:
+				fmt.Fprintf(&builder, "- Edited %s %d: %s → %s\n", change.Type, change.NewIndex+1, quote(change.OldExcerpt), quote(change.NewExcerpt))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if images := d.ImagesAdded(); len(images) > 0 {
+		builder.WriteString("### Images added\n\n")
+		for _, url := range images {
+			fmt.Fprintf(&builder, "- `%s`\n", url)
+		}
+		builder.WriteString("\n")
+	}
+
+	return strings.TrimSpace(builder.String())
+}
+
+// formatValue renders the value of a frontmatter field as inline code
+func formatValue(field markdown.FrontmatterField) string {
+	var value string
+	switch field.Type {
+	case "bool":
+		value = strconv.FormatBool(field.BoolValue)
+	case "number":
+		value = strconv.FormatFloat(field.NumberValue, 'f', -1, 64)
+	case "dateTime":
+		value = field.DateTimeValue.Format(time.RFC3339)
+	case "stringSlice":
+		value = strings.Join(field.StringSliceValue, ", ")
+	default:
+		value = field.StringValue
+	}
+	return "`" + strings.ReplaceAll(value, "`", "'") + "`"
+}
+
+// quote renders a block excerpt, which may be empty for blocks without text
+func quote(excerpt string) string {
+	if excerpt == "" {
+		return "(no text)"
+	}
+	return "“" + excerpt + "”"
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BranchStatus caches the review, check and deployment state of the head of a branch of a site
+type BranchStatus struct {
+	gorm.Model
+	SiteID    uint   `gorm:"not null;uniqueIndex:idx_branch_status,priority:1"`
+	Branch    string `gorm:"not null;uniqueIndex:idx_branch_status,priority:2"`
+	CommitSHA string `gorm:"not null;default:''"`
+
+	// ChecksState combines commit statuses and check runs: pending, success, failure or empty without checks
+	ChecksState string `gorm:"not null;default:''"`
+
+	// the most recent pull request opened from the branch, PullRequestState is open, closed or merged
+	PullRequestNumber int64  `gorm:"not null;default:0"`
+	PullRequestURL    string `gorm:"not null;default:''"`
+	PullRequestState  string `gorm:"not null;default:''"`
+
+	// DeploymentState is the state of the latest deployment of the branch, empty if it was never deployed
+	DeploymentState       string `gorm:"not null;default:''"`
+	DeploymentEnvironment string `gorm:"not null;default:''"`
+
+	// DeployedSHA, DeployedAt and DeploymentURL describe the latest successful deployment
+	DeployedSHA   string `gorm:"not null;default:''"`
+	DeployedAt    *time.Time
+	DeploymentURL string `gorm:"not null;default:''"`
+
+	RefreshedAt time.Time
+
+	// Stale is set by webhook deliveries for statuses, checks and deployments of the branch
+	Stale bool `gorm:"not null;default:false"`
+}
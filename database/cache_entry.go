@@ -0,0 +1,19 @@
+package database
+
+import (
+	"time"
+)
+
+// CacheEntry is a single value of the on-disk cache
+type CacheEntry struct {
+	ID        uint   `gorm:"primaryKey"`
+	Namespace string `gorm:"not null;uniqueIndex:idx_cache_key,priority:1"`
+	Key       string `gorm:"not null;uniqueIndex:idx_cache_key,priority:2"`
+	Value     []byte `gorm:"not null"`
+
+	// Tags group entries that are invalidated together, such as all entries of a user or repository
+	Tags StringSliceValue `gorm:"not null;default:'[]';serializer:json"`
+
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UpdatedAt time.Time `gorm:"index"`
+}
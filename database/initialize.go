@@ -2,25 +2,46 @@ package database
 
 import (
 	"fmt"
+	"os"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// databaseFile is the SQLite database file, relative to the working directory
+const databaseFile = "gorm_example.db"
+
 // Initialize creates a connection to the database and creates the users table if it doesn't exist
 func Initialize() (*gorm.DB, error) {
 	// Connect to SQLite database
-	db, err := gorm.Open(sqlite.Open("gorm_example.db"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(databaseFile), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// the database holds provider tokens, only the server's user may read it
+	if err := os.Chmod(databaseFile, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to restrict database permissions: %w", err)
+	}
+
 	models := []interface{}{
 		&User{},
 		&GitHubAuth{},
 		&Site{},
 		&Template{},
 		&TemplateField{},
+		&ScheduledPublication{},
+		&RepositoryIndex{},
+		&RepositoryFile{},
+		&CacheEntry{},
+		&ProviderAuth{},
+		&PreviewBuild{},
+		&BranchStatus{},
+		&SiteMember{},
+		&SiteInvitation{},
+		&PostAuthor{},
+		&Workspace{},
+		&WorkspaceMember{},
 	}
 
 	// AutoMigrate the schema
@@ -28,5 +49,9 @@ func Initialize() (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := BackfillSiteOwners(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate site owners: %w", err)
+	}
+
 	return db, nil
 }
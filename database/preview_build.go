@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// PreviewBuildStatusQueued is a build waiting for a free build slot
+	PreviewBuildStatusQueued = "queued"
+
+	// PreviewBuildStatusRunning is a build currently checking out or running the generator
+	PreviewBuildStatusRunning = "running"
+
+	// PreviewBuildStatusSucceeded is a build whose output is served under its preview URL
+	PreviewBuildStatusSucceeded = "succeeded"
+
+	// PreviewBuildStatusFailed is a build that could not be completed
+	PreviewBuildStatusFailed = "failed"
+)
+
+// PreviewBuild represents a build of a branch of a site by its generator, one per commit
+type PreviewBuild struct {
+	gorm.Model
+	SiteID    uint   `gorm:"not null;uniqueIndex:idx_preview_build_commit,priority:1"`
+	UserID    uint   `gorm:"not null"`
+	Branch    string `gorm:"not null;uniqueIndex:idx_preview_build_commit,priority:2"`
+	CommitSHA string `gorm:"not null;uniqueIndex:idx_preview_build_commit,priority:3"`
+
+	// PreviewKey identifies the preview URL of the branch, which serves its latest successful build
+	PreviewKey string `gorm:"not null;index"`
+	Status     string `gorm:"not null;default:'queued';check:status IN ('queued', 'running', 'succeeded', 'failed')"`
+	Log        string `gorm:"not null;default:''"`
+	Error      string `gorm:"not null;default:''"`
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ProviderAuth represents the authentication data of a user on a git hosting provider other than GitHub
+type ProviderAuth struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_user_provider,priority:1"`
+	Provider    string `gorm:"not null;uniqueIndex:idx_user_provider,priority:2"`
+	Login       string `gorm:"not null"`
+	Name        string `gorm:"not null"`
+	URL         string `gorm:"not null"`
+	AccessToken string `gorm:"not null"`
+}
+
+// GetProviderToken returns the access token a user authorized for a provider, local sites need no token
+func GetProviderToken(db *gorm.DB, userID uint, provider string) (string, error) {
+	if provider == "local" {
+		return "", nil
+	}
+
+	if provider == "" || provider == "github" {
+		var auth GitHubAuth
+		if err := db.Where("user_id = ?", userID).First(&auth).Error; err != nil || auth.AccessToken == "" {
+			return "", errors.New("GitHub authentication required")
+		}
+		return auth.AccessToken, nil
+	}
+
+	var auth ProviderAuth
+	if err := db.Where("user_id = ? AND provider = ?", userID, provider).First(&auth).Error; err != nil || auth.AccessToken == "" {
+		return "", errors.New("Authentication with " + provider + " required")
+	}
+	return auth.AccessToken, nil
+}
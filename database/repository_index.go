@@ -0,0 +1,67 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RepositoryIndex records the commit a site's repository index was built from
+type RepositoryIndex struct {
+	gorm.Model
+	SiteID    uint   `gorm:"not null;uniqueIndex"`
+	CommitSHA string `gorm:"not null"`
+	TreeSHA   string `gorm:"not null"`
+
+	// Generator is the generator the files were classified with, a change forces a rebuild
+	Generator string `gorm:"not null"`
+
+	// Version is the version of the indexed metadata, indexes built by older versions are rebuilt
+	Version     int `gorm:"not null;default:0"`
+	RefreshedAt time.Time
+
+	// Stale is set by webhook deliveries when the default branch has moved
+	Stale bool `gorm:"not null;default:false"`
+}
+
+// RepositoryFile represents a single file in a site's repository index
+type RepositoryFile struct {
+	ID     uint   `gorm:"primaryKey"`
+	SiteID uint   `gorm:"not null;uniqueIndex:idx_site_file,priority:1;index:idx_site_kind,priority:1"`
+	Path   string `gorm:"not null;uniqueIndex:idx_site_file,priority:2"`
+	SHA    string `gorm:"not null"`
+	Size   int64  `gorm:"not null;default:0"`
+	Kind   string `gorm:"not null;default:'other';index:idx_site_kind,priority:2;check:kind IN ('post', 'media', 'other')"`
+
+	// post metadata, only populated for files of the post kind
+	Title            string           `gorm:"not null;default:''"`
+	Date             time.Time        `gorm:"not null;default:'0000-00-00 00:00:00'"`
+	Tags             StringSliceValue `gorm:"not null;default:'[]';serializer:json"`
+	Categories       StringSliceValue `gorm:"not null;default:'[]';serializer:json"`
+	Draft            bool             `gorm:"not null;default:false"`
+	Slug             string           `gorm:"not null;default:''"`
+	Permalink        string           `gorm:"not null;default:''"`
+	LastCommitAuthor string           `gorm:"not null;default:''"`
+	LastCommitDate   time.Time        `gorm:"not null;default:'0000-00-00 00:00:00'"`
+}
+
+const (
+	// RepositoryFileKindPost is a post in one of the generator's post directories
+	RepositoryFileKindPost = "post"
+
+	// RepositoryFileKindMedia is an image, video, audio or document file
+	RepositoryFileKindMedia = "media"
+
+	// RepositoryFileKindOther is any other file in the repository
+	RepositoryFileKindOther = "other"
+)
+
+// DeleteRepositoryIndex removes the repository index of a site
+func DeleteRepositoryIndex(db *gorm.DB, siteID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("site_id = ?", siteID).Delete(&RepositoryFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("site_id = ?", siteID).Delete(&RepositoryIndex{}).Error
+	})
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ScheduleActionPublishDraft publishes a draft post
+	ScheduleActionPublishDraft = "publish_draft"
+
+	// ScheduleActionMergePullRequest merges the pull request containing a post
+	ScheduleActionMergePullRequest = "merge_pull_request"
+)
+
+const (
+	// ScheduleStatusPending is a job waiting for its publish time
+	ScheduleStatusPending = "pending"
+
+	// ScheduleStatusRunning is a job currently being executed by the scheduler
+	ScheduleStatusRunning = "running"
+
+	// ScheduleStatusCompleted is a job that ran successfully
+	ScheduleStatusCompleted = "completed"
+
+	// ScheduleStatusFailed is a job that exhausted its retries
+	ScheduleStatusFailed = "failed"
+)
+
+// ScheduledPublication represents a post scheduled to be published at a future time
+type ScheduledPublication struct {
+	gorm.Model
+	SiteID            uint      `gorm:"not null;index"`
+	UserID            uint      `gorm:"not null;index"`
+	PostPath          string    `gorm:"not null"`
+	Action            string    `gorm:"not null;check:action IN ('publish_draft', 'merge_pull_request')"`
+	PullRequestNumber int64     `gorm:"not null;default:0"`
+	MergeMethod       string    `gorm:"not null;default:'merge'"`
+	PublishAt         time.Time `gorm:"not null"`
+	Status            string    `gorm:"not null;default:'pending';index:idx_schedule_due,priority:1;check:status IN ('pending', 'running', 'completed', 'failed')"`
+	NextAttemptAt     time.Time `gorm:"not null;index:idx_schedule_due,priority:2"`
+	Attempts          int       `gorm:"not null;default:0"`
+	LastError         string    `gorm:"not null;default:''"`
+}
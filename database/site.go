@@ -1,25 +1,129 @@
 package database
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"path"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-// Site represents a configured GitHub repository
+// Site represents a configured repository on a git hosting provider
 type Site struct {
 	gorm.Model
+
+	// UserID is the user who registered the site, access is granted through SiteMember
 	UserID        uint   `gorm:"not null;index:idx_user_repo,priority:1"`
 	RepositoryURL string `gorm:"not null;index:idx_user_repo,priority:2"`
 	Description   string
 	DefaultBranch string `gorm:"not null"`
 	Private       bool   `gorm:"not null"`
+
+	// Provider is the git hosting provider the repository lives on
+	Provider string `gorm:"not null;default:'github';check:provider IN ('github', 'gitlab', 'gitea', 'local')"`
+
+	// PublishingMode controls how saved posts reach the repository
+	PublishingMode string `gorm:"not null;default:'pull_request';check:publishing_mode IN ('pull_request', 'direct', 'staging')"`
+
+	// StagingBranch is the branch commits are pushed to when using the staging publishing mode
+	StagingBranch string `gorm:"not null;default:''"`
+
+	// Generator is the static site generator used to build the site
+	Generator string `gorm:"not null;default:'jekyll';check:generator IN ('jekyll', 'hugo', 'eleventy', 'other')"`
+
+	// RootPath is the directory of the site within the repository, empty when the site is the whole repository
+	RootPath string `gorm:"not null;default:''"`
+
+	// WorkingBranch is the branch the site is read from and published to instead of the repository's default branch
+	WorkingBranch string `gorm:"not null;default:''"`
+
+	// WebhookSecret is used to verify the signature of GitHub webhook deliveries
+	WebhookSecret string `gorm:"not null;default:''"`
+
+	// WebhookReceivedAt is the time of the last verified webhook delivery
+	WebhookReceivedAt *time.Time
+
+	// ConfigError describes why the configuration in the repository was rejected, empty when it was loaded
+	ConfigError string `gorm:"not null;default:''"`
+
+	// WorkspaceID is the workspace the site belongs to, nil for sites shared only through their members
+	WorkspaceID *uint `gorm:"index"`
+}
+
+const (
+	// PublishingModePullRequest saves posts to a review branch and opens a pull request
+	PublishingModePullRequest = "pull_request"
+
+	// PublishingModeDirect commits posts directly to the default branch
+	PublishingModeDirect = "direct"
+
+	// PublishingModeStaging commits posts to a fixed staging branch
+	PublishingModeStaging = "staging"
+)
+
+// ValidPublishingMode returns true if the given mode is a known publishing mode
+func ValidPublishingMode(mode string) bool {
+	return mode == PublishingModePullRequest || mode == PublishingModeDirect || mode == PublishingModeStaging
+}
+
+// NormalizeRootPath cleans a site root path, rejecting paths that leave the repository
+func NormalizeRootPath(root string) (string, bool) {
+	root = strings.Trim(strings.TrimSpace(root), "/")
+	if root == "" {
+		return "", true
+	}
+
+	root = path.Clean(root)
+	if root == "." || root == ".." || strings.HasPrefix(root, "../") {
+		return "", false
+	}
+	return root, true
+}
+
+// Branch returns the branch the site is read from and published to
+func (s Site) Branch() string {
+	if s.WorkingBranch != "" {
+		return s.WorkingBranch
+	}
+	return s.DefaultBranch
+}
+
+// RepositoryPath returns the path within the repository of a path relative to the site root
+func (s Site) RepositoryPath(sitePath string) string {
+	if s.RootPath == "" {
+		return sitePath
+	}
+	return path.Join(s.RootPath, sitePath)
+}
+
+// GenerateWebhookSecret creates a random secret for signing webhook deliveries
+func GenerateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// OwnerAndRepo extracts the owner and repository name from the site's repository URL.
+// GitLab repositories in subgroups have an owner made of several slash-separated groups.
+func (s Site) OwnerAndRepo() (string, string, bool) {
+	// Format: https://github.com/owner/repo or https://gitlab.com/group/subgroup/repo
+	urlParts := strings.Split(strings.TrimSuffix(s.RepositoryURL, "/"), "/")
+	if len(urlParts) < 5 {
+		return "", "", false
+	}
+	owner := strings.Join(urlParts[3:len(urlParts)-1], "/")
+	return owner, urlParts[len(urlParts)-1], true
 }
 
-// GetSite retrieves the site from the database
+// GetSite retrieves a site the user is a member of, directly or through its workspace, from the database
 func GetSite(db *gorm.DB, siteID string, user User) (Site, error) {
 	var site Site
-	if err := db.Where("id = ? AND user_id = ?", siteID, user.ID).First(&site).Error; err != nil {
+	if err := AccessibleSites(db, user.ID).Where("id = ?", siteID).First(&site).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return site, errors.New("site not found")
 		}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RoleOwner can do everything on a site, including deleting it and managing other owners
+	RoleOwner = "owner"
+
+	// RoleAdmin manages the settings and members of a site
+	RoleAdmin = "admin"
+
+	// RoleEditor writes and publishes every post of a site
+	RoleEditor = "editor"
+
+	// RoleAuthor creates posts and edits the posts they created
+	RoleAuthor = "author"
+
+	// RoleViewer reads the posts of a site
+	RoleViewer = "viewer"
+)
+
+const (
+	// PermissionRead allows reading a site and its posts
+	PermissionRead = "read"
+
+	// PermissionWrite allows creating and editing posts, limited to posts the member created for authors
+	PermissionWrite = "write"
+
+	// PermissionPublish allows publishing posts, scheduling them and acting on their review requests
+	PermissionPublish = "publish"
+
+	// PermissionManage allows changing the settings of a site and managing its members
+	PermissionManage = "manage"
+
+	// PermissionDelete allows deleting a site
+	PermissionDelete = "delete"
+)
+
+// InvitationTTL is how long an invitation can be accepted for
+const InvitationTTL = 14 * 24 * time.Hour
+
+// rolePermissions are the permissions granted by each role
+var rolePermissions = map[string][]string{
+	RoleOwner:  {PermissionRead, PermissionWrite, PermissionPublish, PermissionManage, PermissionDelete},
+	RoleAdmin:  {PermissionRead, PermissionWrite, PermissionPublish, PermissionManage},
+	RoleEditor: {PermissionRead, PermissionWrite, PermissionPublish},
+	RoleAuthor: {PermissionRead, PermissionWrite},
+	RoleViewer: {PermissionRead},
+}
+
+// SiteMember grants a user a role on a site
+type SiteMember struct {
+	gorm.Model
+	SiteID uint   `gorm:"not null;uniqueIndex:idx_site_member,priority:1"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_site_member,priority:2;index"`
+	Role   string `gorm:"not null;check:role IN ('owner', 'admin', 'editor', 'author', 'viewer')"`
+}
+
+// SiteInvitation invites a person to a site by email address or GitHub login, whichever is set
+type SiteInvitation struct {
+	gorm.Model
+	SiteID      uint   `gorm:"not null;index"`
+	Email       string `gorm:"not null;default:'';index"`
+	GitHubLogin string `gorm:"not null;default:'';index"`
+	Role        string `gorm:"not null;check:role IN ('owner', 'admin', 'editor', 'author', 'viewer')"`
+	InvitedByID uint   `gorm:"not null"`
+	ExpiresAt   time.Time
+}
+
+// PostAuthor records the user who created a post through the admin
+type PostAuthor struct {
+	ID        uint   `gorm:"primaryKey"`
+	SiteID    uint   `gorm:"not null;uniqueIndex:idx_post_author,priority:1"`
+	Path      string `gorm:"not null;uniqueIndex:idx_post_author,priority:2"`
+	UserID    uint   `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// ValidRole returns true if the given role is a known site role
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can returns true if the role of the member grants the given permission
+func (m SiteMember) Can(permission string) bool {
+	return slices.Contains(rolePermissions[m.Role], permission)
+}
+
+// OwnPostsOnly returns true if the member may only edit the posts they created
+func (m SiteMember) OwnPostsOnly() bool {
+	return m.Role == RoleAuthor
+}
+
+// CanAssign returns true if the member may give the role to someone, only owners can make other owners
+func (m SiteMember) CanAssign(role string) bool {
+	return m.Can(PermissionManage) && (role != RoleOwner || m.Role == RoleOwner)
+}
+
+// GetSiteMember retrieves the membership of a user in a site. Members of the workspace of a site
+// get the role the workspace grants them when it is higher than their own role on the site, without
+// a membership of their own the returned member has no ID.
+func GetSiteMember(db *gorm.DB, siteID string, userID uint) (SiteMember, error) {
+	var site Site
+	if err := db.Select("id", "workspace_id").Where("id = ?", siteID).First(&site).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return SiteMember{}, errors.New("site not found")
+		}
+		return SiteMember{}, errors.New("failed to fetch site membership")
+	}
+
+	member := SiteMember{SiteID: site.ID, UserID: userID}
+	err := db.Where("site_id = ? AND user_id = ?", site.ID, userID).First(&member).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return member, errors.New("failed to fetch site membership")
+	}
+
+	if site.WorkspaceID != nil {
+		var workspace Workspace
+		if err := db.First(&workspace, *site.WorkspaceID).Error; err != nil {
+			return member, errors.New("failed to fetch site membership")
+		}
+		workspaceMember, err := GetWorkspaceMember(db, fmt.Sprint(workspace.ID), userID)
+		if err == nil {
+			member.Role = higherSiteRole(member.Role, workspaceMember.SiteRole(workspace))
+		}
+	}
+
+	if member.Role == "" {
+		return member, errors.New("site not found")
+	}
+	return member, nil
+}
+
+// CountOwners returns the number of owners of a site
+func CountOwners(db *gorm.DB, siteID uint) (int64, error) {
+	var count int64
+	err := db.Model(&SiteMember{}).Where("site_id = ? AND role = ?", siteID, RoleOwner).Count(&count).Error
+	return count, err
+}
+
+// NormalizeInvitee lowercases the email address or GitHub login of an invitation so that it matches
+// regardless of how it was typed
+func NormalizeInvitee(value string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "@"))
+}
+
+// PendingInvitations returns the unexpired invitations addressed to the email address or GitHub login of a user
+func PendingInvitations(db *gorm.DB, email, githubLogin string) ([]SiteInvitation, error) {
+	query := db.Where("expires_at > ?", time.Now())
+	email = NormalizeInvitee(email)
+	githubLogin = NormalizeInvitee(githubLogin)
+	switch {
+	case email != "" && githubLogin != "":
+		query = query.Where("email = ? OR git_hub_login = ?", email, githubLogin)
+	case email != "":
+		query = query.Where("email = ?", email)
+	case githubLogin != "":
+		query = query.Where("git_hub_login = ?", githubLogin)
+	default:
+		return []SiteInvitation{}, nil
+	}
+
+	var invitations []SiteInvitation
+	if err := query.Order("created_at").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RecordPostAuthor records the creator of a post, keeping the first recorded author of a path
+func RecordPostAuthor(db *gorm.DB, siteID uint, postPath string, userID uint) error {
+	author := PostAuthor{SiteID: siteID, Path: postPath, UserID: userID}
+	return db.Where("site_id = ? AND path = ?", siteID, postPath).FirstOrCreate(&author).Error
+}
+
+// IsPostAuthor returns true if the user created the post at the given path
+func IsPostAuthor(db *gorm.DB, siteID uint, postPath string, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&PostAuthor{}).Where("site_id = ? AND path = ? AND user_id = ?", siteID, postPath, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// MovePostAuthor follows a post to its new path when it is published or unpublished
+func MovePostAuthor(db *gorm.DB, siteID uint, from, to string) error {
+	if from == to {
+		return nil
+	}
+	return db.Model(&PostAuthor{}).Where("site_id = ? AND path = ?", siteID, from).Update("path", to).Error
+}
+
+// BackfillSiteOwners makes the user who registered a site its owner when the site has no members,
+// which is the case for sites registered before sites could be shared
+func BackfillSiteOwners(db *gorm.DB) error {
+	var sites []Site
+	err := db.Where("id NOT IN (?)", db.Model(&SiteMember{}).Select("site_id")).Find(&sites).Error
+	if err != nil {
+		return err
+	}
+
+	for _, site := range sites {
+		if err := db.Create(&SiteMember{SiteID: site.ID, UserID: site.UserID, Role: RoleOwner}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSiteMembers removes the members, invitations and post authors of a site
+func DeleteSiteMembers(db *gorm.DB, siteID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("site_id = ?", siteID).Delete(&SiteMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("site_id = ?", siteID).Delete(&SiteInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("site_id = ?", siteID).Delete(&PostAuthor{}).Error
+	})
+}
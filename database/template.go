@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,6 +11,21 @@ type Template struct {
 	gorm.Model
 	UserID uint   `gorm:"not null;index:idx_user_template,priority:1"`
 	Name   string `gorm:"not null"`
+
+	// WorkspaceID is the workspace the template belongs to, nil for the personal templates of the user
+	WorkspaceID *uint `gorm:"index"`
+
+	// SiteID is the site the template belongs to, nil for personal and workspace templates
+	SiteID *uint `gorm:"index"`
+
+	// Collection is the directory of the site the template is meant for, empty for every directory
+	Collection string `gorm:"not null;default:''"`
+
+	// Default marks the template new posts of its site and collection are created from
+	Default bool `gorm:"column:is_default;not null;default:false"`
+
+	// Path is the file of the site repository the template is defined in, empty for templates only stored here
+	Path string `gorm:"not null;default:''"`
 }
 
 type TemplateField struct {
@@ -22,6 +38,84 @@ type TemplateField struct {
 	DateTimeValue    time.Time        `gorm:"not null;default:'0000-00-00 00:00:00'"`
 	StringSliceValue StringSliceValue `gorm:"not null;default:'[]';serializer:json"`
 	Type             string           `gorm:"not null;default:'string';check:type IN ('string', 'bool', 'number', 'dateTime', 'stringSlice')"`
+
+	// Required fields must be set and not empty in the frontmatter of posts
+	Required bool `gorm:"not null;default:false"`
+
+	// AllowedValues restricts string values, and every value of string slices, to a list when not empty
+	AllowedValues StringSliceValue `gorm:"not null;default:'[]';serializer:json"`
+
+	// Min and Max bound number values
+	Min *float64
+	Max *float64
+
+	// Pattern is a regular expression string values, and every value of string slices, must match
+	Pattern string `gorm:"not null;default:''"`
+
+	// MaxLength is the maximum number of characters of string values, 0 for no limit
+	MaxLength int `gorm:"not null;default:0"`
+
+	// MinDate and MaxDate bound date and time values
+	MinDate *time.Time
+	MaxDate *time.Time
+
+	// HelpText describes the field to the people editing posts
+	HelpText string `gorm:"not null;default:''"`
 }
 
 type StringSliceValue []string
+
+// SiteTemplates scopes a query to the templates of a site
+func SiteTemplates(db *gorm.DB, siteID uint) *gorm.DB {
+	return db.Where("site_id = ?", siteID)
+}
+
+// DefaultTemplate returns the default template for new posts in a collection of a site, falling back
+// to the default template of the whole site. It returns nil when the site has no default template.
+func DefaultTemplate(db *gorm.DB, siteID uint, collection string) (*Template, error) {
+	var templates []Template
+	err := SiteTemplates(db, siteID).
+		Where("is_default = ? AND collection IN ?", true, []string{collection, ""}).
+		Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback *Template
+	for i, template := range templates {
+		if template.Collection == collection {
+			return &templates[i], nil
+		}
+		fallback = &templates[i]
+	}
+	return fallback, nil
+}
+
+// ClearDefaultTemplate unmarks the default template of a collection of a site, except for the given template,
+// so that a collection has a single default template
+func ClearDefaultTemplate(db *gorm.DB, siteID uint, collection string, exceptID uint) error {
+	return SiteTemplates(db, siteID).
+		Model(&Template{}).
+		Where("collection = ? AND id != ?", collection, exceptID).
+		Update("is_default", false).Error
+}
+
+// GetTemplateFields returns the fields of a template in the order they were created
+func GetTemplateFields(db *gorm.DB, templateID uint) ([]TemplateField, error) {
+	var fields []TemplateField
+	if err := db.Where("template_id = ?", templateID).Order("id").Find(&fields).Error; err != nil {
+		return nil, errors.New("failed to fetch template fields")
+	}
+	return fields, nil
+}
+
+// DeleteSiteTemplates removes the templates of a site and their fields
+func DeleteSiteTemplates(db *gorm.DB, siteID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		templates := tx.Session(&gorm.Session{NewDB: true}).Model(&Template{}).Select("id").Where("site_id = ?", siteID)
+		if err := tx.Where("template_id IN (?)", templates).Delete(&TemplateField{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("site_id = ?", siteID).Delete(&Template{}).Error
+	})
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// WorkspaceRoleOwner manages a workspace, its members and deletes it
+	WorkspaceRoleOwner = "owner"
+
+	// WorkspaceRoleAdmin manages the settings, members and records of a workspace
+	WorkspaceRoleAdmin = "admin"
+
+	// WorkspaceRoleMember works on the sites of a workspace with the workspace's default site role
+	WorkspaceRoleMember = "member"
+)
+
+// siteRoleRank orders site roles from the least to the most privileged
+var siteRoleRank = map[string]int{
+	RoleViewer: 1,
+	RoleAuthor: 2,
+	RoleEditor: 3,
+	RoleAdmin:  4,
+	RoleOwner:  5,
+}
+
+// Workspace groups sites, templates and members, optionally mirroring the members of a GitHub organization
+type Workspace struct {
+	gorm.Model
+	Name string `gorm:"not null"`
+
+	// GitHubOrganization is the login of the GitHub organization whose members are synced, empty when not linked
+	GitHubOrganization string `gorm:"not null;default:''"`
+
+	// LinkedByID is the user whose GitHub token is used to sync the members of the organization
+	LinkedByID uint `gorm:"not null;default:0"`
+
+	// GitHubSyncedAt is the time the members of the organization were last synced
+	GitHubSyncedAt *time.Time
+
+	// MediaDirectory is the directory media files are browsed in by default on the sites of the workspace
+	MediaDirectory string `gorm:"not null;default:''"`
+
+	// DefaultSiteRole is the role members of the workspace have on its sites
+	DefaultSiteRole string `gorm:"not null;default:'editor';check:default_site_role IN ('admin', 'editor', 'author', 'viewer')"`
+}
+
+// WorkspaceMember grants a user a role in a workspace
+type WorkspaceMember struct {
+	gorm.Model
+	WorkspaceID uint   `gorm:"not null;uniqueIndex:idx_workspace_member,priority:1"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_workspace_member,priority:2;index"`
+	Role        string `gorm:"not null;check:role IN ('owner', 'admin', 'member')"`
+
+	// Synced is true for members added from the GitHub organization, who are removed when they leave it
+	Synced bool `gorm:"not null;default:false"`
+}
+
+// ValidWorkspaceRole returns true if the given role is a known workspace role
+func ValidWorkspaceRole(role string) bool {
+	return role == WorkspaceRoleOwner || role == WorkspaceRoleAdmin || role == WorkspaceRoleMember
+}
+
+// CanManage returns true if the member may change the settings, members and records of the workspace
+func (m WorkspaceMember) CanManage() bool {
+	return m.Role == WorkspaceRoleOwner || m.Role == WorkspaceRoleAdmin
+}
+
+// CanAssign returns true if the member may give the role to someone, only owners can make other owners
+func (m WorkspaceMember) CanAssign(role string) bool {
+	return m.CanManage() && (role != WorkspaceRoleOwner || m.Role == WorkspaceRoleOwner)
+}
+
+// SiteRole returns the role a member of the workspace has on the sites of the workspace
+func (m WorkspaceMember) SiteRole(workspace Workspace) string {
+	switch m.Role {
+	case WorkspaceRoleOwner:
+		return RoleOwner
+	case WorkspaceRoleAdmin:
+		return RoleAdmin
+	default:
+		return workspace.DefaultSiteRole
+	}
+}
+
+// GetWorkspaceMember retrieves the membership of a user in a workspace
+func GetWorkspaceMember(db *gorm.DB, workspaceID string, userID uint) (WorkspaceMember, error) {
+	var member WorkspaceMember
+	if err := db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return member, errors.New("workspace not found")
+		}
+		return member, errors.New("failed to fetch workspace membership")
+	}
+	return member, nil
+}
+
+// UserWorkspaceIDs returns a subquery selecting the IDs of the workspaces a user is a member of.
+// The subquery starts from a new session so that it can be built from a query that is being chained.
+func UserWorkspaceIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)
+}
+
+// ManagedWorkspaceIDs returns a subquery selecting the IDs of the workspaces a user owns or administers
+func ManagedWorkspaceIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return UserWorkspaceIDs(db, userID).Where("role IN ?", []string{WorkspaceRoleOwner, WorkspaceRoleAdmin})
+}
+
+// AccessibleSites scopes a query to the sites a user is a member of, directly or through a workspace
+func AccessibleSites(db *gorm.DB, userID uint) *gorm.DB {
+	members := db.Session(&gorm.Session{NewDB: true}).Model(&SiteMember{}).Select("site_id").Where("user_id = ?", userID)
+	return db.Where("(id IN (?) OR workspace_id IN (?))", members, UserWorkspaceIDs(db, userID))
+}
+
+// AccessibleTemplates scopes a query to the personal templates of a user and the templates of their workspaces,
+// site templates are reached through their site
+func AccessibleTemplates(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("site_id IS NULL AND ((workspace_id IS NULL AND user_id = ?) OR workspace_id IN (?))", userID, UserWorkspaceIDs(db, userID))
+}
+
+// EditableTemplates scopes a query to the personal templates of a user and the templates of the workspaces they manage
+func EditableTemplates(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("site_id IS NULL AND ((workspace_id IS NULL AND user_id = ?) OR workspace_id IN (?))", userID, ManagedWorkspaceIDs(db, userID))
+}
+
+// CountWorkspaceOwners returns the number of owners of a workspace
+func CountWorkspaceOwners(db *gorm.DB, workspaceID uint) (int64, error) {
+	var count int64
+	err := db.Model(&WorkspaceMember{}).Where("workspace_id = ? AND role = ?", workspaceID, WorkspaceRoleOwner).Count(&count).Error
+	return count, err
+}
+
+// DeleteWorkspaceMembers removes the members of a workspace
+func DeleteWorkspaceMembers(db *gorm.DB, workspaceID uint) error {
+	return db.Unscoped().Where("workspace_id = ?", workspaceID).Delete(&WorkspaceMember{}).Error
+}
+
+// higherSiteRole returns the more privileged of two site roles
+func higherSiteRole(a, b string) string {
+	if siteRoleRank[b] > siteRoleRank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,290 @@
+package deploystatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"static-admin/database"
+	"static-admin/github"
+	"static-admin/provider"
+	"static-admin/publisher"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StatePROpen is a post with pending changes in an open pull request
+	StatePROpen = "pr_open"
+
+	// StateChecksPending is a post whose open pull request is waiting for checks
+	StateChecksPending = "checks_pending"
+
+	// StateChecksFailing is a post whose open pull request has failing checks
+	StateChecksFailing = "checks_failing"
+
+	// StateMerged is a post on the site branch that has not been deployed yet, or whose site is not deployed through GitHub
+	StateMerged = "merged"
+
+	// StateDeploying is a post on the site branch whose deployment is in progress
+	StateDeploying = "deploying"
+
+	// StateDeployed is a post whose latest change is live
+	StateDeployed = "deployed"
+)
+
+const (
+	// pollInterval is how long a branch status is trusted for sites without webhooks
+	pollInterval = time.Minute
+
+	// webhookTrustInterval is how long a branch status is trusted when the site receives webhook deliveries,
+	// which mark it stale as soon as checks or deployments change
+	webhookTrustInterval = 5 * time.Minute
+)
+
+// branchLocks serializes refreshes of the same branch
+var branchLocks sync.Map
+
+// Input identifies the site and repository statuses are read from
+type Input struct {
+	Site  database.Site
+	Owner string
+	Repo  string
+	Token string
+}
+
+// Publication represents the publication state of a post
+type Publication struct {
+	State                 string
+	Branch                string
+	CommitSHA             string
+	PullRequestNumber     int64
+	PullRequestURL        string
+	ChecksState           string
+	DeploymentState       string
+	DeploymentEnvironment string
+
+	// DeploymentURL is the URL of the latest successful deployment of the site branch, which the post
+	// is live under once its state is deployed
+	DeploymentURL string
+}
+
+// Branch returns the status of the head of a branch, refreshing the cached status when it is stale.
+// False is returned for branches that do not exist, such as review branches deleted after a merge.
+func Branch(ctx context.Context, db *gorm.DB, input Input, branch string) (database.BranchStatus, bool, error) {
+	lock, _ := branchLocks.LoadOrStore(fmt.Sprintf("%d:%s", input.Site.ID, branch), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	var status database.BranchStatus
+	err := db.Where("site_id = ? AND branch = ?", input.Site.ID, branch).First(&status).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return database.BranchStatus{}, false, err
+	}
+
+	trustInterval := pollInterval
+	if input.Site.WebhookReceivedAt != nil {
+		trustInterval = webhookTrustInterval
+	}
+	if status.ID != 0 && !status.Stale && time.Since(status.RefreshedAt) < trustInterval {
+		return status, true, nil
+	}
+
+	p, repo, err := provider.RepoForSite(input.Site, input.Token)
+	if err != nil {
+		return database.BranchStatus{}, false, err
+	}
+
+	head, err := p.HeadCommit(ctx, repo, branch)
+	if err != nil {
+		if branch == input.Site.Branch() {
+			return database.BranchStatus{}, false, err
+		}
+		if status.ID != 0 {
+			if err := db.Unscoped().Delete(&status).Error; err != nil {
+				log.Printf("Failed to delete status of branch %s of site %d: %v", branch, input.Site.ID, err)
+			}
+		}
+		return database.BranchStatus{}, false, nil
+	}
+
+	refreshed := database.BranchStatus{
+		Model:       status.Model,
+		SiteID:      input.Site.ID,
+		Branch:      branch,
+		CommitSHA:   head,
+		RefreshedAt: time.Now(),
+	}
+	if input.Site.Provider == "" || input.Site.Provider == provider.GitHub {
+		if err := refreshGitHub(ctx, input, &refreshed); err != nil {
+			return database.BranchStatus{}, false, err
+		}
+	}
+
+	if err := db.Save(&refreshed).Error; err != nil {
+		return database.BranchStatus{}, false, err
+	}
+	return refreshed, true, nil
+}
+
+// refreshGitHub reads the checks, pull request and deployments of the head of a branch from GitHub
+func refreshGitHub(ctx context.Context, input Input, status *database.BranchStatus) error {
+	checks, err := github.FetchCommitChecks(ctx, input.Owner, input.Repo, status.CommitSHA, input.Token)
+	if err != nil {
+		return err
+	}
+	status.ChecksState = checks.State
+
+	// the site branch is the base of review pull requests rather than the head of one
+	if status.Branch != input.Site.Branch() {
+		pr, found, err := github.FindPullRequestForBranch(ctx, input.Owner, input.Repo, status.Branch, input.Token)
+		if err != nil {
+			return err
+		}
+		if found {
+			status.PullRequestNumber = pr.Number
+			status.PullRequestURL = pr.HtmlURL
+			status.PullRequestState = pr.State
+			if pr.Merged {
+				status.PullRequestState = "merged"
+			}
+		}
+	}
+
+	deployments, err := github.FetchDeployments(ctx, input.Owner, input.Repo, status.Branch, input.Token)
+	if err != nil {
+		return err
+	}
+	if deployments.Latest != nil {
+		status.DeploymentState = deployments.Latest.State
+		status.DeploymentEnvironment = deployments.Latest.Environment
+	}
+	if deployments.Successful != nil {
+		deployedAt := deployments.Successful.CreatedAt
+		status.DeployedSHA = deployments.Successful.SHA
+		status.DeployedAt = &deployedAt
+		status.DeploymentURL = deployments.Successful.EnvironmentURL
+	}
+	return nil
+}
+
+// Invalidate marks the status of a branch as stale so the next read refreshes it
+func Invalidate(db *gorm.DB, siteID uint, branch string) {
+	err := db.Model(&database.BranchStatus{}).Where("site_id = ? AND branch = ?", siteID, branch).Update("stale", true).Error
+	if err != nil {
+		log.Printf("Failed to invalidate status of branch %s of site %d: %v", branch, siteID, err)
+	}
+}
+
+// InvalidateSite marks the status of every branch of a site as stale
+func InvalidateSite(db *gorm.DB, siteID uint) {
+	err := db.Model(&database.BranchStatus{}).Where("site_id = ?", siteID).Update("stale", true).Error
+	if err != nil {
+		log.Printf("Failed to invalidate branch statuses of site %d: %v", siteID, err)
+	}
+}
+
+// InvalidateCommit marks the status of every branch whose head is the given commit as stale
+func InvalidateCommit(db *gorm.DB, siteID uint, commitSHA string) {
+	err := db.Model(&database.BranchStatus{}).Where("site_id = ? AND commit_sha = ?", siteID, commitSHA).Update("stale", true).Error
+	if err != nil {
+		log.Printf("Failed to invalidate status of commit %s of site %d: %v", commitSHA, siteID, err)
+	}
+}
+
+// PostPublication computes the publication state of a post from the status of its review branch, which is nil
+// when the post has no pending changes, and of the site branch. A post on the site branch counts as deployed once
+// a successful deployment was created after the last commit changing it.
+func PostPublication(review *database.BranchStatus, site database.BranchStatus, lastCommitDate time.Time) Publication {
+	if review != nil && review.PullRequestState == "open" {
+		publication := Publication{
+			State:                 StatePROpen,
+			Branch:                review.Branch,
+			CommitSHA:             review.CommitSHA,
+			PullRequestNumber:     review.PullRequestNumber,
+			PullRequestURL:        review.PullRequestURL,
+			ChecksState:           review.ChecksState,
+			DeploymentState:       review.DeploymentState,
+			DeploymentEnvironment: review.DeploymentEnvironment,
+			DeploymentURL:         site.DeploymentURL,
+		}
+		switch review.ChecksState {
+		case "pending":
+			publication.State = StateChecksPending
+		case "failure":
+			publication.State = StateChecksFailing
+		}
+		return publication
+	}
+
+	publication := Publication{
+		State:                 StateMerged,
+		Branch:                site.Branch,
+		CommitSHA:             site.CommitSHA,
+		ChecksState:           site.ChecksState,
+		DeploymentState:       site.DeploymentState,
+		DeploymentEnvironment: site.DeploymentEnvironment,
+		DeploymentURL:         site.DeploymentURL,
+	}
+	switch {
+	case site.DeployedAt != nil && !lastCommitDate.IsZero() && !site.DeployedAt.Before(lastCommitDate):
+		publication.State = StateDeployed
+	case site.DeployedSHA != "" && site.DeployedSHA == site.CommitSHA:
+		// the head of the site branch is deployed, so every post on it is live
+		publication.State = StateDeployed
+	case site.DeploymentState == "pending" || site.DeploymentState == "queued" || site.DeploymentState == "in_progress":
+		publication.State = StateDeploying
+	}
+	return publication
+}
+
+// Posts computes the publication state of posts, given by path with the date of the last commit changing them.
+// Pull requests are only looked up on GitHub, posts of other providers are reported as merged.
+func Posts(ctx context.Context, db *gorm.DB, input Input, lastCommitDates map[string]time.Time) (map[string]Publication, error) {
+	site, _, err := Branch(ctx, db, input, input.Site.Branch())
+	if err != nil {
+		return nil, err
+	}
+
+	openBranches := map[string]bool{}
+	if input.Site.Provider == "" || input.Site.Provider == provider.GitHub {
+		prs, err := github.ListPullRequests(ctx, github.ListPullRequestsInput{
+			Owner:      input.Owner,
+			Repo:       input.Repo,
+			BaseBranch: input.Site.Branch(),
+			State:      "open",
+			Token:      input.Token,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			openBranches[pr.Head.Ref] = true
+		}
+	}
+
+	publications := make(map[string]Publication, len(lastCommitDates))
+	for postPath, lastCommitDate := range lastCommitDates {
+		var review *database.BranchStatus
+		for _, branch := range []string{publisher.ReviewBranch(input.Site, postPath), publisher.CreateBranch(input.Site, postPath)} {
+			if !openBranches[branch] {
+				continue
+			}
+
+			status, found, err := Branch(ctx, db, input, branch)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				review = &status
+				break
+			}
+		}
+
+		publications[postPath] = PostPublication(review, site, lastCommitDate)
+	}
+	return publications, nil
+}
@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"static-admin/markdown"
+)
+
+const (
+	// Jekyll sites keep drafts in a _drafts directory and support `published: false`
+	Jekyll = "jekyll"
+
+	// Hugo sites mark drafts with `draft: true`
+	Hugo = "hugo"
+
+	// Eleventy sites mark drafts with `published: false`
+	Eleventy = "eleventy"
+
+	// Other is used for generators without any special conventions
+	Other = "other"
+)
+
+const (
+	// StatusDraft is a post that is not yet published
+	StatusDraft = "draft"
+
+	// StatusScheduled is a published post with a date in the future
+	StatusScheduled = "scheduled"
+
+	// StatusPublished is a post that is live on the site
+	StatusPublished = "published"
+)
+
+// datePrefixRegex matches the date prefix used by Jekyll post filenames
+var datePrefixRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+
+// Valid returns true if the given generator is supported
+func Valid(generator string) bool {
+	return generator == Jekyll || generator == Hugo || generator == Eleventy || generator == Other
+}
+
+// configFiles are the configuration files identifying each generator at the root of a site
+var configFiles = map[string]string{
+	"_config.yml":         Jekyll,
+	"_config.yaml":        Jekyll,
+	"_config.toml":        Jekyll,
+	"hugo.toml":           Hugo,
+	"hugo.yaml":           Hugo,
+	"hugo.json":           Hugo,
+	"config.toml":         Hugo,
+	".eleventy.js":        Eleventy,
+	".eleventy.cjs":       Eleventy,
+	"eleventy.config.js":  Eleventy,
+	"eleventy.config.cjs": Eleventy,
+	"eleventy.config.mjs": Eleventy,
+}
+
+// Detect guesses the generator of a site from the paths of its files, relative to the site root.
+// An empty string is returned when no configuration file is found.
+func Detect(paths []string) string {
+	for _, filePath := range paths {
+		if generator, ok := configFiles[filePath]; ok {
+			return generator
+		}
+	}
+	return ""
+}
+
+// ValidStatus returns true if the given post status is known
+func ValidStatus(status string) bool {
+	return status == StatusDraft || status == StatusScheduled || status == StatusPublished
+}
+
+// PostsDirectory returns the directory published posts live in for a generator
+func PostsDirectory(generator string) string {
+	switch generator {
+	case Hugo:
+		return "content/posts"
+	case Eleventy:
+		return "posts"
+	default:
+		return "_posts"
+	}
+}
+
+// DraftsDirectory returns the directory drafts live in for a generator,
+// or an empty string if drafts live alongside published posts
+func DraftsDirectory(generator string) string {
+	if generator == Jekyll {
+		return "_drafts"
+	}
+	return ""
+}
+
+// StaticDirectory returns the directory that files served from the root of the site are copied from,
+// or an empty string if they are served from the root of the repository
+func StaticDirectory(generator string) string {
+	if generator == Hugo {
+		return "static"
+	}
+	return ""
+}
+
+// PostDirectories returns every directory that may contain posts for a generator
+func PostDirectories(generator string) []string {
+	directories := []string{PostsDirectory(generator)}
+	if drafts := DraftsDirectory(generator); drafts != "" {
+		directories = append(directories, drafts)
+	}
+	return directories
+}
+
+// PostStatus computes the publication status of a post from its path and frontmatter
+func PostStatus(generator, filePath string, fields []markdown.FrontmatterField, now time.Time) string {
+	if drafts := DraftsDirectory(generator); drafts != "" && strings.HasPrefix(filePath, drafts+"/") {
+		return StatusDraft
+	}
+
+	for _, field := range fields {
+		switch {
+		case field.Name == "draft" && field.Type == "bool" && generator == Hugo:
+			if field.BoolValue {
+				return StatusDraft
+			}
+		case field.Name == "published" && field.Type == "bool" && generator != Hugo:
+			if !field.BoolValue {
+				return StatusDraft
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if field.Name == "date" && field.Type == "dateTime" && field.DateTimeValue.After(now) {
+			return StatusScheduled
+		}
+	}
+
+	return StatusPublished
+}
+
+// PublishedPath returns the path a post should live at once it is published
+func PublishedPath(generator, filePath string, date time.Time) string {
+	drafts := DraftsDirectory(generator)
+	if drafts == "" || !strings.HasPrefix(filePath, drafts+"/") {
+		return filePath
+	}
+
+	fileName := path.Base(filePath)
+	if !datePrefixRegex.MatchString(fileName) {
+		fileName = date.Format("2006-01-02") + "-" + fileName
+	}
+	return path.Join(PostsDirectory(generator), fileName)
+}
+
+// DraftPath returns the path a post should live at once it is unpublished
+func DraftPath(generator, filePath string) string {
+	drafts := DraftsDirectory(generator)
+	if drafts == "" || strings.HasPrefix(filePath, drafts+"/") {
+		return filePath
+	}
+
+	// jekyll drafts are dated when they are published
+	fileName := datePrefixRegex.ReplaceAllString(path.Base(filePath), "")
+	return path.Join(drafts, fileName)
+}
+
+// SetDraft rewrites the frontmatter of a post to mark it as a draft or as published
+func SetDraft(generator string, fields []markdown.FrontmatterField, draft bool) []markdown.FrontmatterField {
+	name := "published"
+	value := !draft
+	if generator == Hugo {
+		name = "draft"
+		value = draft
+	}
+
+	result := []markdown.FrontmatterField{}
+	for _, field := range fields {
+		// hugo uses `draft` while everything else uses `published`
+		if field.Name == "draft" || field.Name == "published" {
+			continue
+		}
+		result = append(result, field)
+	}
+
+	// jekyll drafts are tracked by directory, so the flag is only needed to unpublish elsewhere
+	if generator == Jekyll || (!draft && generator != Hugo) {
+		return result
+	}
+
+	return append(result, markdown.FrontmatterField{
+		Name:             name,
+		BoolValue:        value,
+		StringSliceValue: []string{},
+		Type:             "bool",
+	})
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"static-admin/cache"
+)
+
+// cacheNamespace is the namespace of GitHub API responses in the cache
+const cacheNamespace = "github"
+
+// cachedResponse is a response body kept together with its validators so it can be revalidated
+type cachedResponse struct {
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+	Link         string `json:"link"`
+	Body         []byte `json:"body"`
+}
+
+var responseCache atomic.Pointer[cache.Cache]
+
+func init() {
+	SetCache(cache.NewMemory(cache.DefaultMaxEntries, nil))
+}
+
+// Cache returns the cache that GitHub API responses are stored in
+func Cache() cache.Cache {
+	return *responseCache.Load()
+}
+
+// SetCache replaces the cache that GitHub API responses are stored in
+func SetCache(c cache.Cache) {
+	responseCache.Store(&c)
+}
+
+// responseCacheKey scopes a cached response to the token it was fetched with and its absolute URL
+func responseCacheKey(token, url string) string {
+	return tokenKey("Bearer "+token) + ":" + url
+}
+
+// getCachedResponse retrieves a cached response
+func getCachedResponse(key string) (cachedResponse, bool) {
+	data, ok := Cache().Get(cacheNamespace, key)
+	if !ok {
+		return cachedResponse{}, false
+	}
+
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+// setCachedResponse stores a response, tagged with its user and the repository or owner it belongs to
+func setCachedResponse(key, token string, requestURL *url.URL, entry cachedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tags := append([]string{cache.UserTag(tokenKey("Bearer " + token))}, pathTags(requestURL.Path)...)
+	Cache().Set(cacheNamespace, key, data, tags...)
+}
+
+// pathTags returns the repository or owner tags of an API path
+func pathTags(path string) []string {
+	if i := strings.Index(path, "/repos/"); i >= 0 {
+		parts := strings.SplitN(path[i+len("/repos/"):], "/", 3)
+		if len(parts) >= 2 {
+			return []string{cache.RepositoryTag(parts[0], parts[1])}
+		}
+	}
+
+	for _, prefix := range []string{"/orgs/", "/users/"} {
+		if i := strings.Index(path, prefix); i >= 0 {
+			parts := strings.SplitN(path[i+len(prefix):], "/", 3)
+			if len(parts) >= 2 && parts[1] == "repos" {
+				return []string{cache.OwnerTag(parts[0])}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetCached sends a GET request, revalidating a previously cached response with its ETag or
+// Last-Modified date. A 304 response reuses the cached body and does not count against the rate limit.
+// The body and the Link header of the response are returned.
+func (c *Client) GetCached(ctx context.Context, path, token string) ([]byte, string, error) {
+	req, err := c.NewRequest(ctx, "GET", path, token, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := responseCacheKey(token, req.URL.String())
+	cached, ok := getCachedResponse(key)
+	if ok {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		c.count(tokenKey(req.Header.Get("Authorization")), func(m *ClientMetrics) { m.NotModified++ })
+		return cached.Body, cached.Link, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("API request failed: %s (status: %d)", string(body), resp.StatusCode)
+	}
+
+	entry := cachedResponse{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Link:         resp.Header.Get("Link"),
+		Body:         body,
+	}
+	if entry.ETag != "" || entry.LastModified != "" {
+		setCachedResponse(key, token, req.URL, entry)
+	}
+
+	return body, entry.Link, nil
+}
+
+// getCachedPages reads every page of a paginated listing, passing each page body to the callback
+func getCachedPages(ctx context.Context, path, token string, page func(body []byte) error) error {
+	for path != "" {
+		body, link, err := DefaultClient().GetCached(ctx, path, token)
+		if err != nil {
+			return err
+		}
+
+		if err := page(body); err != nil {
+			return err
+		}
+
+		path = ""
+		if link != "" {
+			path = extractNextPageURL(link)
+		}
+	}
+
+	return nil
+}
+
+// InvalidateRepository removes cached responses for a repository and the repository listings of its owner
+func InvalidateRepository(owner, repo string) {
+	Cache().Invalidate(cache.RepositoryTag(owner, repo), cache.OwnerTag(owner))
+}
+
+// InvalidateUser removes every cached response fetched with the given token
+func InvalidateUser(token string) {
+	Cache().Invalidate(cache.UserTag(tokenKey("Bearer " + token)))
+}
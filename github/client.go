@@ -0,0 +1,474 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultBaseURL is the base URL of the public GitHub API
+const DefaultBaseURL = "https://api.github.com"
+
+// ErrRateLimited is returned when the rate limit is exhausted and resets too far in the future to wait for
+var ErrRateLimited = errors.New("GitHub rate limit exceeded")
+
+// RateLimit represents the quota of a token for a single API resource
+type RateLimit struct {
+	Resource  string    `json:"resource"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	Reset     time.Time `json:"reset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ClientMetrics represents the request counters and quotas observed for a single token
+type ClientMetrics struct {
+	Requests       int64                `json:"requests"`
+	Retries        int64                `json:"retries"`
+	RateLimitWaits int64                `json:"rate_limit_waits"`
+	NotModified    int64                `json:"not_modified"`
+	RateLimits     map[string]RateLimit `json:"rate_limits"`
+}
+
+// Client sends requests to the GitHub API, retrying transient failures and honoring rate limits
+type Client struct {
+	// BaseURL is the API root that relative request paths are resolved against
+	BaseURL string
+
+	// HTTPClient is the client used to send requests
+	HTTPClient *http.Client
+
+	// MaxRetries is the number of times a failed request is retried
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between retries
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxRateLimitWait is the longest a request waits for the rate limit to reset
+	MaxRateLimitWait time.Duration
+
+	metricsLock sync.Mutex
+	metrics     map[string]*ClientMetrics
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithBaseURL sets the API root, for example to point the client at GitHub Enterprise or a test server
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithTransport sets the transport used to send requests
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// WithTimeout sets the timeout of a single request attempt
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient.Timeout = timeout
+	}
+}
+
+// WithMaxRetries sets the number of times a failed request is retried
+func WithMaxRetries(retries int) ClientOption {
+	return func(c *Client) {
+		c.MaxRetries = retries
+	}
+}
+
+// WithBackoff sets the bounds of the exponential backoff between retries
+func WithBackoff(min, max time.Duration) ClientOption {
+	return func(c *Client) {
+		c.MinBackoff = min
+		c.MaxBackoff = max
+	}
+}
+
+// WithMaxRateLimitWait sets the longest a request waits for the rate limit to reset
+func WithMaxRateLimitWait(wait time.Duration) ClientOption {
+	return func(c *Client) {
+		c.MaxRateLimitWait = wait
+	}
+}
+
+// NewClient creates a new GitHub API client
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		BaseURL:          DefaultBaseURL,
+		HTTPClient:       &http.Client{Timeout: 30 * time.Second},
+		MaxRetries:       3,
+		MinBackoff:       500 * time.Millisecond,
+		MaxBackoff:       30 * time.Second,
+		MaxRateLimitWait: time.Minute,
+		metrics:          make(map[string]*ClientMetrics),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var defaultClient atomic.Pointer[Client]
+
+func init() {
+	defaultClient.Store(NewClient())
+}
+
+// DefaultClient returns the client used by the package level functions
+func DefaultClient() *Client {
+	return defaultClient.Load()
+}
+
+// SetDefaultClient replaces the client used by the package level functions
+func SetDefaultClient(c *Client) {
+	defaultClient.Store(c)
+}
+
+// URL resolves a request path against the base URL, leaving absolute URLs such as pagination links untouched
+func (c *Client) URL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.BaseURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// GraphQLURL returns the GraphQL endpoint that belongs to the base URL
+func (c *Client) GraphQLURL() string {
+	// GitHub Enterprise serves the REST API under /api/v3 and GraphQL under /api/graphql
+	if strings.HasSuffix(c.BaseURL, "/api/v3") {
+		return strings.TrimSuffix(c.BaseURL, "/v3") + "/graphql"
+	}
+	return c.BaseURL + "/graphql"
+}
+
+// NewRequest creates an authenticated API request, encoding the payload as JSON
+func (c *Client) NewRequest(ctx context.Context, method, path, token string, payload interface{}) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL(path), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("authentication token is required")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// Do sends a request, waiting out exhausted rate limits and retrying rate limited requests
+// with exponential backoff. Network and server errors are only retried for GET and HEAD
+// requests, since other requests may already have been applied when they fail.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := tokenKey(req.Header.Get("Authorization"))
+	idempotent := isIdempotent(req.Method)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForRateLimit(ctx, key); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		c.count(key, func(m *ClientMetrics) { m.Requests++ })
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			if !idempotent || ctx.Err() != nil || attempt >= c.MaxRetries {
+				return nil, err
+			}
+			if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			c.count(key, func(m *ClientMetrics) { m.Retries++ })
+			continue
+		}
+
+		c.recordRateLimit(key, resp)
+
+		wait, retry := c.retryDelay(resp, attempt, idempotent)
+		if !retry || attempt >= c.MaxRetries {
+			if isRateLimited(resp) {
+				resp.Body.Close()
+				return nil, ErrRateLimited
+			}
+			return resp, nil
+		}
+
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if wait > c.MaxRateLimitWait {
+			return nil, ErrRateLimited
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		c.count(key, func(m *ClientMetrics) { m.Retries++ })
+	}
+}
+
+// RoundTrip implements http.RoundTripper so that other GitHub API clients share the
+// retries, rate limit handling and metrics of the client
+func (c *Client) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.Do(req)
+}
+
+// OAuth2Context returns a context that makes oauth2 clients send their requests through the client
+func (c *Client) OAuth2Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: c})
+}
+
+// RESTBaseURL returns the base URL in the form expected by go-github clients
+func (c *Client) RESTBaseURL() (*url.URL, error) {
+	return url.Parse(c.BaseURL + "/")
+}
+
+// DoJSON sends a request and decodes the JSON response into result, which may be nil
+func (c *Client) DoJSON(ctx context.Context, method, path, token string, payload interface{}, result interface{}) error {
+	req, err := c.NewRequest(ctx, method, path, token, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("API request failed: %s (status: %d)", string(respBody), resp.StatusCode)
+	}
+
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return nil
+}
+
+// Metrics returns the request counters and quotas observed for a token
+func (c *Client) Metrics(token string) ClientMetrics {
+	c.metricsLock.Lock()
+	defer c.metricsLock.Unlock()
+
+	result := ClientMetrics{RateLimits: map[string]RateLimit{}}
+	m, ok := c.metrics[tokenKey("Bearer "+token)]
+	if !ok {
+		return result
+	}
+
+	result.Requests = m.Requests
+	result.Retries = m.Retries
+	result.RateLimitWaits = m.RateLimitWaits
+	result.NotModified = m.NotModified
+	for resource, limit := range m.RateLimits {
+		result.RateLimits[resource] = limit
+	}
+	return result
+}
+
+// retryDelay returns how long to wait before retrying a response, and whether it should be retried at all.
+// Requests that are not idempotent are only retried when GitHub rejected them because of a rate limit,
+// as it guarantees that those requests were not applied.
+func (c *Client) retryDelay(resp *http.Response, attempt int, idempotent bool) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode >= 500:
+		return c.backoff(attempt), idempotent
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden:
+		// secondary rate limits tell us how long to back off for
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, err := strconv.Atoi(retryAfter); err == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+
+		if !idempotent && resp.StatusCode != http.StatusTooManyRequests {
+			return 0, false
+		}
+
+		// the primary rate limit is exhausted until it resets
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, ok := parseReset(resp.Header.Get("X-RateLimit-Reset")); ok {
+				return time.Until(reset), true
+			}
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return c.backoff(attempt), true
+		}
+	}
+
+	return 0, false
+}
+
+// backoff returns the exponential backoff with jitter for an attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.MinBackoff << attempt
+	if delay <= 0 || delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// waitForRateLimit blocks until the core rate limit of a token resets if it is known to be exhausted
+func (c *Client) waitForRateLimit(ctx context.Context, key string) error {
+	c.metricsLock.Lock()
+	var wait time.Duration
+	if m, ok := c.metrics[key]; ok {
+		if limit, ok := m.RateLimits["core"]; ok && limit.Remaining == 0 {
+			wait = time.Until(limit.Reset)
+		}
+	}
+	c.metricsLock.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if wait > c.MaxRateLimitWait {
+		return ErrRateLimited
+	}
+
+	c.count(key, func(m *ClientMetrics) { m.RateLimitWaits++ })
+	return c.sleep(ctx, wait)
+}
+
+// recordRateLimit stores the rate limit headers of a response
+func (c *Client) recordRateLimit(key string, resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+
+	limit := RateLimit{
+		Resource:  resp.Header.Get("X-RateLimit-Resource"),
+		UpdatedAt: time.Now(),
+	}
+	if limit.Resource == "" {
+		limit.Resource = "core"
+	}
+	limit.Remaining, _ = strconv.Atoi(remaining)
+	limit.Limit, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	limit.Used, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Used"))
+	if reset, ok := parseReset(resp.Header.Get("X-RateLimit-Reset")); ok {
+		limit.Reset = reset
+	}
+
+	c.count(key, func(m *ClientMetrics) { m.RateLimits[limit.Resource] = limit })
+}
+
+// count applies an update to the metrics of a token
+func (c *Client) count(key string, update func(*ClientMetrics)) {
+	c.metricsLock.Lock()
+	defer c.metricsLock.Unlock()
+
+	m, ok := c.metrics[key]
+	if !ok {
+		m = &ClientMetrics{RateLimits: map[string]RateLimit{}}
+		c.metrics[key] = m
+	}
+	update(m)
+}
+
+// sleep waits for the given duration or until the context is done
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isIdempotent returns true if a request can safely be sent again after an unknown outcome
+func isIdempotent(method string) bool {
+	return method == "" || method == http.MethodGet || method == http.MethodHead
+}
+
+// isRateLimited returns true if a response was rejected because of the rate limit
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "")
+}
+
+// parseReset parses the unix timestamp of an X-RateLimit-Reset header
+func parseReset(value string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// tokenKey identifies a token in the metrics without keeping the token itself in memory
+func tokenKey(authorization string) string {
+	token := authorization
+	if _, after, ok := strings.Cut(authorization, " "); ok {
+		token = after
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
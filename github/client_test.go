@@ -0,0 +1,336 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testResponse is a canned response of a test server
+type testResponse struct {
+	status  int
+	headers map[string]string
+	// drop closes the connection without responding
+	drop bool
+}
+
+// testServer replies with the given responses in order, repeating the last one, and records the requests it receives
+type testServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses []testResponse
+	bodies    []string
+}
+
+func newTestServer(t *testing.T, responses ...testResponse) *testServer {
+	t.Helper()
+	s := &testServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		i := min(len(s.bodies), len(s.responses)-1)
+		s.bodies = append(s.bodies, string(body))
+		resp := s.responses[i]
+		s.mu.Unlock()
+
+		if resp.drop {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("failed to hijack connection: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+
+		for name, value := range resp.headers {
+			w.Header().Set(name, value)
+		}
+		w.WriteHeader(resp.status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// attempts returns the number of requests the server received
+func (s *testServer) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func newTestClient(s *testServer, opts ...ClientOption) *Client {
+	opts = append([]ClientOption{
+		WithBaseURL(s.URL),
+		WithBackoff(time.Millisecond, 2*time.Millisecond),
+		WithMaxRetries(2),
+		WithMaxRateLimitWait(5 * time.Second),
+	}, opts...)
+	return NewClient(opts...)
+}
+
+func TestClientRetries(t *testing.T) {
+	retryAfter := map[string]string{"Retry-After": "0"}
+	exhausted := map[string]string{
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10),
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		responses []testResponse
+		attempts  int
+		status    int
+		err       error
+	}{
+		{"get succeeds", http.MethodGet, []testResponse{{status: 200}}, 1, 200, nil},
+		{"get retries server errors", http.MethodGet, []testResponse{{status: 502}, {status: 200}}, 2, 200, nil},
+		{"get gives up after max retries", http.MethodGet, []testResponse{{status: 500}}, 3, 500, nil},
+		{"head retries server errors", http.MethodHead, []testResponse{{status: 503}, {status: 200}}, 2, 200, nil},
+		{"get does not retry client errors", http.MethodGet, []testResponse{{status: 404}}, 1, 404, nil},
+		{"get does not retry plain forbidden", http.MethodGet, []testResponse{{status: 403}}, 1, 403, nil},
+		{"get retries exhausted primary limit", http.MethodGet, []testResponse{{status: 403, headers: exhausted}, {status: 200}}, 2, 200, nil},
+		{"post does not retry server errors", http.MethodPost, []testResponse{{status: 500}, {status: 201}}, 1, 500, nil},
+		{"put does not retry server errors", http.MethodPut, []testResponse{{status: 502}, {status: 200}}, 1, 502, nil},
+		{"delete does not retry server errors", http.MethodDelete, []testResponse{{status: 503}, {status: 204}}, 1, 503, nil},
+		{"post retries too many requests", http.MethodPost, []testResponse{{status: 429}, {status: 201}}, 2, 201, nil},
+		{"post retries secondary rate limit", http.MethodPost, []testResponse{{status: 403, headers: retryAfter}, {status: 201}}, 2, 201, nil},
+		{"post does not retry exhausted primary limit", http.MethodPost, []testResponse{{status: 403, headers: exhausted}, {status: 201}}, 1, 0, ErrRateLimited},
+		{"post does not retry plain forbidden", http.MethodPost, []testResponse{{status: 403}, {status: 201}}, 1, 403, nil},
+		{"rate limit outlasting retries", http.MethodGet, []testResponse{{status: 429, headers: retryAfter}}, 3, 0, ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.responses...)
+			c := newTestClient(s)
+
+			req, err := c.NewRequest(context.Background(), tt.method, "/repos/owner/repo", "token", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.Do(req)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Do() error = %v, want %v", err, tt.err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.status {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+				}
+			}
+
+			if got := s.attempts(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+			if got := c.Metrics("token").Retries; got != int64(tt.attempts-1) {
+				t.Errorf("retries = %d, want %d", got, tt.attempts-1)
+			}
+		})
+	}
+}
+
+func TestClientNetworkErrors(t *testing.T) {
+	tests := []struct {
+		method   string
+		attempts int
+		wantErr  bool
+	}{
+		{http.MethodGet, 2, false},
+		{http.MethodHead, 2, false},
+		{http.MethodPost, 1, true},
+		{http.MethodPatch, 1, true},
+		{http.MethodDelete, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			s := newTestServer(t, testResponse{drop: true}, testResponse{status: 200})
+			c := newTestClient(s)
+
+			req, err := c.NewRequest(context.Background(), tt.method, "/repos/owner/repo", "token", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := s.attempts(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestClientRetryResendsBody(t *testing.T) {
+	s := newTestServer(t, testResponse{status: 429}, testResponse{status: 201})
+	c := newTestClient(s)
+
+	err := c.DoJSON(context.Background(), http.MethodPost, "/repos/owner/repo/pulls", "token", map[string]string{"title": "Update"}, nil)
+	if err != nil {
+		t.Fatalf("DoJSON() error = %v", err)
+	}
+
+	want := `{"title":"Update"}`
+	if len(s.bodies) != 2 || s.bodies[0] != want || s.bodies[1] != want {
+		t.Errorf("bodies = %q, want the payload twice", s.bodies)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		minWait    time.Duration
+		err        error
+	}{
+		{"waits for the given seconds", "1", time.Second, nil},
+		{"gives up when longer than the max wait", "60", 0, ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t,
+				testResponse{status: 403, headers: map[string]string{"Retry-After": tt.retryAfter}},
+				testResponse{status: 200},
+			)
+			c := newTestClient(s)
+
+			req, err := c.NewRequest(context.Background(), http.MethodGet, "/user", "token", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			resp, err := c.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Do() error = %v, want %v", err, tt.err)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minWait {
+				t.Errorf("waited %v, want at least %v", elapsed, tt.minWait)
+			}
+		})
+	}
+}
+
+func TestClientRetryAfterCancelled(t *testing.T) {
+	s := newTestServer(t, testResponse{status: 429, headers: map[string]string{"Retry-After": "3"}})
+	c := newTestClient(s)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := c.NewRequest(ctx, http.MethodGet, "/user", "token", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClientRateLimitAccounting(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	s := newTestServer(t,
+		testResponse{status: 200, headers: map[string]string{
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Remaining": "4999",
+			"X-RateLimit-Used":      "1",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}},
+		testResponse{status: 200, headers: map[string]string{
+			"X-RateLimit-Limit":     "30",
+			"X-RateLimit-Remaining": "29",
+			"X-RateLimit-Used":      "1",
+			"X-RateLimit-Resource":  "search",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}},
+		testResponse{status: 200, headers: map[string]string{
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Used":      "5000",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		}},
+	)
+	c := newTestClient(s)
+	ctx := context.Background()
+
+	for _, path := range []string{"/user", "/search/code", "/user"} {
+		if err := c.DoJSON(ctx, http.MethodGet, path, "token", nil, nil); err != nil {
+			t.Fatalf("DoJSON(%s) error = %v", path, err)
+		}
+	}
+
+	metrics := c.Metrics("token")
+	if metrics.Requests != 3 {
+		t.Errorf("requests = %d, want 3", metrics.Requests)
+	}
+	core := metrics.RateLimits["core"]
+	if core.Limit != 5000 || core.Remaining != 0 || core.Used != 5000 || !core.Reset.Equal(reset) {
+		t.Errorf("core rate limit = %+v", core)
+	}
+	search := metrics.RateLimits["search"]
+	if search.Limit != 30 || search.Remaining != 29 || search.Used != 1 {
+		t.Errorf("search rate limit = %+v", search)
+	}
+
+	// the core limit is exhausted for an hour, so the request fails without reaching the server
+	if err := c.DoJSON(ctx, http.MethodGet, "/user", "token", nil, nil); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("DoJSON() error = %v, want %v", err, ErrRateLimited)
+	}
+	if got := s.attempts(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+
+	// other tokens have their own quota
+	if err := c.DoJSON(ctx, http.MethodGet, "/user", "other", nil, nil); err != nil {
+		t.Fatalf("DoJSON() with another token error = %v", err)
+	}
+	if got := c.Metrics("other").Requests; got != 1 {
+		t.Errorf("requests of other token = %d, want 1", got)
+	}
+	if got := c.Metrics("token").Requests; got != 3 {
+		t.Errorf("requests of exhausted token = %d, want 3", got)
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	c := NewClient(WithBackoff(100*time.Millisecond, time.Second))
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{70, time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if got := c.backoff(tt.attempt); got < tt.max/2 || got > tt.max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
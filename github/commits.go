@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// maxRenameHops limits how many renames are followed when listing the history of a file
+const maxRenameHops = 10
+
+// FileRevision represents a commit that changed a file
+type FileRevision struct {
+	SHA     string
+	Path    string
+	Author  string
+	Date    time.Time
+	Message string
+}
+
+// FetchFileHistoryInput represents the input parameters for the FetchFileHistory function
+type FetchFileHistoryInput struct {
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+	Token string
+}
+
+// commitListItem represents a commit returned by the commits API
+type commitListItem struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+	Files []struct {
+		Filename         string `json:"filename"`
+		Status           string `json:"status"`
+		PreviousFilename string `json:"previous_filename"`
+	} `json:"files"`
+}
+
+// FetchFileHistory lists the commits that changed a file on a ref, newest first.
+// The commits API does not follow renames, so the commit adding the file is checked
+// for a rename and the history of the previous path is appended.
+func FetchFileHistory(ctx context.Context, input FetchFileHistoryInput) ([]FileRevision, error) {
+	var revisions []FileRevision
+	path, ref := input.Path, input.Ref
+	for hop := 0; hop <= maxRenameHops; hop++ {
+		var oldest commitListItem
+		listPath := fmt.Sprintf("/repos/%s/%s/commits?sha=%s&path=%s&per_page=100", input.Owner, input.Repo, url.QueryEscape(ref), url.QueryEscape(path))
+		err := getCachedPages(ctx, listPath, input.Token, func(body []byte) error {
+			var commits []commitListItem
+			if err := json.Unmarshal(body, &commits); err != nil {
+				return fmt.Errorf("failed to parse response: %v", err)
+			}
+			for _, commit := range commits {
+				revisions = append(revisions, commit.revision(path))
+				oldest = commit
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if oldest.SHA == "" || len(oldest.Parents) == 0 {
+			return revisions, nil
+		}
+
+		// commits are immutable, so the details of the oldest commit are served from the cache after the first read
+		body, _, err := DefaultClient().GetCached(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s", input.Owner, input.Repo, oldest.SHA), input.Token)
+		if err != nil {
+			return nil, err
+		}
+		var details commitListItem
+		if err := json.Unmarshal(body, &details); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %v", err)
+		}
+
+		previous := ""
+		for _, file := range details.Files {
+			if file.Filename == path && file.Status == "renamed" {
+				previous = file.PreviousFilename
+			}
+		}
+		if previous == "" {
+			return revisions, nil
+		}
+		path, ref = previous, oldest.Parents[0].SHA
+	}
+	return revisions, nil
+}
+
+// revision converts a commit of the commits API, preferring the GitHub login of the author
+func (commit commitListItem) revision(path string) FileRevision {
+	author := commit.Commit.Author.Name
+	if commit.Author != nil && commit.Author.Login != "" {
+		author = commit.Author.Login
+	}
+	return FileRevision{
+		SHA:     commit.SHA,
+		Path:    path,
+		Author:  author,
+		Date:    commit.Commit.Author.Date,
+		Message: commit.Commit.Message,
+	}
+}
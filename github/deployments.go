@@ -0,0 +1,76 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// maxDeploymentsScanned limits how many deployments are searched for the last successful one
+const maxDeploymentsScanned = 20
+
+// Deployment represents a deployment of a ref together with its latest status
+type Deployment struct {
+	ID          int64     `json:"id"`
+	SHA         string    `json:"sha"`
+	Ref         string    `json:"ref"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// State and EnvironmentURL come from the latest status of the deployment
+	State          string `json:"state"`
+	EnvironmentURL string `json:"environment_url"`
+}
+
+// DeploymentStatus represents a single status reported for a deployment
+type DeploymentStatus struct {
+	State          string    `json:"state"`
+	EnvironmentURL string    `json:"environment_url"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Deployments represents the latest deployment of a ref and the latest one that succeeded
+type Deployments struct {
+	Latest     *Deployment
+	Successful *Deployment
+}
+
+// FetchDeployments fetches the latest deployment of a branch and the latest successful one,
+// which differ while a deployment is in progress or after it failed
+func FetchDeployments(ctx context.Context, owner, repo, ref, token string) (Deployments, error) {
+	q := url.Values{}
+	q.Set("ref", ref)
+	q.Set("per_page", fmt.Sprintf("%d", maxDeploymentsScanned))
+
+	var deployments []Deployment
+	if err := DefaultClient().DoJSON(ctx, "GET", fmt.Sprintf("/repos/%s/%s/deployments?%s", owner, repo, q.Encode()), token, nil, &deployments); err != nil {
+		return Deployments{}, err
+	}
+
+	var result Deployments
+	for i := range deployments {
+		deployment := deployments[i]
+
+		var statuses []DeploymentStatus
+		url := fmt.Sprintf("/repos/%s/%s/deployments/%d/statuses?per_page=1", owner, repo, deployment.ID)
+		if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &statuses); err != nil {
+			return Deployments{}, err
+		}
+		deployment.State = "pending"
+		if len(statuses) > 0 {
+			deployment.State = statuses[0].State
+			deployment.EnvironmentURL = statuses[0].EnvironmentURL
+		}
+
+		if result.Latest == nil {
+			result.Latest = &deployment
+		}
+		if deployment.State == "success" {
+			result.Successful = &deployment
+			break
+		}
+	}
+
+	return result, nil
+}
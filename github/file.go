@@ -1,16 +1,17 @@
 package github
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"unicode/utf8"
 )
 
 type GitHubAPIResponse struct {
+	SHA      string `json:"sha"`
 	Content  string `json:"content"`
 	Encoding string `json:"encoding"`
 }
@@ -20,60 +21,55 @@ type GitHubFileRequest struct {
 	RepoName  string // Repository name (e.g., "gin")
 	FilePath  string // Path to the file in the repository (e.g., "README.md")
 	Branch    string // Branch or commit reference (e.g., "main")
-	Token     string // GitHub personal access token (optional)
+	Token     string // GitHub personal access token
 }
 
 // FetchFileFromGitHub fetches a file's content from the GitHub API.
-func FetchFileFromGitHub(req GitHubFileRequest) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s?ref=%s", req.RepoOwner, req.RepoName, req.FilePath, req.Branch)
-
-	httpReq, err := http.NewRequest("GET", url, nil)
+func FetchFileFromGitHub(ctx context.Context, req GitHubFileRequest) (string, error) {
+	decodedContent, err := FetchRawFile(ctx, req)
 	if err != nil {
 		return "", err
 	}
 
-	// Add authentication header if a token is provided
-	if req.Token != "" {
-		httpReq.Header.Set("Authorization", fmt.Sprintf("token %s", req.Token))
+	// Validate the decoded content as text
+	if !isTextFile(decodedContent) {
+		return "", fmt.Errorf("file is not a valid text file")
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+	return string(decodedContent), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch file: %s", resp.Status)
-	}
+// FetchRawFile fetches the bytes of a file from the GitHub API, including binary files.
+// Files over 1MB are not inlined by the contents API and are read through the blob API instead.
+func FetchRawFile(ctx context.Context, req GitHubFileRequest) ([]byte, error) {
+	path := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", req.RepoOwner, req.RepoName, req.FilePath, url.QueryEscape(req.Branch))
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
+	body, _, err := DefaultClient().GetCached(ctx, path, req.Token)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var apiResponse GitHubAPIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", err
-	}
-
-	if apiResponse.Encoding != "base64" {
-		return "", errors.New("unsupported encoding, expected base64")
+		return nil, err
 	}
 
-	decodedContent, err := base64.StdEncoding.DecodeString(apiResponse.Content)
-	if err != nil {
-		return "", err
+	if apiResponse.Encoding == "none" && apiResponse.SHA != "" {
+		blobPath := fmt.Sprintf("/repos/%s/%s/git/blobs/%s", req.RepoOwner, req.RepoName, apiResponse.SHA)
+		body, _, err = DefaultClient().GetCached(ctx, blobPath, req.Token)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body, &apiResponse); err != nil {
+			return nil, err
+		}
 	}
 
-	// Validate the decoded content as text
-	if !isTextFile(decodedContent) {
-		return "", fmt.Errorf("file is not a valid text file")
+	if apiResponse.Encoding != "base64" {
+		return nil, errors.New("unsupported encoding, expected base64")
 	}
 
-	return string(decodedContent), nil
+	return base64.StdEncoding.DecodeString(apiResponse.Content)
 }
 
 // isTextFile checks if the given content is a text file by ensuring it contains valid UTF-8.
@@ -1,10 +1,10 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"sort"
 )
 
@@ -27,59 +27,24 @@ type File struct {
 }
 
 // FetchRepoFiles lists all files within a specified path in a GitHub repository, handling pagination.
-func FetchRepoFiles(input FetchRepoFilesInput) ([]File, error) {
+func FetchRepoFiles(ctx context.Context, input FetchRepoFilesInput) ([]File, error) {
 	// GitHub API endpoint for repository contents
-	baseURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", input.Owner, input.Repo, input.Path)
+	path := fmt.Sprintf("/repos/%s/%s/contents/%s", input.Owner, input.Repo, input.Path)
+	if input.Ref != "" {
+		path += "?ref=" + url.QueryEscape(input.Ref)
+	}
 
 	var allFiles []File
-	url := baseURL
-
-	for url != "" {
-		// Create a new HTTP request
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %v", err)
-		}
-
-		// Add authentication header if a token is provided
-		if input.Token == "" {
-			return nil, fmt.Errorf("authentication token is required")
-		}
-		req.Header.Set("Authorization", "Bearer "+input.Token)
-
-		// add the ref parameter if it is provided
-		if input.Ref != "" {
-			q := req.URL.Query()
-			q.Add("ref", input.Ref)
-			req.URL.RawQuery = q.Encode()
-		}
-
-		// Send the HTTP request
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to send request: %v", err)
-		}
-		defer resp.Body.Close()
-
-		// Check for HTTP errors
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API request failed: %s (status: %d)", string(body), resp.StatusCode)
-		}
-
-		// Parse the JSON response
+	err := getCachedPages(ctx, path, input.Token, func(body []byte) error {
 		var files []File
-		if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %v", err)
+		if err := json.Unmarshal(body, &files); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
 		}
 		allFiles = append(allFiles, files...)
-
-		// Check for pagination
-		url = ""
-		if linkHeader := resp.Header.Get("Link"); linkHeader != "" {
-			url = extractNextPageURL(linkHeader)
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	sort.Slice(allFiles, func(i, j int) bool {
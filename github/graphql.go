@@ -0,0 +1,146 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// graphQLBatchSize is the number of files requested per GraphQL query
+const graphQLBatchSize = 50
+
+// BlobContent represents the contents and latest commit of a single file
+type BlobContent struct {
+	Path             string
+	SHA              string
+	Text             string
+	LastCommitAuthor string
+	LastCommitDate   string
+}
+
+// FetchBlobsInput represents the input parameters for the FetchBlobs function
+type FetchBlobsInput struct {
+	Owner     string
+	Repo      string
+	CommitSHA string
+	Entries   []TreeEntry
+	Token     string
+}
+
+// graphQLResponse represents the envelope of a GraphQL API response
+type graphQLResponse struct {
+	Data struct {
+		Repository map[string]json.RawMessage `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// graphQLHistory represents the last commit touching a path
+type graphQLHistory struct {
+	Nodes []struct {
+		CommittedDate string `json:"committedDate"`
+		Author        struct {
+			Name string `json:"name"`
+			User *struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"author"`
+	} `json:"nodes"`
+}
+
+// FetchBlobs reads the text and last commit of many files using batched GraphQL queries
+func FetchBlobs(ctx context.Context, input FetchBlobsInput) (map[string]BlobContent, error) {
+	result := make(map[string]BlobContent, len(input.Entries))
+	for start := 0; start < len(input.Entries); start += graphQLBatchSize {
+		end := start + graphQLBatchSize
+		if end > len(input.Entries) {
+			end = len(input.Entries)
+		}
+
+		if err := fetchBlobBatch(ctx, input, input.Entries[start:end], result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// fetchBlobBatch fetches a single batch of files and adds them to the result
+func fetchBlobBatch(ctx context.Context, input FetchBlobsInput, entries []TreeEntry, result map[string]BlobContent) error {
+	var blobs, histories strings.Builder
+	for i, entry := range entries {
+		path, err := json.Marshal(entry.Path)
+		if err != nil {
+			return fmt.Errorf("failed to encode path: %v", err)
+		}
+		fmt.Fprintf(&blobs, "b%d: object(oid: %q) { ... on Blob { text } }\n", i, entry.SHA)
+		fmt.Fprintf(&histories, "h%d: history(first: 1, path: %s) { nodes { committedDate author { name user { login } } } }\n", i, path)
+	}
+
+	query := fmt.Sprintf(`query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    %s
+    commit: object(oid: %q) { ... on Commit { %s } }
+  }
+}`, blobs.String(), input.CommitSHA, histories.String())
+
+	payload := map[string]interface{}{
+		"query": query,
+		"variables": map[string]string{
+			"owner": input.Owner,
+			"name":  input.Repo,
+		},
+	}
+
+	var response graphQLResponse
+	if err := DefaultClient().DoJSON(ctx, "POST", DefaultClient().GraphQLURL(), input.Token, payload, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("GraphQL request failed: %s", response.Errors[0].Message)
+	}
+
+	commit := map[string]graphQLHistory{}
+	if raw, ok := response.Data.Repository["commit"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &commit); err != nil {
+			return fmt.Errorf("failed to parse commit history: %v", err)
+		}
+	}
+
+	for i, entry := range entries {
+		content := BlobContent{
+			Path: entry.Path,
+			SHA:  entry.SHA,
+		}
+
+		var blob struct {
+			Text *string `json:"text"`
+		}
+		if raw, ok := response.Data.Repository[fmt.Sprintf("b%d", i)]; ok {
+			if err := json.Unmarshal(raw, &blob); err != nil {
+				return fmt.Errorf("failed to parse blob %s: %v", entry.Path, err)
+			}
+		}
+		// binary blobs have no text and are skipped
+		if blob.Text == nil {
+			continue
+		}
+		content.Text = *blob.Text
+
+		if history, ok := commit[fmt.Sprintf("h%d", i)]; ok && len(history.Nodes) > 0 {
+			node := history.Nodes[0]
+			content.LastCommitDate = node.CommittedDate
+			content.LastCommitAuthor = node.Author.Name
+			if node.Author.User != nil && node.Author.User.Login != "" {
+				content.LastCommitAuthor = node.Author.User.Login
+			}
+		}
+
+		result[entry.Path] = content
+	}
+
+	return nil
+}
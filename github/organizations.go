@@ -1,10 +1,12 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 )
@@ -31,63 +33,86 @@ type FetchOrganizationsInput struct {
 }
 
 // FetchOrganizations fetches all the organizations for a given GitHub user
-func FetchOrganizations(input FetchOrganizationsInput) ([]Organization, error) {
+func FetchOrganizations(ctx context.Context, input FetchOrganizationsInput) ([]Organization, error) {
 	if input.Username == "" {
 		return nil, fmt.Errorf("username is required")
 	}
 
-	baseURL := fmt.Sprintf("https://api.github.com/users/%s/orgs", input.Username)
-
 	var allOrgs []Organization
-	url := baseURL
+	err := getCachedPages(ctx, fmt.Sprintf("/users/%s/orgs", input.Username), input.Token, func(body []byte) error {
+		var orgs []Organization
+		if err := json.Unmarshal(body, &orgs); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		allOrgs = append(allOrgs, orgs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(allOrgs, func(i, j int) bool {
+		return allOrgs[i].Login < allOrgs[j].Login
+	})
+
+	return allOrgs, nil
+}
+
+// OrganizationMember represents a member of an organization from the GitHub API response
+type OrganizationMember struct {
+	// Login is the member's username
+	Login string `json:"login"`
 
-	for url != "" {
-		// Create a new HTTP request
-		req, err := http.NewRequest("GET", url, nil)
+	// ID is the member's unique identifier
+	ID int64 `json:"id"`
+}
+
+// ListOrganizationMembers lists every member of a GitHub organization. Responses are not cached so that
+// members who left the organization are noticed on the next sync. Private members are only listed for
+// tokens of organization members with the read:org scope.
+func ListOrganizationMembers(ctx context.Context, org, token string) ([]OrganizationMember, error) {
+	if org == "" {
+		return nil, fmt.Errorf("organization is required")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("authentication token is required")
+	}
+
+	var allMembers []OrganizationMember
+	nextURL := fmt.Sprintf("/orgs/%s/members?per_page=100", url.PathEscape(org))
+	for nextURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", DefaultClient().URL(nextURL), nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %v", err)
 		}
+		req.Header.Set("Authorization", "Bearer "+token)
 
-		// Add authentication header if a token is provided
-		if input.Token == "" {
-			return nil, fmt.Errorf("authentication token is required")
-		}
-		req.Header.Set("Authorization", "Bearer "+input.Token)
-
-		// Send the HTTP request
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		resp, err := DefaultClient().Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to send request: %v", err)
 		}
-		defer resp.Body.Close()
 
-		// Check for HTTP errors
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
 			return nil, fmt.Errorf("API request failed: %s (status: %d)", string(body), resp.StatusCode)
 		}
 
-		// Parse the JSON response
-		var orgs []Organization
-		if err := json.NewDecoder(resp.Body).Decode(&orgs); err != nil {
+		var members []OrganizationMember
+		err = json.NewDecoder(resp.Body).Decode(&members)
+		resp.Body.Close()
+		if err != nil {
 			return nil, fmt.Errorf("failed to parse response: %v", err)
 		}
-		allOrgs = append(allOrgs, orgs...)
+		allMembers = append(allMembers, members...)
 
-		// Check for pagination
-		url = ""
+		nextURL = ""
 		if linkHeader := resp.Header.Get("Link"); linkHeader != "" {
-			// Parse the Link header to find the URL for the next page
-			url = extractNextPageURL(linkHeader)
+			nextURL = extractNextPageURL(linkHeader)
 		}
 	}
 
-	sort.Slice(allOrgs, func(i, j int) bool {
-		return allOrgs[i].Login < allOrgs[j].Login
-	})
-
-	return allOrgs, nil
+	return allMembers, nil
 }
 
 // extractNextPageURL parses the Link header to extract the "next" page URL.
@@ -2,12 +2,18 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
+// ErrProtectedBranch is returned when a branch update is rejected by branch protection rules
+var ErrProtectedBranch = errors.New("branch is protected")
+
 type CreatePullRequestIfNecessaryInput struct {
 	Owner      string
 	Repo       string
@@ -29,6 +35,16 @@ type CreateBranchAndUpdateFileInput struct {
 	Token      string
 }
 
+type CommitFilesInput struct {
+	Owner      string
+	Repo       string
+	Branch     string
+	BaseBranch string
+	Changes    []FileChange
+	CommitMsg  string
+	Token      string
+}
+
 type CreateCommit struct {
 	Message string   `json:"message"`
 	Parents []string `json:"parents"`
@@ -44,7 +60,23 @@ type CommitTree struct {
 }
 
 type PullRequest struct {
-	Number int64 `json:"number"`
+	Number             int64              `json:"number"`
+	Title              string             `json:"title"`
+	Body               string             `json:"body"`
+	State              string             `json:"state"`
+	HtmlURL            string             `json:"html_url"`
+	Draft              bool               `json:"draft"`
+	Merged             bool               `json:"merged"`
+	Mergeable          *bool              `json:"mergeable"`
+	MergeableState     string             `json:"mergeable_state"`
+	Head               PullRequestRef     `json:"head"`
+	Base               PullRequestRef     `json:"base"`
+	User               PullRequestUser    `json:"user"`
+	Labels             []PullRequestLabel `json:"labels"`
+	RequestedReviewers []PullRequestUser  `json:"requested_reviewers"`
+	CreatedAt          string             `json:"created_at"`
+	UpdatedAt          string             `json:"updated_at"`
+	MergedAt           string             `json:"merged_at"`
 }
 
 type Ref struct {
@@ -66,21 +98,43 @@ type Tree struct {
 }
 
 type TreeObject struct {
-	Path    string `json:"path"`
-	Mode    string `json:"mode"`
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	Path    string  `json:"path"`
+	Mode    string  `json:"mode"`
+	Type    string  `json:"type"`
+	Content *string `json:"content,omitempty"`
 }
 
-func getHeadRef(owner, repo, branch, token string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/ref/heads/%s", owner, repo, branch)
-	req, err := http.NewRequest("GET", url, nil)
+// MarshalJSON encodes a tree object without content as a deletion, which GitHub expects as a null sha
+func (t TreeObject) MarshalJSON() ([]byte, error) {
+	type treeObject TreeObject
+	if t.Content != nil {
+		return json.Marshal(treeObject(t))
+	}
+
+	return json.Marshal(struct {
+		treeObject
+		SHA *string `json:"sha"`
+	}{
+		treeObject: treeObject(t),
+	})
+}
+
+// FileChange represents a single file write or deletion within a commit
+type FileChange struct {
+	Path    string
+	Content string
+	Delete  bool
+}
+
+func getHeadRef(ctx context.Context, owner, repo, branch, token string) (string, error) {
+	url := fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", owner, repo, branch)
+	req, err := http.NewRequestWithContext(ctx, "GET", DefaultClient().URL(url), nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -93,15 +147,15 @@ func getHeadRef(owner, repo, branch, token string) (string, error) {
 	return headData.Object.SHA, nil
 }
 
-func getCommitTree(owner, repo, commitSHA, token string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/commits/%s", owner, repo, commitSHA)
-	req, err := http.NewRequest("GET", url, nil)
+func getCommitTree(ctx context.Context, owner, repo, commitSHA, token string) (string, error) {
+	url := fmt.Sprintf("/repos/%s/%s/git/commits/%s", owner, repo, commitSHA)
+	req, err := http.NewRequestWithContext(ctx, "GET", DefaultClient().URL(url), nil)
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -114,18 +168,23 @@ func getCommitTree(owner, repo, commitSHA, token string) (string, error) {
 	return commitData.Tree.SHA, nil
 }
 
-func createTree(owner, repo, baseTree, path, content, token string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees", owner, repo)
+func createTree(ctx context.Context, owner, repo, baseTree string, changes []FileChange, token string) (string, error) {
+	url := fmt.Sprintf("/repos/%s/%s/git/trees", owner, repo)
 	treeData := Tree{
 		BaseTree: baseTree,
-		Tree: []TreeObject{
-			{
-				Path:    path,
-				Mode:    "100644",
-				Type:    "blob",
-				Content: content,
-			},
-		},
+		Tree:     []TreeObject{},
+	}
+	for _, change := range changes {
+		object := TreeObject{
+			Path: change.Path,
+			Mode: "100644",
+			Type: "blob",
+		}
+		if !change.Delete {
+			content := change.Content
+			object.Content = &content
+		}
+		treeData.Tree = append(treeData.Tree, object)
 	}
 
 	body, err := json.Marshal(treeData)
@@ -133,14 +192,14 @@ func createTree(owner, repo, baseTree, path, content, token string) (string, err
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", DefaultClient().URL(url), bytes.NewBuffer(body))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -153,8 +212,8 @@ func createTree(owner, repo, baseTree, path, content, token string) (string, err
 	return newTreeData.SHA, nil
 }
 
-func createCommit(owner, repo, message, parentSHA, treeSHA, token string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/commits", owner, repo)
+func createCommit(ctx context.Context, owner, repo, message, parentSHA, treeSHA, token string) (string, error) {
+	url := fmt.Sprintf("/repos/%s/%s/git/commits", owner, repo)
 	commitData := CreateCommit{
 		Message: message,
 		Parents: []string{parentSHA},
@@ -166,14 +225,14 @@ func createCommit(owner, repo, message, parentSHA, treeSHA, token string) (strin
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", DefaultClient().URL(url), bytes.NewBuffer(body))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -188,8 +247,8 @@ func createCommit(owner, repo, message, parentSHA, treeSHA, token string) (strin
 	return commitResp.SHA, nil
 }
 
-func updateBranchRef(owner, repo, branch, sha, token string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
+func updateBranchRef(ctx context.Context, owner, repo, branch, sha, token string) error {
+	url := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
 	refData := struct {
 		SHA string `json:"sha"`
 	}{
@@ -201,24 +260,40 @@ func updateBranchRef(owner, repo, branch, sha, token string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", DefaultClient().URL(url), bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		if isProtectedBranchError(resp.StatusCode, body) {
+			return fmt.Errorf("failed to update ref %s: %w", branch, ErrProtectedBranch)
+		}
+		return fmt.Errorf("failed to update ref: %s", string(body))
+	}
+
 	return nil
 }
 
-func createRef(owner, repo, ref, sha, token string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/refs", owner, repo)
+// isProtectedBranchError checks if a failed ref update was rejected by branch protection rules
+func isProtectedBranchError(statusCode int, body []byte) bool {
+	if statusCode != http.StatusUnprocessableEntity && statusCode != http.StatusConflict && statusCode != http.StatusForbidden {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(body)), "protected branch")
+}
+
+func createRef(ctx context.Context, owner, repo, ref, sha, token string) error {
+	url := fmt.Sprintf("/repos/%s/%s/git/refs", owner, repo)
 	refData := struct {
 		Ref string `json:"ref"`
 		SHA string `json:"sha"`
@@ -232,14 +307,14 @@ func createRef(owner, repo, ref, sha, token string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", DefaultClient().URL(url), bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return err
 	}
@@ -253,47 +328,69 @@ func createRef(owner, repo, ref, sha, token string) error {
 	return nil
 }
 
-func CreateBranchAndUpdateFile(input CreateBranchAndUpdateFileInput) error {
+func CreateBranchAndUpdateFile(ctx context.Context, input CreateBranchAndUpdateFileInput) error {
+	return CommitFiles(ctx, CommitFilesInput{
+		Owner:      input.Owner,
+		Repo:       input.Repo,
+		Branch:     input.Branch,
+		BaseBranch: input.BaseBranch,
+		CommitMsg:  input.CommitMsg,
+		Token:      input.Token,
+		Changes: []FileChange{
+			{
+				Path:    input.Path,
+				Content: input.Content,
+			},
+		},
+	})
+}
+
+// CommitFiles writes and deletes a set of files in a single commit, creating the branch from the base branch if necessary
+func CommitFiles(ctx context.Context, input CommitFilesInput) error {
+	if len(input.Changes) == 0 {
+		return fmt.Errorf("at least one file change is required")
+	}
+
 	updateBranch := true
-	lastCommitSHA, err := getHeadRef(input.Owner, input.Repo, input.Branch, input.Token)
+	lastCommitSHA, err := getHeadRef(ctx, input.Owner, input.Repo, input.Branch, input.Token)
 	if err != nil || lastCommitSHA == "" {
 		updateBranch = false
-		lastCommitSHA, err = getHeadRef(input.Owner, input.Repo, input.BaseBranch, input.Token)
+		lastCommitSHA, err = getHeadRef(ctx, input.Owner, input.Repo, input.BaseBranch, input.Token)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Get tree SHA from commit
-	lastTreeSHA, err := getCommitTree(input.Owner, input.Repo, lastCommitSHA, input.Token)
+	lastTreeSHA, err := getCommitTree(ctx, input.Owner, input.Repo, lastCommitSHA, input.Token)
 	if err != nil {
 		return err
 	}
 
-	// Create new tree with file
-	newTreeSHA, err := createTree(input.Owner, input.Repo, lastTreeSHA, input.Path, input.Content, input.Token)
+	// Create new tree with the changed files
+	newTreeSHA, err := createTree(ctx, input.Owner, input.Repo, lastTreeSHA, input.Changes, input.Token)
 	if err != nil {
 		return err
 	}
 
 	// Create new commit
-	newCommitSHA, err := createCommit(input.Owner, input.Repo, input.CommitMsg, lastCommitSHA, newTreeSHA, input.Token)
+	newCommitSHA, err := createCommit(ctx, input.Owner, input.Repo, input.CommitMsg, lastCommitSHA, newTreeSHA, input.Token)
 	if err != nil {
 		return err
 	}
 
 	if updateBranch {
-		return updateBranchRef(input.Owner, input.Repo, input.Branch, newCommitSHA, input.Token)
+		return updateBranchRef(ctx, input.Owner, input.Repo, input.Branch, newCommitSHA, input.Token)
 	}
 
 	// Create new branch ref
-	return createRef(input.Owner, input.Repo, input.Branch, newCommitSHA, input.Token)
+	return createRef(ctx, input.Owner, input.Repo, input.Branch, newCommitSHA, input.Token)
 }
 
-func checkIfPullRequestExists(owner, repo, branch, baseBranch, token string) (int64, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", owner, repo)
+func checkIfPullRequestExists(ctx context.Context, owner, repo, branch, baseBranch, token string) (int64, error) {
+	url := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", DefaultClient().URL(url), nil)
 	if err != nil {
 		return 0, err
 	}
@@ -305,7 +402,7 @@ func checkIfPullRequestExists(owner, repo, branch, baseBranch, token string) (in
 
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -327,17 +424,29 @@ func checkIfPullRequestExists(owner, repo, branch, baseBranch, token string) (in
 	return 0, nil
 }
 
-func CreatePullRequestIfNecessary(input CreatePullRequestIfNecessaryInput) (int64, error) {
-	prNumber, err := checkIfPullRequestExists(input.Owner, input.Repo, input.Branch, input.BaseBranch, input.Token)
+func CreatePullRequestIfNecessary(ctx context.Context, input CreatePullRequestIfNecessaryInput) (int64, error) {
+	prNumber, err := checkIfPullRequestExists(ctx, input.Owner, input.Repo, input.Branch, input.BaseBranch, input.Token)
 	if err != nil {
 		return 0, err
 	}
 
+	// the description of an open pull request follows the latest changes on its branch
 	if prNumber != 0 {
+		if input.Body != "" {
+			err := UpdatePullRequestBody(ctx, PullRequestInput{
+				Owner:  input.Owner,
+				Repo:   input.Repo,
+				Number: prNumber,
+				Token:  input.Token,
+			}, input.Body)
+			if err != nil {
+				return 0, fmt.Errorf("failed to update PR: %w", err)
+			}
+		}
 		return prNumber, nil
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", input.Owner, input.Repo)
+	url := fmt.Sprintf("/repos/%s/%s/pulls", input.Owner, input.Repo)
 
 	// Create pull request data
 	prData := struct {
@@ -359,7 +468,7 @@ func CreatePullRequestIfNecessary(input CreatePullRequestIfNecessaryInput) (int6
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", DefaultClient().URL(url), bytes.NewBuffer(body))
 	if err != nil {
 		return 0, err
 	}
@@ -369,7 +478,7 @@ func CreatePullRequestIfNecessary(input CreatePullRequestIfNecessaryInput) (int6
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := DefaultClient().Do(req)
 	if err != nil {
 		return 0, err
 	}
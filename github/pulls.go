@@ -0,0 +1,404 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PullRequestBranchPrefixes are the branch prefixes used for pull requests opened by static-admin
+var PullRequestBranchPrefixes = []string{"create-", "update-", "restore-"}
+
+// PullRequestRef represents the head or base of a pull request
+type PullRequestRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// PullRequestUser represents a user attached to a pull request
+type PullRequestUser struct {
+	Login string `json:"login"`
+}
+
+// PullRequestLabel represents a label attached to a pull request
+type PullRequestLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// PullRequestReview represents a single review left on a pull request
+type PullRequestReview struct {
+	User  PullRequestUser `json:"user"`
+	State string          `json:"state"`
+}
+
+// CommitStatus represents a single commit status context
+type CommitStatus struct {
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// CheckRun represents a single check run on a commit
+type CheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HtmlURL    string `json:"html_url"`
+}
+
+// PullRequestChecks represents the combined status checks for a pull request head commit
+type PullRequestChecks struct {
+	State     string         `json:"state"`
+	Statuses  []CommitStatus `json:"statuses"`
+	CheckRuns []CheckRun     `json:"check_runs"`
+}
+
+// PullRequestDetails represents a pull request along with its checks, review state and mergeability
+type PullRequestDetails struct {
+	PullRequest
+	Checks      PullRequestChecks   `json:"checks"`
+	Reviews     []PullRequestReview `json:"reviews"`
+	ReviewState string              `json:"review_state"`
+}
+
+// PullRequestInput represents the input parameters for operating on a single pull request
+type PullRequestInput struct {
+	Owner  string
+	Repo   string
+	Number int64
+	Token  string
+}
+
+// ListPullRequestsInput represents the input parameters for the ListPullRequests function
+type ListPullRequestsInput struct {
+	Owner      string
+	Repo       string
+	BaseBranch string
+	State      string
+	Token      string
+}
+
+// MergePullRequestInput represents the input parameters for the MergePullRequest function
+type MergePullRequestInput struct {
+	Owner        string
+	Repo         string
+	Number       int64
+	Method       string // "merge", "squash" or "rebase"
+	DeleteBranch bool
+	Token        string
+}
+
+// IsStaticAdminBranch returns true if the branch was created by static-admin
+func IsStaticAdminBranch(branch string) bool {
+	for _, prefix := range PullRequestBranchPrefixes {
+		if strings.HasPrefix(branch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPullRequests lists the pull requests opened by static-admin against a repository
+func ListPullRequests(ctx context.Context, input ListPullRequestsInput) ([]PullRequest, error) {
+	state := input.State
+	if state == "" {
+		state = "open"
+	}
+
+	q := url.Values{}
+	q.Set("state", state)
+	q.Set("per_page", "100")
+	if input.BaseBranch != "" {
+		q.Set("base", input.BaseBranch)
+	}
+
+	var allPRs []PullRequest
+	nextURL := fmt.Sprintf("/repos/%s/%s/pulls?%s", input.Owner, input.Repo, q.Encode())
+	for nextURL != "" {
+		prs, next, err := listPullRequestsPage(ctx, nextURL, input.Token)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pr := range prs {
+			if IsStaticAdminBranch(pr.Head.Ref) {
+				allPRs = append(allPRs, pr)
+			}
+		}
+		nextURL = next
+	}
+
+	return allPRs, nil
+}
+
+// listPullRequestsPage fetches a single page of pull requests and returns the URL of the next page, if any
+func listPullRequestsPage(ctx context.Context, pageURL, token string) ([]PullRequest, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", DefaultClient().URL(pageURL), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %v", err)
+	}
+
+	if token == "" {
+		return nil, "", fmt.Errorf("authentication token is required")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := DefaultClient().Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("API request failed: %s (status: %d)", string(body), resp.StatusCode)
+	}
+
+	var prs []PullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
+		return nil, "", fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	nextURL := ""
+	if linkHeader := resp.Header.Get("Link"); linkHeader != "" {
+		nextURL = extractNextPageURL(linkHeader)
+	}
+	return prs, nextURL, nil
+}
+
+// GetPullRequest fetches a single pull request
+func GetPullRequest(ctx context.Context, input PullRequestInput) (PullRequest, error) {
+	var pr PullRequest
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d", input.Owner, input.Repo, input.Number)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, input.Token, nil, &pr); err != nil {
+		return PullRequest{}, err
+	}
+	return pr, nil
+}
+
+// FindPullRequestForBranch fetches the most recent pull request opened from a branch in any state,
+// returning false when the branch never had a pull request
+func FindPullRequestForBranch(ctx context.Context, owner, repo, branch, token string) (PullRequest, bool, error) {
+	q := url.Values{}
+	q.Set("head", owner+":"+branch)
+	q.Set("state", "all")
+	q.Set("per_page", "1")
+
+	var prs []PullRequest
+	if err := DefaultClient().DoJSON(ctx, "GET", fmt.Sprintf("/repos/%s/%s/pulls?%s", owner, repo, q.Encode()), token, nil, &prs); err != nil {
+		return PullRequest{}, false, err
+	}
+	if len(prs) == 0 {
+		return PullRequest{}, false, nil
+	}
+
+	// the list endpoint does not report merges other than through the merge time
+	pr := prs[0]
+	pr.Merged = pr.MergedAt != ""
+	return pr, true, nil
+}
+
+// GetPullRequestDetails fetches a pull request along with its status checks, reviews and mergeability
+func GetPullRequestDetails(ctx context.Context, input PullRequestInput) (PullRequestDetails, error) {
+	// the single pull request endpoint is the only one that computes mergeability
+	pr, err := GetPullRequest(ctx, input)
+	if err != nil {
+		return PullRequestDetails{}, err
+	}
+
+	details := PullRequestDetails{PullRequest: pr}
+
+	checks, err := FetchCommitChecks(ctx, input.Owner, input.Repo, pr.Head.SHA, input.Token)
+	if err != nil {
+		return PullRequestDetails{}, err
+	}
+	details.Checks = checks
+
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews?per_page=100", input.Owner, input.Repo, input.Number)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, input.Token, nil, &details.Reviews); err != nil {
+		return PullRequestDetails{}, err
+	}
+	details.ReviewState = summarizeReviews(details.Reviews)
+
+	return details, nil
+}
+
+// FetchCommitChecks fetches both the legacy commit statuses and the check runs for a commit
+func FetchCommitChecks(ctx context.Context, owner, repo, sha, token string) (PullRequestChecks, error) {
+	var combined struct {
+		State    string         `json:"state"`
+		Statuses []CommitStatus `json:"statuses"`
+	}
+	url := fmt.Sprintf("/repos/%s/%s/commits/%s/status", owner, repo, sha)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &combined); err != nil {
+		return PullRequestChecks{}, err
+	}
+
+	var checkRuns struct {
+		CheckRuns []CheckRun `json:"check_runs"`
+	}
+	url = fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs?per_page=100", owner, repo, sha)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &checkRuns); err != nil {
+		return PullRequestChecks{}, err
+	}
+
+	checks := PullRequestChecks{
+		Statuses:  combined.Statuses,
+		CheckRuns: checkRuns.CheckRuns,
+	}
+
+	// the combined state is pending for commits without any statuses, which would hide the check runs
+	if len(combined.Statuses) > 0 {
+		checks.State = combined.State
+	}
+
+	// the combined status endpoint ignores check runs, so fold them into the overall state
+	for _, run := range checks.CheckRuns {
+		if run.Status != "completed" {
+			if checks.State != "failure" {
+				checks.State = "pending"
+			}
+			continue
+		}
+
+		switch run.Conclusion {
+		case "failure", "timed_out", "cancelled", "action_required":
+			checks.State = "failure"
+		}
+	}
+
+	if len(checks.Statuses) == 0 && len(checks.CheckRuns) == 0 {
+		checks.State = ""
+	} else if checks.State == "" {
+		checks.State = "success"
+	}
+
+	return checks, nil
+}
+
+// summarizeReviews computes the overall review state from each reviewer's latest review
+func summarizeReviews(reviews []PullRequestReview) string {
+	latest := map[string]string{}
+	for _, review := range reviews {
+		// comments do not change a reviewer's approval state
+		if review.State == "COMMENTED" || review.State == "PENDING" {
+			continue
+		}
+		latest[review.User.Login] = review.State
+	}
+
+	state := "REVIEW_REQUIRED"
+	for _, reviewState := range latest {
+		switch reviewState {
+		case "CHANGES_REQUESTED":
+			return "CHANGES_REQUESTED"
+		case "APPROVED":
+			state = "APPROVED"
+		}
+	}
+	return state
+}
+
+// MergePullRequest merges a pull request, optionally deleting the head branch afterwards
+func MergePullRequest(ctx context.Context, input MergePullRequestInput) error {
+	method := input.Method
+	if method == "" {
+		method = "merge"
+	}
+	if method != "merge" && method != "squash" && method != "rebase" {
+		return fmt.Errorf("invalid merge method: %s", method)
+	}
+
+	pr, err := GetPullRequest(ctx, PullRequestInput{
+		Owner:  input.Owner,
+		Repo:   input.Repo,
+		Number: input.Number,
+		Token:  input.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", input.Owner, input.Repo, input.Number)
+	payload := struct {
+		MergeMethod string `json:"merge_method"`
+		SHA         string `json:"sha"`
+	}{
+		MergeMethod: method,
+		SHA:         pr.Head.SHA,
+	}
+	if err := DefaultClient().DoJSON(ctx, "PUT", url, input.Token, payload, nil); err != nil {
+		return fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	if input.DeleteBranch {
+		return DeleteBranch(ctx, input.Owner, input.Repo, pr.Head.Ref, input.Token)
+	}
+
+	return nil
+}
+
+// ClosePullRequest closes a pull request without merging it
+func ClosePullRequest(ctx context.Context, input PullRequestInput) error {
+	return updatePullRequestState(ctx, input, "closed")
+}
+
+// ReopenPullRequest reopens a previously closed pull request
+func ReopenPullRequest(ctx context.Context, input PullRequestInput) error {
+	return updatePullRequestState(ctx, input, "open")
+}
+
+func updatePullRequestState(ctx context.Context, input PullRequestInput, state string) error {
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d", input.Owner, input.Repo, input.Number)
+	payload := struct {
+		State string `json:"state"`
+	}{
+		State: state,
+	}
+	return DefaultClient().DoJSON(ctx, "PATCH", url, input.Token, payload, nil)
+}
+
+// UpdatePullRequestBody replaces the description of a pull request
+func UpdatePullRequestBody(ctx context.Context, input PullRequestInput, body string) error {
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d", input.Owner, input.Repo, input.Number)
+	payload := map[string]string{"body": body}
+	return DefaultClient().DoJSON(ctx, "PATCH", url, input.Token, payload, nil)
+}
+
+// RequestReviewers requests reviews from the given users on a pull request
+func RequestReviewers(ctx context.Context, input PullRequestInput, reviewers []string) error {
+	url := fmt.Sprintf("/repos/%s/%s/pulls/%d/requested_reviewers", input.Owner, input.Repo, input.Number)
+	payload := struct {
+		Reviewers []string `json:"reviewers"`
+	}{
+		Reviewers: reviewers,
+	}
+	return DefaultClient().DoJSON(ctx, "POST", url, input.Token, payload, nil)
+}
+
+// AddLabels adds labels to a pull request, creating any labels that do not yet exist
+func AddLabels(ctx context.Context, input PullRequestInput, labels []string) error {
+	// pull requests share their labels endpoint with issues
+	url := fmt.Sprintf("/repos/%s/%s/issues/%d/labels", input.Owner, input.Repo, input.Number)
+	payload := struct {
+		Labels []string `json:"labels"`
+	}{
+		Labels: labels,
+	}
+	return DefaultClient().DoJSON(ctx, "POST", url, input.Token, payload, nil)
+}
+
+// DeleteBranch deletes a branch from a repository
+func DeleteBranch(ctx context.Context, owner, repo, branch, token string) error {
+	url := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, branch)
+	if err := DefaultClient().DoJSON(ctx, "DELETE", url, token, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+	return nil
+}
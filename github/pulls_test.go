@@ -0,0 +1,118 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// useTestAPI points the package level functions at a test server for the duration of a test
+func useTestAPI(t *testing.T, handler http.Handler) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	previous := DefaultClient()
+	SetDefaultClient(NewClient(WithBaseURL(server.URL), WithMaxRetries(0)))
+	t.Cleanup(func() { SetDefaultClient(previous) })
+}
+
+func TestFetchCommitChecks(t *testing.T) {
+	success := CheckRun{Name: "build", Status: "completed", Conclusion: "success"}
+	skipped := CheckRun{Name: "deploy", Status: "completed", Conclusion: "skipped"}
+	running := CheckRun{Name: "test", Status: "in_progress"}
+	failed := CheckRun{Name: "lint", Status: "completed", Conclusion: "failure"}
+
+	tests := []struct {
+		name      string
+		state     string
+		statuses  []CommitStatus
+		checkRuns []CheckRun
+		want      string
+	}{
+		{name: "nothing reported", state: "pending", want: ""},
+		{name: "check runs only", state: "pending", checkRuns: []CheckRun{success, skipped}, want: "success"},
+		{name: "check runs only in progress", state: "pending", checkRuns: []CheckRun{success, running}, want: "pending"},
+		{name: "check runs only failed", state: "pending", checkRuns: []CheckRun{failed, running}, want: "failure"},
+		{name: "statuses only", state: "success", statuses: []CommitStatus{{Context: "ci", State: "success"}}, want: "success"},
+		{name: "pending status", state: "pending", statuses: []CommitStatus{{Context: "ci", State: "pending"}}, checkRuns: []CheckRun{success}, want: "pending"},
+		{name: "failed status", state: "failure", statuses: []CommitStatus{{Context: "ci", State: "failure"}}, checkRuns: []CheckRun{running}, want: "failure"},
+		{name: "failed check run", state: "success", statuses: []CommitStatus{{Context: "ci", State: "success"}}, checkRuns: []CheckRun{failed}, want: "failure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/owner/repo/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+				statuses := tt.statuses
+				if statuses == nil {
+					statuses = []CommitStatus{}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"state": tt.state, "statuses": statuses})
+			})
+			mux.HandleFunc("GET /repos/owner/repo/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+				checkRuns := tt.checkRuns
+				if checkRuns == nil {
+					checkRuns = []CheckRun{}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"total_count": len(checkRuns), "check_runs": checkRuns})
+			})
+			useTestAPI(t, mux)
+
+			checks, err := FetchCommitChecks(context.Background(), "owner", "repo", "abc123", "token")
+			if err != nil {
+				t.Fatalf("FetchCommitChecks() error = %v", err)
+			}
+			if checks.State != tt.want {
+				t.Errorf("State = %q, want %q", checks.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPullRequests(t *testing.T) {
+	pages := [][]string{
+		{"update-first", "feature/unrelated"},
+		{"create-second"},
+		{"restore-third"},
+	}
+
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := 0
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		if page < len(pages)-1 {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/owner/repo/pulls?page=%d>; rel="next"`, r.Host, page+1))
+		}
+
+		var prs []map[string]interface{}
+		for _, branch := range pages[page] {
+			prs = append(prs, map[string]interface{}{"head": map[string]string{"ref": branch}})
+		}
+		json.NewEncoder(w).Encode(prs)
+	})
+	useTestAPI(t, mux)
+
+	prs, err := ListPullRequests(context.Background(), ListPullRequestsInput{Owner: "owner", Repo: "repo", Token: "token"})
+	if err != nil {
+		t.Fatalf("ListPullRequests() error = %v", err)
+	}
+
+	var branches []string
+	for _, pr := range prs {
+		branches = append(branches, pr.Head.Ref)
+	}
+	want := []string{"update-first", "create-second", "restore-third"}
+	if !reflect.DeepEqual(branches, want) {
+		t.Errorf("branches = %v, want %v", branches, want)
+	}
+	if requests != len(pages) {
+		t.Errorf("requests = %d, want %d", requests, len(pages))
+	}
+}
@@ -1,10 +1,9 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 )
 
@@ -13,7 +12,7 @@ type FetchRepositoryInput struct {
 	Token         string
 }
 
-func FetchRepository(input FetchRepositoryInput) (Repository, error) {
+func FetchRepository(ctx context.Context, input FetchRepositoryInput) (Repository, error) {
 	if input.RepositoryURL == "" {
 		return Repository{}, fmt.Errorf("repository URL is required")
 	}
@@ -29,35 +28,11 @@ func FetchRepository(input FetchRepositoryInput) (Repository, error) {
 	}
 
 	// Construct GitHub API URL
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, name)
+	apiURL := fmt.Sprintf("/repos/%s/%s", owner, name)
 
-	// Create HTTP request
-	req, err := http.NewRequest("GET", apiURL, nil)
+	body, _, err := DefaultClient().GetCached(ctx, apiURL, input.Token)
 	if err != nil {
-		return Repository{}, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Add authentication header
-	req.Header.Set("Authorization", "Bearer "+input.Token)
-
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return Repository{}, fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return Repository{}, fmt.Errorf("API request failed: %s (status: %d)", string(body), resp.StatusCode)
-	}
-
-	// Read and parse response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return Repository{}, fmt.Errorf("failed to read response body: %v", err)
+		return Repository{}, err
 	}
 
 	var repo Repository
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// TreeEntry represents a single blob or subtree in a git tree
+type TreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"` // "blob", "tree" or "commit"
+	SHA  string `json:"sha"`
+	Size int64  `json:"size"`
+}
+
+// RepositoryTree represents a recursive listing of a repository at a commit
+type RepositoryTree struct {
+	CommitSHA string      `json:"commit_sha"`
+	SHA       string      `json:"sha"`
+	Truncated bool        `json:"truncated"`
+	Tree      []TreeEntry `json:"tree"`
+}
+
+// FetchTreeInput represents the input parameters for the FetchTree function
+type FetchTreeInput struct {
+	Owner string
+	Repo  string
+	Ref   string
+	Token string
+}
+
+// FetchHeadCommit returns the commit SHA a branch currently points at
+func FetchHeadCommit(ctx context.Context, owner, repo, branch, token string) (string, error) {
+	var ref Ref
+	url := fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", owner, repo, branch)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &ref); err != nil {
+		return "", err
+	}
+	if ref.Object.SHA == "" {
+		return "", fmt.Errorf("branch %s not found", branch)
+	}
+	return ref.Object.SHA, nil
+}
+
+// FetchTree lists every file in a repository at a ref using a single recursive tree request
+func FetchTree(ctx context.Context, input FetchTreeInput) (RepositoryTree, error) {
+	commitSHA, err := FetchHeadCommit(ctx, input.Owner, input.Repo, input.Ref, input.Token)
+	if err != nil {
+		return RepositoryTree{}, err
+	}
+
+	return FetchTreeAtCommit(ctx, input.Owner, input.Repo, commitSHA, input.Token)
+}
+
+// FetchTreeAtCommit lists every file in a repository at a specific commit
+func FetchTreeAtCommit(ctx context.Context, owner, repo, commitSHA, token string) (RepositoryTree, error) {
+	var tree RepositoryTree
+	url := fmt.Sprintf("/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, commitSHA)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &tree); err != nil {
+		return RepositoryTree{}, err
+	}
+
+	// very large repositories are truncated by the API, which would silently hide posts
+	if tree.Truncated {
+		return RepositoryTree{}, fmt.Errorf("repository tree for %s/%s is too large to list", owner, repo)
+	}
+
+	tree.CommitSHA = commitSHA
+	return tree, nil
+}
+
+// FetchCommitTree returns the SHA of the root tree of a commit
+func FetchCommitTree(ctx context.Context, owner, repo, commitSHA, token string) (string, error) {
+	var commit Commit
+	url := fmt.Sprintf("/repos/%s/%s/git/commits/%s", owner, repo, commitSHA)
+	if err := DefaultClient().DoJSON(ctx, "GET", url, token, nil, &commit); err != nil {
+		return "", err
+	}
+	return commit.Tree.SHA, nil
+}
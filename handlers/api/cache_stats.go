@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/github"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewCacheStatsHandler creates a new handler for the cache stats endpoint
+func NewCacheStatsHandler(config config.Config) (CacheStatsHandler, error) {
+	return CacheStatsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// CacheStatsHandler handles the cache stats request
+type CacheStatsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h CacheStatsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/cache/stats", h.handler)
+	r.OPTIONS("/cache/stats", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the hit and miss counters of each cache namespace
+func (h CacheStatsHandler) handler(c *gin.Context) {
+	if _, exists := middleware.GetUser(c); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, github.Cache().Stats())
+}
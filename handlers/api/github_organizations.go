@@ -1,10 +1,10 @@
 package api
 
 import (
-	"context"
 	"net/http"
 	"sort"
 	"static-admin/config"
+	github_api "static-admin/github"
 	"static-admin/middleware"
 
 	"static-admin/handlers/api/util"
@@ -55,12 +55,15 @@ func (h GitHubOrganizationsHandler) handler(c *gin.Context) {
 	}
 
 	// Create GitHub client with user's access token
-	ctx := context.Background()
+	ctx := github_api.DefaultClient().OAuth2Context(c.Request.Context())
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: githubAuth.AccessToken},
 	)
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
+	if baseURL, err := github_api.DefaultClient().RESTBaseURL(); err == nil {
+		client.BaseURL = baseURL
+	}
 
 	// List organizations for authenticated user
 	orgs, _, err := client.Organizations.List(ctx, "", nil)
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/github"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewGitHubRateLimitHandler creates a new handler for the GitHub rate limit endpoint
+func NewGitHubRateLimitHandler(config config.Config) (GitHubRateLimitHandler, error) {
+	return GitHubRateLimitHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// GitHubRateLimitHandler handles the GitHub rate limit request
+type GitHubRateLimitHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h GitHubRateLimitHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/github/rate-limit", h.handler)
+	r.OPTIONS("/github/rate-limit", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the current user's GitHub API quota
+func (h GitHubRateLimitHandler) handler(c *gin.Context) {
+	githubAuth, exists := middleware.GetGitHubAuth(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "GitHub authentication required",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, github.DefaultClient().Metrics(githubAuth.AccessToken))
+}
@@ -14,6 +14,7 @@ import (
 // RepositoryResponse represents a GitHub repository in the JSON response
 type RepositoryResponse struct {
 	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
 	Description   string `json:"description"`
 	Private       bool   `json:"private"`
 	Url           string `json:"url"`
@@ -45,7 +46,7 @@ func (h GitHubRepositoriesHandler) GroupRegister(r *gin.RouterGroup) {
 
 // handler handles the GET request for GitHub repositories
 func (h GitHubRepositoriesHandler) handler(c *gin.Context) {
-	user, exists := middleware.GetUser(c)
+	_, exists := middleware.GetUser(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "User not found",
@@ -65,10 +66,9 @@ func (h GitHubRepositoriesHandler) handler(c *gin.Context) {
 	var allRepos []github.Repository
 	orgName := c.Param("org")
 	if orgName == githubAuth.Login {
-		allRepos, err = github.FetchUserRepositories(github.FetchUserRepositoriesInput{
+		allRepos, err = github.FetchUserRepositories(c.Request.Context(), github.FetchUserRepositoriesInput{
 			Username: githubAuth.Login,
 			Token:    githubAuth.AccessToken,
-			UserID:   user.ID,
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -77,10 +77,9 @@ func (h GitHubRepositoriesHandler) handler(c *gin.Context) {
 			return
 		}
 	} else {
-		allRepos, err = github.FetchOrgRepositories(github.FetchOrgRepositoriesInput{
+		allRepos, err = github.FetchOrgRepositories(c.Request.Context(), github.FetchOrgRepositoriesInput{
 			Organization: orgName,
 			Token:        githubAuth.AccessToken,
-			UserID:       user.ID,
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewInvitationResponseHandler creates a new handler for accepting or declining an invitation
+func NewInvitationResponseHandler(config config.Config) (InvitationResponseHandler, error) {
+	return InvitationResponseHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// InvitationResponseHandler handles the invitation accept and decline requests
+type InvitationResponseHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h InvitationResponseHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/invitations/:invitationId/accept", h.acceptHandler)
+	r.OPTIONS("/invitations/:invitationId/accept", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/invitations/:invitationId/decline", h.declineHandler)
+	r.OPTIONS("/invitations/:invitationId/decline", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// acceptHandler handles the POST request for accepting an invitation
+func (h InvitationResponseHandler) acceptHandler(c *gin.Context) {
+	h.handler(c, true)
+}
+
+// declineHandler handles the POST request for declining an invitation
+func (h InvitationResponseHandler) declineHandler(c *gin.Context) {
+	h.handler(c, false)
+}
+
+// handler removes an invitation addressed to the current user, making them a member when it is accepted
+func (h InvitationResponseHandler) handler(c *gin.Context, accept bool) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	invitations, err := database.PendingInvitations(h.Database, user.Email, githubLogin(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch invitations",
+		})
+		return
+	}
+
+	var invitation *database.SiteInvitation
+	for i := range invitations {
+		if c.Param("invitationId") == strconv.FormatUint(uint64(invitations[i].ID), 10) {
+			invitation = &invitations[i]
+		}
+	}
+	if invitation == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invitation not found",
+		})
+		return
+	}
+
+	var member database.SiteMember
+	err = h.Database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(invitation).Error; err != nil {
+			return err
+		}
+		if !accept {
+			return nil
+		}
+
+		// someone who became a member through another invitation keeps their role
+		err := tx.Where("site_id = ? AND user_id = ?", invitation.SiteID, user.ID).First(&member).Error
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		member = database.SiteMember{SiteID: invitation.SiteID, UserID: user.ID, Role: invitation.Role}
+		return tx.Create(&member).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to respond to invitation",
+		})
+		return
+	}
+
+	if !accept {
+		c.Status(http.StatusOK)
+		return
+	}
+	c.JSON(http.StatusOK, newSiteMemberResponse(h.Database, member))
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewInvitationsHandler creates a new handler for listing the invitations of the current user
+func NewInvitationsHandler(config config.Config) (InvitationsHandler, error) {
+	return InvitationsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// InvitationsHandler handles the invitations request
+type InvitationsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h InvitationsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/invitations", h.handler)
+	r.OPTIONS("/invitations", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the invitations addressed to the current user
+func (h InvitationsHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	invitations, err := database.PendingInvitations(h.Database, user.Email, githubLogin(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch invitations",
+		})
+		return
+	}
+
+	response := make([]SiteInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = newSiteInvitationResponse(invitation)
+
+		var site database.Site
+		if err := h.Database.First(&site, invitation.SiteID).Error; err == nil {
+			parts := strings.Split(site.RepositoryURL, "/")
+			response[i].SiteName = parts[len(parts)-1]
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// githubLogin returns the GitHub login of the current user, empty if they did not connect GitHub
+func githubLogin(c *gin.Context) string {
+	githubAuth, ok := middleware.GetGitHubAuth(c)
+	if !ok || githubAuth == nil {
+		return ""
+	}
+	return githubAuth.Login
+}
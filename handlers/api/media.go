@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/repoindex"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MediaResponse represents a media file in the JSON response
+type MediaResponse struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+	Size int64  `json:"size"`
+}
+
+// NewMediaHandler creates a new handler for the media endpoint
+func NewMediaHandler(config config.Config) (MediaHandler, error) {
+	return MediaHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// MediaHandler handles the media browsing request
+type MediaHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h MediaHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/media", h.handler)
+	r.OPTIONS("/sites/:siteId/media", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for media files
+func (h MediaHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	// sites of a workspace browse the media directory of the workspace unless a directory is requested
+	directory, requested := c.GetQuery("directory")
+	if !requested && site.WorkspaceID != nil {
+		var workspace database.Workspace
+		if err := h.Database.First(&workspace, *site.WorkspaceID).Error; err == nil {
+			directory = workspace.MediaDirectory
+		}
+	}
+
+	files, err := repoindex.Media(c.Request.Context(), h.Database, repoindex.RefreshInput{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+	}, directory, c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch media from GitHub",
+		})
+		return
+	}
+
+	response := make([]MediaResponse, len(files))
+	for i, file := range files {
+		response[i] = MediaResponse{
+			Path: file.Path,
+			Name: filepath.Base(file.Path),
+			SHA:  file.SHA,
+			Size: file.Size,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -6,12 +6,16 @@ import (
 	"static-admin/blocks"
 	"static-admin/config"
 	"static-admin/database"
-	"static-admin/github"
+	"static-admin/deploystatus"
 	"static-admin/markdown"
 	"static-admin/middleware"
-	"strings"
+	"static-admin/permalink"
+	"static-admin/publisher"
+	"static-admin/repoindex"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"github.com/jxskiss/base62"
 	"gorm.io/gorm"
 )
@@ -22,6 +26,14 @@ type PostContentResponse struct {
 	Path        string                      `json:"path"`
 	Frontmatter []markdown.FrontmatterField `json:"frontmatter"`
 	Blocks      []blocks.Block              `json:"blocks"`
+
+	// Permalink is the path the post is published under and URL the absolute address it is live at,
+	// each omitted when it cannot be determined from the site configuration
+	Permalink string `json:"permalink,omitempty"`
+	URL       string `json:"url,omitempty"`
+
+	// Publication is omitted when the review and deployment state could not be read
+	Publication *PublicationResponse `json:"publication,omitempty"`
 }
 
 // NewPostHandler creates a new handler for the post content endpoint
@@ -65,14 +77,6 @@ func (h PostHandler) handler(c *gin.Context) {
 		return
 	}
 
-	githubAuth, exists := middleware.GetGitHubAuth(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "GitHub authentication required",
-		})
-		return
-	}
-
 	// Get site ID and post ID from URL
 	siteID := c.Param("siteId")
 	postID := c.Param("postId")
@@ -100,30 +104,29 @@ func (h PostHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Extract owner and repo from repository URL
-	urlParts := strings.Split(site.RepositoryURL, "/")
-	if len(urlParts) < 5 {
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Invalid repository URL",
 		})
 		return
 	}
-	owner := urlParts[len(urlParts)-2]
-	repo := urlParts[len(urlParts)-1]
 
-	branch := site.DefaultBranch
+	branch := site.Branch()
 	if branch == "" {
 		branch = "master"
 	}
 
-	// Fetch file content from GitHub
-	content, err := github.FetchFileFromGitHub(github.GitHubFileRequest{
-		RepoOwner: owner,
-		RepoName:  repo,
-		FilePath:  postPath,
-		Branch:    branch,
-		Token:     githubAuth.AccessToken,
-	})
+	// Fetch file content from the site's provider
+	content, err := publisher.FetchFile(c.Request.Context(), site, owner, repo, postPath, token, branch)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch file content",
@@ -149,10 +152,66 @@ func (h PostHandler) handler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, PostContentResponse{
+	response := PostContentResponse{
 		ID:          postID,
 		Path:        postPath,
 		Frontmatter: frontmatter,
 		Blocks:      blocks,
+	}
+
+	permalinks, err := repoindex.Permalinks(c.Request.Context(), h.Database, repoindex.RefreshInput{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
 	})
+	if err != nil {
+		glog.Errorf("Failed to read permalink configuration for site %d: %v", site.ID, err)
+		permalinks = permalink.DefaultConfig(site.Generator)
+	}
+	response.Permalink = permalinks.Path(permalink.FromFrontmatter(site.Generator, postPath, frontmatter))
+
+	var postPublication *deploystatus.Publication
+	if publication, err := h.publication(c, site, owner, repo, token, postPath); err != nil {
+		glog.Errorf("Failed to fetch publication state of %s for site %d: %v", postPath, site.ID, err)
+	} else {
+		postPublication = &publication
+	}
+	response.URL = postURL(permalinks, response.Permalink, postPublication)
+	if postPublication != nil {
+		response.Publication = newPublicationResponse(*postPublication, response.URL)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// publication computes the publication state of a post, using the indexed date of its last commit
+func (h PostHandler) publication(c *gin.Context, site database.Site, owner, repo, token, postPath string) (deploystatus.Publication, error) {
+	input := repoindex.RefreshInput{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+	}
+
+	var lastCommitDate time.Time
+	if index, err := repoindex.Get(c.Request.Context(), h.Database, input); err == nil {
+		for _, post := range index.Posts {
+			if post.Path == postPath {
+				lastCommitDate = post.LastCommitDate
+				break
+			}
+		}
+	}
+
+	publications, err := deploystatus.Posts(c.Request.Context(), h.Database, deploystatus.Input{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+	}, map[string]time.Time{postPath: lastCommitDate})
+	if err != nil {
+		return deploystatus.Publication{}, err
+	}
+	return publications[postPath], nil
 }
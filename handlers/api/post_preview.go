@@ -0,0 +1,184 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"static-admin/blocks"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/middleware"
+	"static-admin/preview"
+	"static-admin/publisher"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// PostPreviewResponse represents the JSON response for previewing a post
+type PostPreviewResponse struct {
+	Path string `json:"path"`
+	HTML string `json:"html"`
+}
+
+// NewPostPreviewHandler creates a new handler for the post preview endpoint
+func NewPostPreviewHandler(config config.Config) (PostPreviewHandler, error) {
+	return PostPreviewHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostPreviewHandler handles the post preview request
+type PostPreviewHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostPreviewHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/preview", h.handler)
+	r.OPTIONS("/sites/:siteId/preview", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for rendering unsaved post content to HTML. The page is rendered with
+// the site's preview layout if it has one, and relative asset URLs point at the site asset endpoint.
+// The HTML is not sanitized and should be displayed in a sandboxed frame.
+func (h PostPreviewHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req PostSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	// new posts have no path yet, their relative assets resolve against the posts directory
+	postPath := req.Path
+	if req.ID != "" {
+		postPath, err = fromBase62(req.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to decode post ID",
+			})
+			return
+		}
+	}
+	if postPath == "" {
+		postPath = path.Join(generator.PostsDirectory(site.Generator), "preview.md")
+	}
+
+	contentMarkdown, err := blocks.ParseBlocksToMarkdown(req.Blocks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate markdown",
+		})
+		return
+	}
+
+	page, err := preview.NewPage(postPath, req.Frontmatter, contentMarkdown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to render markdown",
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	// sites without a preview layout use the default layout
+	layout, err := publisher.FetchFile(c.Request.Context(), site, owner, repo, preview.LayoutPath, token, site.Branch())
+	if err != nil {
+		layout = ""
+	}
+
+	rendered, err := preview.Render(layout, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Failed to render preview layout: %v", err),
+		})
+		return
+	}
+
+	assetToken, err := preview.NewAssetToken(h.JWTSecret, user.ID, site.ID, postPath)
+	if err != nil {
+		glog.Errorf("Failed to sign preview asset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to sign asset URLs",
+		})
+		return
+	}
+
+	origin := requestOrigin(c)
+	rendered, err = preview.RewriteAssets(rendered, site.Generator, postPath, func(assetPath string) string {
+		return fmt.Sprintf("%s/blobs/%d/%s?token=%s", origin, site.ID, escapeAssetPath(assetPath), url.QueryEscape(assetToken))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to rewrite asset URLs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, PostPreviewResponse{
+		Path: postPath,
+		HTML: rendered,
+	})
+}
+
+// requestOrigin returns the scheme and host the request was made to, honoring proxy headers
+func requestOrigin(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// escapeAssetPath escapes each segment of an asset path for use in a URL
+func escapeAssetPath(assetPath string) string {
+	segments := strings.Split(assetPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/blocks"
+	"static-admin/config"
+	"static-admin/contentdiff"
+	"static-admin/markdown"
+	"static-admin/publisher"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RevisionContentResponse represents the content of a post at a revision
+type RevisionContentResponse struct {
+	SHA         string                      `json:"sha"`
+	Path        string                      `json:"path"`
+	Frontmatter []markdown.FrontmatterField `json:"frontmatter"`
+	Blocks      []blocks.Block              `json:"blocks"`
+}
+
+// NewPostRevisionHandler creates a new handler for the post revision content endpoint
+func NewPostRevisionHandler(config config.Config) (PostRevisionHandler, error) {
+	return PostRevisionHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostRevisionHandler handles the post revision content request
+type PostRevisionHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostRevisionHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/posts/:postId/revisions/:sha", h.handler)
+	r.OPTIONS("/sites/:siteId/posts/:postId/revisions/:sha", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the content of a post at a revision.
+// The path query parameter selects the path of the post in that revision if it was renamed since.
+func (h PostRevisionHandler) handler(c *gin.Context) {
+	target, ok := loadPostRevisionTarget(c, h.Database)
+	if !ok {
+		return
+	}
+
+	revisionPath := c.DefaultQuery("path", target.Path)
+	content, err := publisher.FetchFile(c.Request.Context(), target.Site, target.Owner, target.Repo, revisionPath, target.Token, c.Param("sha"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Revision not found",
+		})
+		return
+	}
+
+	parsed, err := contentdiff.Parse(content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to parse revision",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevisionContentResponse{
+		SHA:         c.Param("sha"),
+		Path:        revisionPath,
+		Frontmatter: parsed.Frontmatter,
+		Blocks:      parsed.Blocks,
+	})
+}
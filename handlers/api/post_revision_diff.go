@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/contentdiff"
+	"static-admin/publisher"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPostRevisionDiffHandler creates a new handler for the post revision diff endpoint
+func NewPostRevisionDiffHandler(config config.Config) (PostRevisionDiffHandler, error) {
+	return PostRevisionDiffHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostRevisionDiffHandler handles the post revision diff request
+type PostRevisionDiffHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostRevisionDiffHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/posts/:postId/diff", h.handler)
+	r.OPTIONS("/sites/:siteId/posts/:postId/diff", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the structured diff between two revisions of a post.
+// The to revision defaults to the site branch, and from_path and to_path select renamed paths.
+func (h PostRevisionDiffHandler) handler(c *gin.Context) {
+	target, ok := loadPostRevisionTarget(c, h.Database)
+	if !ok {
+		return
+	}
+
+	from := c.Query("from")
+	if from == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "From revision is required",
+		})
+		return
+	}
+	to := c.DefaultQuery("to", target.Site.Branch())
+
+	oldContent, err := publisher.FetchFile(c.Request.Context(), target.Site, target.Owner, target.Repo, c.DefaultQuery("from_path", target.Path), target.Token, from)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "From revision not found",
+		})
+		return
+	}
+
+	newContent, err := publisher.FetchFile(c.Request.Context(), target.Site, target.Owner, target.Repo, c.DefaultQuery("to_path", target.Path), target.Token, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "To revision not found",
+		})
+		return
+	}
+
+	diff, err := contentdiff.CompareContent(oldContent, newContent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to parse revisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/publisher"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RevisionRestoreResponse represents the JSON response for restoring a revision of a post
+type RevisionRestoreResponse struct {
+	Message string `json:"message"`
+	PRURL   string `json:"pr_url"`
+	Branch  string `json:"branch"`
+}
+
+// NewPostRevisionRestoreHandler creates a new handler for the post revision restore endpoint
+func NewPostRevisionRestoreHandler(config config.Config) (PostRevisionRestoreHandler, error) {
+	return PostRevisionRestoreHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostRevisionRestoreHandler handles the post revision restore request
+type PostRevisionRestoreHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostRevisionRestoreHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/posts/:postId/revisions/:sha/restore", h.handler)
+	r.OPTIONS("/sites/:siteId/posts/:postId/revisions/:sha/restore", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for restoring a revision of a post through a new pull request.
+// The path query parameter selects the path of the post in that revision if it was renamed since.
+func (h PostRevisionRestoreHandler) handler(c *gin.Context) {
+	target, ok := loadPostRevisionTarget(c, h.Database)
+	if !ok || !requirePostPath(c, h.Database, target.Site, target.Path) {
+		return
+	}
+
+	result, err := publisher.Restore(c.Request.Context(), publisher.RestoreInput{
+		Site:         target.Site,
+		Owner:        target.Owner,
+		Repo:         target.Repo,
+		Token:        target.Token,
+		Path:         target.Path,
+		SHA:          c.Param("sha"),
+		RevisionPath: c.DefaultQuery("path", target.Path),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevisionRestoreResponse{
+		Message: result.Message,
+		PRURL:   result.PRURL,
+		Branch:  result.Branch,
+	})
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/publisher"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RevisionResponse represents a revision of a post in the JSON response
+type RevisionResponse struct {
+	SHA     string `json:"sha"`
+	Path    string `json:"path"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Message string `json:"message"`
+	Branch  string `json:"branch"`
+	Merged  bool   `json:"merged"`
+}
+
+// postRevisionTarget is the site and post a revision request operates on
+type postRevisionTarget struct {
+	Site  database.Site
+	Owner string
+	Repo  string
+	Token string
+	Path  string
+}
+
+// loadPostRevisionTarget resolves the site and post of a revision request, responding with an error on failure
+func loadPostRevisionTarget(c *gin.Context, db *gorm.DB) (postRevisionTarget, bool) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return postRevisionTarget{}, false
+	}
+
+	postPath, err := fromBase62(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to decode post ID",
+		})
+		return postRevisionTarget{}, false
+	}
+
+	site, err := database.GetSite(db, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return postRevisionTarget{}, false
+	}
+
+	token, err := database.GetProviderToken(db, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return postRevisionTarget{}, false
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return postRevisionTarget{}, false
+	}
+
+	return postRevisionTarget{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+		Path:  postPath,
+	}, true
+}
+
+// NewPostRevisionsHandler creates a new handler for the post revisions endpoint
+func NewPostRevisionsHandler(config config.Config) (PostRevisionsHandler, error) {
+	return PostRevisionsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostRevisionsHandler handles the post revisions request
+type PostRevisionsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostRevisionsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/posts/:postId/revisions", h.handler)
+	r.OPTIONS("/sites/:siteId/posts/:postId/revisions", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the revisions of a post
+func (h PostRevisionsHandler) handler(c *gin.Context) {
+	target, ok := loadPostRevisionTarget(c, h.Database)
+	if !ok {
+		return
+	}
+
+	revisions, err := publisher.History(c.Request.Context(), target.Site, target.Owner, target.Repo, target.Token, target.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch post history",
+		})
+		return
+	}
+
+	response := make([]RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		response[i] = RevisionResponse{
+			SHA:     revision.SHA,
+			Path:    revision.Path,
+			Author:  revision.Author,
+			Date:    revision.Date.Format(time.RFC3339),
+			Message: revision.Message,
+			Branch:  revision.Branch,
+			Merged:  revision.Merged,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,19 +1,29 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"path/filepath"
 	"static-admin/blocks"
 	"static-admin/config"
 	"static-admin/database"
-	"static-admin/github"
+	"static-admin/deploystatus"
+	"static-admin/generator"
 	"static-admin/markdown"
 	"static-admin/middleware"
+	"static-admin/permalink"
+	"static-admin/provider"
+	"static-admin/publisher"
+	"static-admin/repoindex"
+	"static-admin/schema"
+	"static-admin/siteconfig"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
@@ -24,6 +34,13 @@ type PostSaveRequest struct {
 	Path        string                      `json:"path"`
 	Frontmatter []markdown.FrontmatterField `json:"frontmatter"`
 	Blocks      []blocks.Block              `json:"blocks"`
+
+	// Collection is the directory new posts are created in, the posts directory of the generator by default
+	Collection string `json:"collection,omitempty"`
+
+	// TemplateID is the site template the post is created from and validated against, instead of the
+	// default template of its collection
+	TemplateID *uint `json:"template_id,omitempty"`
 }
 
 // PostSaveResponse represents the JSON response for saving a post's content
@@ -33,6 +50,12 @@ type PostSaveResponse struct {
 	Path     string          `json:"path"`
 	Markdown string          `json:"markdown"`
 	PRURL    string          `json:"pr_url"`
+	Mode     string          `json:"mode"`
+	Branch   string          `json:"branch"`
+
+	// Permalink and URL are where the post will be published, omitted when they cannot be determined
+	Permalink string `json:"permalink,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 // NewPostSaveHandler creates a new handler for saving post content
@@ -65,14 +88,6 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 		return
 	}
 
-	githubAuth, exists := middleware.GetGitHubAuth(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "GitHub authentication required",
-		})
-		return
-	}
-
 	// Get site ID and post ID from URL
 	siteID := c.Param("siteId")
 
@@ -85,6 +100,14 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 		return
 	}
 
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Parse request body
 	var req PostSaveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -95,7 +118,33 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 		return
 	}
 
+	// existing posts are identified by the URL, which access to the post is checked against
+	if c.Request.Method == "POST" {
+		req.ID = c.Param("postId")
+	}
+
+	var templateFields []database.TemplateField
 	if c.Request.Method == "PUT" {
+		collection, ok := database.NormalizeRootPath(req.Collection)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid collection",
+			})
+			return
+		}
+		if collection == "" {
+			collection = generator.PostsDirectory(site.Generator)
+		}
+
+		// fields of the template missing from the request are filled in with the template's values
+		templateFields, ok = h.templateFields(c, site.ID, collection, req.TemplateID)
+		if !ok {
+			return
+		}
+		if templateFields != nil {
+			req.Frontmatter = applyTemplate(templateFields, req.Frontmatter, time.Now())
+		}
+
 		// generate the path as a slug version of the post date and title
 		// make sure to get the date from the frontmatter
 		var date time.Time
@@ -125,7 +174,7 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 			return
 		}
 
-		req.Path = fmt.Sprintf("%s-%s.md", date.Format("2006-01-02"), slug.Make(title))
+		req.Path = fmt.Sprintf("%s/%s-%s.md", collection, date.Format("2006-01-02"), slug.Make(title))
 		req.ID = toBase62(req.Path)
 	}
 
@@ -137,18 +186,95 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 		})
 		return
 	}
+	if !requirePostPath(c, h.Database, site, path) {
+		return
+	}
+
+	// filter out the permalink field if the value is empty, a set permalink overrides the site's pattern
+	fields := []markdown.FrontmatterField{}
+	for _, field := range req.Frontmatter {
+		if field.Name == "permalink" && field.Type == "string" && field.StringValue == "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
 
-	// filter out the permalink field if the value is empty
-	fields := req.Frontmatter
-	permalinkIndex := -1
-	for i, field := range fields {
-		if field.Name == "permalink" {
-			permalinkIndex = i
-			break
+	// existing posts are validated against the template of the directory they are in
+	if c.Request.Method == "POST" {
+		var found bool
+		if templateFields, found = h.templateFields(c, site.ID, filepath.Dir(path), req.TemplateID); !found {
+			return
 		}
 	}
-	if permalinkIndex != -1 {
-		fields = append(fields[:permalinkIndex], fields[permalinkIndex+1:]...)
+	if fieldErrors := schema.Validate(templateFields, fields); len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Frontmatter does not match the template",
+			"fields": fieldErrors,
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	// the permalink of the post is checked against the other posts of the site before committing,
+	// saves are not blocked when the site configuration or index cannot be read
+	indexInput := repoindex.RefreshInput{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+	}
+	index, err := repoindex.Get(c.Request.Context(), h.Database, indexInput)
+	if err != nil {
+		glog.Errorf("Failed to fetch posts of site %d to check permalinks: %v", site.ID, err)
+		index = nil
+	}
+	permalinks, err := repoindex.Permalinks(c.Request.Context(), h.Database, indexInput)
+	if err != nil {
+		glog.Errorf("Failed to read permalink configuration for site %d: %v", site.ID, err)
+		permalinks = permalink.DefaultConfig(site.Generator)
+	}
+	postPermalink := permalinks.Path(permalink.FromFrontmatter(site.Generator, path, fields))
+	if index != nil && postPermalink != "" {
+		if other, found := index.FindPermalink(permalinks, postPermalink, path); found {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":     fmt.Sprintf("The permalink %s is already used by %s", postPermalink, other.Path),
+				"permalink": postPermalink,
+				"path":      other.Path,
+			})
+			return
+		}
+	}
+
+	// authors cannot create a post over an existing one they did not create
+	if member, ok := middleware.GetSiteMember(c); ok && member.OwnPostsOnly() && c.Request.Method == "PUT" {
+		if index == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch posts",
+			})
+			return
+		}
+		isAuthor, err := database.IsPostAuthor(h.Database, site.ID, path, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch post author",
+			})
+			return
+		}
+		for _, post := range index.Posts {
+			if post.Path == path && !isAuthor {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Authors can only edit posts they created",
+				})
+				return
+			}
+		}
 	}
 
 	// Generate markdown content
@@ -170,61 +296,174 @@ func (h PostSaveHandler) handler(c *gin.Context) {
 
 	fullMarkdown := frontmatterYaml + "\n" + contentMarkdown + "\n"
 
-	// Extract owner and repo from repository URL
-	urlParts := strings.Split(site.RepositoryURL, "/")
-	if len(urlParts) < 5 {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid repository URL",
-		})
-		return
-	}
-	owner := urlParts[len(urlParts)-2]
-	repo := urlParts[len(urlParts)-1]
-
 	fileName := filepath.Base(path)
-	branchName := fmt.Sprintf("update-%s", slug.Make(fileName))
+	branchName := publisher.ReviewBranch(site, path)
 	if c.Request.Method == "PUT" {
-		branchName = fmt.Sprintf("create-%s", slug.Make(fileName))
-	}
-
-	err = github.CreateBranchAndUpdateFile(github.CreateBranchAndUpdateFileInput{
-		Owner:      owner,
-		Repo:       repo,
-		Path:       path,
-		Content:    fullMarkdown,
-		Branch:     branchName,
-		BaseBranch: site.DefaultBranch,
-		CommitMsg:  fmt.Sprintf("Update %s", path),
-		Token:      githubAuth.AccessToken,
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to create branch and update file: %v", err),
-		})
-		return
+		branchName = publisher.CreateBranch(site, path)
 	}
 
-	prNumber, err := github.CreatePullRequestIfNecessary(github.CreatePullRequestIfNecessaryInput{
-		Owner:      owner,
-		Repo:       repo,
-		Branch:     branchName,
-		BaseBranch: site.DefaultBranch,
-		Title:      fmt.Sprintf("Update %s", fileName),
-		Body:       fmt.Sprintf("Updates content for %s", path),
-		Token:      githubAuth.AccessToken,
+	result, err := publisher.Commit(c.Request.Context(), publisher.CommitInput{
+		Site:         site,
+		Owner:        owner,
+		Repo:         repo,
+		Token:        token,
+		ReviewBranch: branchName,
+		Title:        fmt.Sprintf("Update %s", fileName),
+		Body:         publisher.ReviewBody(c.Request.Context(), site, owner, repo, token, path, fmt.Sprintf("Updates content for `%s`.", site.RepositoryPath(path)), fullMarkdown),
+		CommitMsg:    fmt.Sprintf("Update %s", path),
+		Changes: []provider.FileChange{
+			{
+				Path:    path,
+				Content: fullMarkdown,
+			},
+		},
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to create pull request: %v", err),
+			"error": err.Error(),
 		})
 		return
 	}
 
+	if c.Request.Method == "PUT" {
+		if err := database.RecordPostAuthor(h.Database, site.ID, path, user.ID); err != nil {
+			glog.Errorf("Failed to record author of %s for site %d: %v", path, site.ID, err)
+		}
+	}
+
+	if result.Branch == site.Branch() {
+		repoindex.Invalidate(h.Database, site.ID)
+	}
+	deploystatus.Invalidate(h.Database, site.ID, result.Branch)
+
 	c.JSON(http.StatusOK, PostSaveResponse{
-		Message:  "Created pull request for changes",
+		Message:  result.Message,
 		Request:  req,
 		Path:     path,
 		Markdown: fullMarkdown,
-		PRURL:    fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, repo, prNumber),
+		PRURL:    result.PRURL,
+		Mode:     result.Mode,
+		Branch:   result.Branch,
+
+		Permalink: postPermalink,
+		URL:       permalink.Resolve(permalinks.BaseURL, postPermalink),
 	})
 }
+
+// requirePostPath writes an error response and returns false if the member may not write a post at a path
+// relative to the site root. Members who cannot manage the site are limited to its post directories and the
+// collections of its templates, so that they cannot commit CI workflows or the admin configuration of the site.
+func requirePostPath(c *gin.Context, db *gorm.DB, site database.Site, postPath string) bool {
+	member, ok := middleware.GetSiteMember(c)
+	if !ok || member.Can(database.PermissionManage) {
+		return true
+	}
+
+	postPath = path.Clean(postPath)
+	repositoryPath := site.RepositoryPath(postPath)
+	reserved := postPath == ".." || strings.HasPrefix(postPath, "../") || strings.HasPrefix(postPath, "/") ||
+		strings.HasPrefix(postPath, siteconfig.Directory+"/") || strings.HasPrefix(repositoryPath, ".github/")
+	if !reserved {
+		var collections []string
+		if err := database.SiteTemplates(db, site.ID).Model(&database.Template{}).Where("collection <> ''").Pluck("collection", &collections).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch collections",
+			})
+			return false
+		}
+
+		for _, directory := range append(generator.PostDirectories(site.Generator), collections...) {
+			if strings.HasPrefix(postPath, directory+"/") {
+				return true
+			}
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": "Your role on this site only allows changing posts in its post directories",
+	})
+	return false
+}
+
+// templateFields returns the fields of the template of a post, nil when no template applies. The
+// error response is sent when the template cannot be fetched.
+func (h PostSaveHandler) templateFields(c *gin.Context, siteID uint, collection string, templateID *uint) ([]database.TemplateField, bool) {
+	template, err := h.postTemplate(siteID, collection, templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Template not found",
+		})
+		return nil, false
+	}
+	if template == nil {
+		return nil, true
+	}
+
+	templateFields, err := database.GetTemplateFields(h.Database, template.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch template fields",
+		})
+		return nil, false
+	}
+	return templateFields, true
+}
+
+// postTemplate returns the template of a post, either the requested template of the site or the
+// default template of the collection. It returns nil when no template applies.
+func (h PostSaveHandler) postTemplate(siteID uint, collection string, templateID *uint) (*database.Template, error) {
+	if templateID == nil {
+		template, err := database.DefaultTemplate(h.Database, siteID, collection)
+		if err != nil {
+			glog.Errorf("Failed to fetch default template for site %d: %v", siteID, err)
+			return nil, nil
+		}
+		return template, nil
+	}
+
+	var template database.Template
+	if err := database.SiteTemplates(h.Database, siteID).First(&template, *templateID).Error; err != nil {
+		return nil, errors.New("template not found")
+	}
+	return &template, nil
+}
+
+// applyTemplate pre-populates the frontmatter of a new post with the fields of its template. Fields sent
+// by the client take precedence and keep their values, date fields without a value default to now.
+func applyTemplate(templateFields []database.TemplateField, frontmatter []markdown.FrontmatterField, now time.Time) []markdown.FrontmatterField {
+	requested := make(map[string]markdown.FrontmatterField, len(frontmatter))
+	for _, field := range frontmatter {
+		requested[field.Name] = field
+	}
+
+	fields := make([]markdown.FrontmatterField, 0, len(templateFields)+len(frontmatter))
+	seen := make(map[string]bool, len(templateFields))
+	for _, templateField := range templateFields {
+		seen[templateField.Name] = true
+		if field, ok := requested[templateField.Name]; ok {
+			fields = append(fields, field)
+			continue
+		}
+
+		field := markdown.FrontmatterField{
+			Name:             templateField.Name,
+			Type:             templateField.Type,
+			StringValue:      templateField.StringValue,
+			BoolValue:        templateField.BoolValue,
+			NumberValue:      templateField.NumberValue,
+			DateTimeValue:    templateField.DateTimeValue,
+			StringSliceValue: templateField.StringSliceValue,
+		}
+		if field.Type == "dateTime" && field.DateTimeValue.IsZero() {
+			field.DateTimeValue = now
+		}
+		fields = append(fields, field)
+	}
+
+	for _, field := range frontmatter {
+		if !seen[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"static-admin/database"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRequirePostPath(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&database.Template{}); err != nil {
+		t.Fatal(err)
+	}
+	siteID := uint(1)
+	if err := db.Create(&database.Template{SiteID: &siteID, Name: "Recipe", Collection: "_recipes"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	jekyll := database.Site{Model: gorm.Model{ID: 1}, Generator: "jekyll"}
+	monorepo := database.Site{Model: gorm.Model{ID: 2}, Generator: "hugo", RootPath: "docs"}
+
+	tests := []struct {
+		name string
+		role string
+		site database.Site
+		path string
+		want bool
+	}{
+		{name: "post", role: database.RoleEditor, site: jekyll, path: "_posts/2024-01-01-hello.md", want: true},
+		{name: "draft", role: database.RoleAuthor, site: jekyll, path: "_drafts/hello.md", want: true},
+		{name: "template collection", role: database.RoleEditor, site: jekyll, path: "_recipes/soup.md", want: true},
+		{name: "hugo post below the root path", role: database.RoleEditor, site: monorepo, path: "content/posts/hello.md", want: true},
+		{name: "workflow", role: database.RoleEditor, site: jekyll, path: ".github/workflows/deploy.yml"},
+		{name: "admin configuration", role: database.RoleEditor, site: jekyll, path: ".static-admin/config.yml"},
+		{name: "admin templates", role: database.RoleAuthor, site: jekyll, path: ".static-admin/templates/post.yml"},
+		{name: "other directory", role: database.RoleEditor, site: jekyll, path: "_layouts/default.html"},
+		{name: "root file", role: database.RoleEditor, site: jekyll, path: "_config.yml"},
+		{name: "posts directory itself", role: database.RoleEditor, site: jekyll, path: "_posts"},
+		{name: "leaving the posts directory", role: database.RoleEditor, site: jekyll, path: "_posts/../.github/workflows/deploy.yml"},
+		{name: "leaving the site", role: database.RoleEditor, site: monorepo, path: "../.github/workflows/deploy.yml"},
+		{name: "collection of another site", role: database.RoleEditor, site: monorepo, path: "_recipes/soup.md"},
+		{name: "admin workflow", role: database.RoleAdmin, site: jekyll, path: ".github/workflows/deploy.yml", want: true},
+		{name: "owner configuration", role: database.RoleOwner, site: jekyll, path: ".static-admin/config.yml", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("site_member", database.SiteMember{SiteID: tt.site.ID, Role: tt.role})
+
+			if got := requirePostPath(c, db, tt.site, tt.path); got != tt.want {
+				t.Fatalf("requirePostPath(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/deploystatus"
+	"static-admin/middleware"
+	"static-admin/publisher"
+	"static-admin/repoindex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// PostStatusResponse represents the JSON response for publishing or unpublishing a post
+type PostStatusResponse struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	PRURL   string `json:"pr_url"`
+	Mode    string `json:"mode"`
+	Branch  string `json:"branch"`
+}
+
+// NewPostStatusHandler creates a new handler for publishing and unpublishing posts
+func NewPostStatusHandler(config config.Config) (PostStatusHandler, error) {
+	return PostStatusHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PostStatusHandler handles the publish and unpublish requests
+type PostStatusHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PostStatusHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/posts/:postId/publish", h.publishHandler)
+	r.POST("/sites/:siteId/posts/:postId/unpublish", h.unpublishHandler)
+	r.OPTIONS("/sites/:siteId/posts/:postId/publish", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.OPTIONS("/sites/:siteId/posts/:postId/unpublish", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// publishHandler handles the POST request for publishing a draft
+func (h PostStatusHandler) publishHandler(c *gin.Context) {
+	h.handler(c, false)
+}
+
+// unpublishHandler handles the POST request for turning a post back into a draft
+func (h PostStatusHandler) unpublishHandler(c *gin.Context) {
+	h.handler(c, true)
+}
+
+// handler moves a post and rewrites its frontmatter in a single commit
+func (h PostStatusHandler) handler(c *gin.Context, draft bool) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	postPath, err := fromBase62(c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to decode post ID",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !requirePostPath(c, h.Database, site, postPath) {
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := publisher.SetPostStatus(c.Request.Context(), publisher.SetPostStatusInput{
+		Site:  site,
+		Token: token,
+		Path:  postPath,
+		Draft: draft,
+	})
+	if errors.Is(err, publisher.ErrAlreadyDraft) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Post is already a draft",
+		})
+		return
+	}
+	if errors.Is(err, publisher.ErrAlreadyPublished) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Post is already published",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := database.MovePostAuthor(h.Database, site.ID, postPath, result.Path); err != nil {
+		glog.Errorf("Failed to move author of %s for site %d: %v", postPath, site.ID, err)
+	}
+
+	if result.Branch == site.Branch() {
+		repoindex.Invalidate(h.Database, site.ID)
+	}
+	deploystatus.Invalidate(h.Database, site.ID, result.Branch)
+
+	c.JSON(http.StatusOK, PostStatusResponse{
+		Message: result.Message,
+		ID:      toBase62(result.Path),
+		Path:    result.Path,
+		Status:  result.Status,
+		PRURL:   result.PRURL,
+		Mode:    result.Mode,
+		Branch:  result.Branch,
+	})
+}
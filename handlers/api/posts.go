@@ -4,13 +4,18 @@ import (
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
-	"static-admin/github"
+	"static-admin/deploystatus"
+	"static-admin/generator"
 	"static-admin/middleware"
-	"strings"
+	"static-admin/permalink"
+	"static-admin/repoindex"
+	"strconv"
+	"time"
 
 	"github.com/jxskiss/base62"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"gorm.io/gorm"
 )
 
@@ -21,8 +26,96 @@ func toBase62(path string) string {
 
 // PostResponse represents a post in the JSON response
 type PostResponse struct {
-	ID   string `json:"id"`
-	Path string `json:"path"`
+	ID               string   `json:"id"`
+	Path             string   `json:"path"`
+	Title            string   `json:"title"`
+	Date             string   `json:"date,omitempty"`
+	Tags             []string `json:"tags"`
+	Categories       []string `json:"categories"`
+	Status           string   `json:"status"`
+	Draft            bool     `json:"draft"`
+	LastCommitAuthor string   `json:"last_commit_author,omitempty"`
+	LastCommitDate   string   `json:"last_commit_date,omitempty"`
+
+	// Permalink is the path the post is published under and URL the absolute address it is live at,
+	// each omitted when it cannot be determined from the site configuration
+	Permalink string `json:"permalink,omitempty"`
+	URL       string `json:"url,omitempty"`
+
+	// Publication is omitted when the review and deployment state could not be read
+	Publication *PublicationResponse `json:"publication,omitempty"`
+}
+
+// PublicationResponse represents the review, check and deployment state of a post in the JSON response
+type PublicationResponse struct {
+	State                 string `json:"state"`
+	Branch                string `json:"branch"`
+	CommitSHA             string `json:"commit_sha"`
+	PullRequestNumber     int64  `json:"pull_request_number,omitempty"`
+	PullRequestURL        string `json:"pull_request_url,omitempty"`
+	ChecksState           string `json:"checks_state,omitempty"`
+	DeploymentState       string `json:"deployment_state,omitempty"`
+	DeploymentEnvironment string `json:"deployment_environment,omitempty"`
+	LiveURL               string `json:"live_url,omitempty"`
+}
+
+// newPublicationResponse converts the publication state of a post to its response format,
+// reporting the live URL of the post once it is deployed
+func newPublicationResponse(publication deploystatus.Publication, postURL string) *PublicationResponse {
+	response := &PublicationResponse{
+		State:                 publication.State,
+		Branch:                publication.Branch,
+		CommitSHA:             publication.CommitSHA,
+		PullRequestNumber:     publication.PullRequestNumber,
+		PullRequestURL:        publication.PullRequestURL,
+		ChecksState:           publication.ChecksState,
+		DeploymentState:       publication.DeploymentState,
+		DeploymentEnvironment: publication.DeploymentEnvironment,
+	}
+	if publication.State == deploystatus.StateDeployed {
+		response.LiveURL = postURL
+	}
+	return response
+}
+
+// postURL resolves the permalink of a post against the site URL from the generator configuration or,
+// when the configuration does not set one, against the URL the site was last deployed to
+func postURL(permalinks permalink.Config, postPermalink string, publication *deploystatus.Publication) string {
+	baseURL := permalinks.BaseURL
+	if baseURL == "" && publication != nil {
+		baseURL = publication.DeploymentURL
+	}
+	return permalink.Resolve(baseURL, postPermalink)
+}
+
+// maxPostsLimit is the largest page size that can be requested
+const maxPostsLimit = 200
+
+// newPostResponse converts an indexed post to its response format
+func newPostResponse(post repoindex.Post, now time.Time) PostResponse {
+	response := PostResponse{
+		ID:               toBase62(post.Path),
+		Path:             post.Path,
+		Title:            post.Title,
+		Tags:             post.Tags,
+		Categories:       post.Categories,
+		Status:           post.Status(now),
+		Draft:            post.Draft,
+		LastCommitAuthor: post.LastCommitAuthor,
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	if response.Categories == nil {
+		response.Categories = []string{}
+	}
+	if !post.Date.IsZero() {
+		response.Date = post.Date.Format(time.RFC3339)
+	}
+	if !post.LastCommitDate.IsZero() {
+		response.LastCommitDate = post.LastCommitDate.Format(time.RFC3339)
+	}
+	return response
 }
 
 // NewPostsHandler creates a new handler for the posts endpoint
@@ -57,14 +150,6 @@ func (h PostsHandler) handler(c *gin.Context) {
 		return
 	}
 
-	githubAuth, exists := middleware.GetGitHubAuth(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "GitHub authentication required",
-		})
-		return
-	}
-
 	// Get site ID from URL
 	siteID := c.Param("siteId")
 	if siteID == "" {
@@ -83,27 +168,70 @@ func (h PostsHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Extract owner and repo from repository URL
-	// Format: https://github.com/owner/repo
-	urlParts := strings.Split(site.RepositoryURL, "/")
-	if len(urlParts) < 5 {
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Invalid repository URL",
 		})
 		return
 	}
-	owner := urlParts[len(urlParts)-2]
-	repo := urlParts[len(urlParts)-1]
 
-	// Fetch files from GitHub
-	files, err := github.FetchRepoFiles(github.FetchRepoFilesInput{
+	query := repoindex.Query{
+		Status:   c.Query("status"),
+		Tag:      c.Query("tag"),
+		Category: c.Query("category"),
+		Search:   c.Query("q"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+		Cursor:   c.Query("cursor"),
+		Now:      time.Now(),
+	}
+	if query.Status != "" && !generator.ValidStatus(query.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Status must be one of draft, scheduled or published",
+		})
+		return
+	}
+	if !repoindex.ValidSort(query.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Sort must be one of date, title, path or updated",
+		})
+		return
+	}
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Order must be one of asc or desc",
+		})
+		return
+	}
+
+	// results are only paginated when a limit is requested
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Limit must be a positive number",
+			})
+			return
+		}
+		query.Limit = min(value, maxPostsLimit)
+	}
+
+	indexInput := repoindex.RefreshInput{
+		Site:  site,
 		Owner: owner,
 		Repo:  repo,
-		Path:  "_posts",
-		Token: githubAuth.AccessToken,
-		Ref:   site.DefaultBranch,
-		Type:  "file",
-	})
+		Token: token,
+	}
+	index, err := repoindex.Get(c.Request.Context(), h.Database, indexInput)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch posts from GitHub",
@@ -111,14 +239,55 @@ func (h PostsHandler) handler(c *gin.Context) {
 		return
 	}
 
+	// posts fall back to the generator's default permalinks when the site configuration cannot be read
+	permalinks, err := repoindex.Permalinks(c.Request.Context(), h.Database, indexInput)
+	if err != nil {
+		glog.Errorf("Failed to read permalink configuration for site %d: %v", site.ID, err)
+		permalinks = permalink.DefaultConfig(site.Generator)
+	}
+
+	posts, nextCursor, err := index.Find(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cursor",
+		})
+		return
+	}
+
+	lastCommitDates := make(map[string]time.Time, len(posts))
+	for _, post := range posts {
+		lastCommitDates[post.Path] = post.LastCommitDate
+	}
+
+	// posts are listed even when the review and deployment state cannot be read
+	publications, err := deploystatus.Posts(c.Request.Context(), h.Database, deploystatus.Input{
+		Site:  site,
+		Owner: owner,
+		Repo:  repo,
+		Token: token,
+	}, lastCommitDates)
+	if err != nil {
+		glog.Errorf("Failed to fetch publication state for site %d: %v", site.ID, err)
+	}
+
 	// Convert to response format
-	response := make([]PostResponse, len(files))
-	for i, file := range files {
-		response[i] = PostResponse{
-			ID:   toBase62(file.Path),
-			Path: file.Path,
+	response := make([]PostResponse, len(posts))
+	for i, post := range posts {
+		response[i] = newPostResponse(post, query.Now)
+		response[i].Permalink = permalinks.Path(post.PermalinkPost())
+
+		var postPublication *deploystatus.Publication
+		if publication, ok := publications[post.Path]; ok {
+			postPublication = &publication
+		}
+		response[i].URL = postURL(permalinks, response[i].Permalink, postPublication)
+		if postPublication != nil {
+			response[i].Publication = newPublicationResponse(*postPublication, response[i].URL)
 		}
 	}
 
+	if nextCursor != "" {
+		c.Header("X-Next-Cursor", nextCursor)
+	}
 	c.JSON(http.StatusOK, response)
 }
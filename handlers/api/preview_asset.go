@@ -0,0 +1,115 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/preview"
+	"static-admin/publisher"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPreviewAssetHandler creates a new handler for the preview asset endpoint
+func NewPreviewAssetHandler(config config.Config) (PreviewAssetHandler, error) {
+	return PreviewAssetHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PreviewAssetHandler serves the files of a site referenced by a rendered preview
+type PreviewAssetHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group. Browsers load preview assets
+// without the Authorization header, so the handler is authenticated by the token query parameter.
+func (h PreviewAssetHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/blobs/:siteId/*path", h.handler)
+}
+
+// handler handles the GET request for a file of a site, preferring pending changes on the
+// review branches of the previewed post over the site branch
+func (h PreviewAssetHandler) handler(c *gin.Context) {
+	claims, err := preview.ParseAssetToken(h.JWTSecret, c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid token",
+		})
+		return
+	}
+
+	if strconv.FormatUint(uint64(claims.SiteID), 10) != c.Param("siteId") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Token does not grant access to this site",
+		})
+		return
+	}
+
+	var user database.User
+	if err := h.Database.First(&user, claims.AssetUser).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	assetPath := strings.TrimPrefix(path.Clean(c.Param("path")), "/")
+	if assetPath == "" || assetPath == "." {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "File not found",
+		})
+		return
+	}
+
+	data, err := publisher.FetchRaw(c.Request.Context(), site, owner, repo, assetPath, token,
+		publisher.ReviewBranch(site, claims.PostPath), publisher.CreateBranch(site, claims.PostPath), site.Branch())
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "File not found",
+		})
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(assetPath))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	// repository files are untrusted, keep any HTML or SVG from running scripts on this origin
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, contentType, data)
+}
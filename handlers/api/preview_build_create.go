@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/previewbuild"
+	"static-admin/publisher"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PreviewBuildCreateRequest represents the JSON request for building a preview of a branch
+type PreviewBuildCreateRequest struct {
+	// Branch defaults to the review branch of PostID, or to the site branch without a post
+	Branch string `json:"branch"`
+	PostID string `json:"post_id"`
+}
+
+// NewPreviewBuildCreateHandler creates a new handler for starting preview builds
+func NewPreviewBuildCreateHandler(config config.Config) (PreviewBuildCreateHandler, error) {
+	return PreviewBuildCreateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PreviewBuildCreateHandler handles the preview build creation request
+type PreviewBuildCreateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PreviewBuildCreateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/builds", h.handler)
+}
+
+// handler handles the POST request for building the head commit of a branch. The build of a
+// commit is reused, so requesting a build of an unchanged branch returns the existing build.
+func (h PreviewBuildCreateHandler) handler(c *gin.Context) {
+	builder := previewbuild.Default()
+	if builder == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Preview builds are not enabled",
+		})
+		return
+	}
+
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req PreviewBuildCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	branch := req.Branch
+	if branch == "" && req.PostID != "" {
+		postPath, err := fromBase62(req.PostID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to decode post ID",
+			})
+			return
+		}
+		branch = publisher.ReviewBranch(site, postPath)
+	}
+	if branch == "" {
+		branch = site.Branch()
+	}
+
+	build, err := builder.Request(c.Request.Context(), previewbuild.RequestInput{
+		Site:   site,
+		UserID: user.ID,
+		Token:  token,
+		Branch: branch,
+		Origin: requestOrigin(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newPreviewBuildResponse(c, build))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/previewbuild"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPreviewBuildLogHandler creates a new handler for the preview build log endpoint
+func NewPreviewBuildLogHandler(config config.Config) (PreviewBuildLogHandler, error) {
+	return PreviewBuildLogHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PreviewBuildLogHandler handles the preview build log request
+type PreviewBuildLogHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PreviewBuildLogHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/builds/:buildId/log", h.handler)
+	r.OPTIONS("/sites/:siteId/builds/:buildId/log", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the output of a build. The output of running builds is
+// streamed as plain text until the build finishes, finished builds return their stored output.
+func (h PreviewBuildLogHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var build database.PreviewBuild
+	if err := h.Database.Where("id = ? AND site_id = ?", c.Param("buildId"), site.ID).First(&build).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Build not found",
+		})
+		return
+	}
+
+	var buildLog *previewbuild.Log
+	if builder := previewbuild.Default(); builder != nil {
+		buildLog, _ = builder.Log(build.ID)
+	}
+	if buildLog == nil {
+		c.String(http.StatusOK, build.Log)
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("X-Content-Type-Options", "nosniff")
+	offset := 0
+	c.Stream(func(w io.Writer) bool {
+		data, done, changed := buildLog.Next(offset)
+		if len(data) > 0 {
+			offset += len(data)
+			_, _ = w.Write(data)
+			return true
+		}
+		if done {
+			return false
+		}
+
+		select {
+		case <-changed:
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/previewbuild"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PreviewBuildResponse represents a preview build in the JSON response
+type PreviewBuildResponse struct {
+	ID         uint   `json:"id"`
+	SiteID     uint   `json:"site_id"`
+	Branch     string `json:"branch"`
+	CommitSHA  string `json:"commit_sha"`
+	Status     string `json:"status"`
+	Error      string `json:"error"`
+	PreviewURL string `json:"preview_url"`
+	CreatedAt  string `json:"created_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// newPreviewBuildResponse converts a preview build to its response format
+func newPreviewBuildResponse(c *gin.Context, build database.PreviewBuild) PreviewBuildResponse {
+	response := PreviewBuildResponse{
+		ID:         build.ID,
+		SiteID:     build.SiteID,
+		Branch:     build.Branch,
+		CommitSHA:  build.CommitSHA,
+		Status:     build.Status,
+		Error:      build.Error,
+		PreviewURL: previewbuild.PreviewURL(requestOrigin(c), build.PreviewKey),
+		CreatedAt:  build.CreatedAt.Format(time.RFC3339),
+	}
+	if build.StartedAt != nil {
+		response.StartedAt = build.StartedAt.Format(time.RFC3339)
+	}
+	if build.FinishedAt != nil {
+		response.FinishedAt = build.FinishedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// NewPreviewBuildsHandler creates a new handler for the preview builds endpoint
+func NewPreviewBuildsHandler(config config.Config) (PreviewBuildsHandler, error) {
+	return PreviewBuildsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PreviewBuildsHandler handles the preview builds request
+type PreviewBuildsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PreviewBuildsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/builds", h.handler)
+	r.OPTIONS("/sites/:siteId/builds", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the preview builds of a site, newest first
+func (h PreviewBuildsHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	query := h.Database.Where("site_id = ?", site.ID)
+	if branch := c.Query("branch"); branch != "" {
+		query = query.Where("branch = ?", branch)
+	}
+
+	var builds []database.PreviewBuild
+	if err := query.Order("created_at DESC").Limit(50).Find(&builds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch builds",
+		})
+		return
+	}
+
+	response := make([]PreviewBuildResponse, len(builds))
+	for i, build := range builds {
+		response[i] = newPreviewBuildResponse(c, build)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
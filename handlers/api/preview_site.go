@@ -0,0 +1,82 @@
+package api
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"static-admin/config"
+	"static-admin/previewbuild"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPreviewSiteHandler creates a new handler serving preview builds
+func NewPreviewSiteHandler(config config.Config) (PreviewSiteHandler, error) {
+	return PreviewSiteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PreviewSiteHandler serves the output of the latest successful build of a branch
+type PreviewSiteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group. Preview sites are opened directly
+// in the browser, so the unguessable preview key in the URL stands in for authentication.
+func (h PreviewSiteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/previews/:previewKey/*path", h.handler)
+	r.HEAD("/previews/:previewKey/*path", h.handler)
+}
+
+// handler handles the GET request for a file of a preview site
+func (h PreviewSiteHandler) handler(c *gin.Context) {
+	builder := previewbuild.Default()
+	if builder == nil {
+		c.String(http.StatusNotFound, "Preview builds are not enabled")
+		return
+	}
+
+	build, err := builder.Latest(c.Param("previewKey"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Preview not found")
+		return
+	}
+
+	// preview sites run the site's own scripts, which must not reach the admin's storage on this origin
+	c.Header("Content-Security-Policy", "sandbox allow-scripts allow-forms allow-popups allow-modals")
+	c.Header("Cache-Control", "no-cache")
+
+	// files are opened through the output directory, so that links written by the generator cannot reach
+	// other files of the server
+	root, err := os.OpenRoot(builder.OutputDir(build))
+	if err != nil {
+		c.String(http.StatusNotFound, "Preview not found")
+		return
+	}
+	defer root.Close()
+
+	request := c.Request.Clone(c.Request.Context())
+	request.URL.Path = c.Param("path")
+	http.FileServerFS(noSymlinkFS{root.FS()}).ServeHTTP(c.Writer, request)
+}
+
+// noSymlinkFS serves the files of a directory, refusing symbolic links even when they stay inside it
+type noSymlinkFS struct {
+	fs.FS
+}
+
+// Open opens a file that is not a symbolic link
+func (f noSymlinkFS) Open(name string) (fs.File, error) {
+	info, err := fs.Lstat(f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.FS.Open(name)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/middleware"
+	"static-admin/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderAuthURLResponse represents the JSON response containing a provider's auth URL
+type ProviderAuthURLResponse struct {
+	URL string `json:"url"`
+}
+
+// NewProviderAuthURLHandler creates a new handler for the provider auth URL endpoint
+func NewProviderAuthURLHandler(config config.Config) (ProviderAuthURLHandler, error) {
+	return ProviderAuthURLHandler{
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// ProviderAuthURLHandler handles the provider auth URL request
+type ProviderAuthURLHandler struct {
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h ProviderAuthURLHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/providers", h.list)
+	r.GET("/providers/:provider/auth-url", h.handler)
+	r.OPTIONS("/providers", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.OPTIONS("/providers/:provider/auth-url", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// list handles the GET request for the configured providers
+func (h ProviderAuthURLHandler) list(c *gin.Context) {
+	c.JSON(http.StatusOK, provider.Names())
+}
+
+// handler handles the GET request for a provider's auth URL
+func (h ProviderAuthURLHandler) handler(c *gin.Context) {
+	jwtToken, ok := middleware.GetJWTToken(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing JWT token",
+		})
+		return
+	}
+
+	p, err := provider.Get(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Provider is not configured",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProviderAuthURLResponse{
+		URL: p.AuthCodeURL(jwtToken),
+	})
+}
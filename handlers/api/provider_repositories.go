@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/provider"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewProviderRepositoriesHandler creates a new handler for the provider repositories endpoint
+func NewProviderRepositoriesHandler(config config.Config) (ProviderRepositoriesHandler, error) {
+	return ProviderRepositoriesHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// ProviderRepositoriesHandler handles the provider repositories request
+type ProviderRepositoriesHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h ProviderRepositoriesHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/providers/:provider/repositories", h.handler)
+	r.OPTIONS("/providers/:provider/repositories", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the repositories the user can access on a provider
+func (h ProviderRepositoriesHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	p, err := provider.Get(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Provider is not configured",
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, p.Name())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	repos, err := p.ListRepositories(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch repositories",
+		})
+		return
+	}
+
+	if repos == nil {
+		repos = []provider.Repository{}
+	}
+	c.JSON(http.StatusOK, repos)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/deploystatus"
+	"static-admin/github"
+	"static-admin/repoindex"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PullRequestMergeRequest represents the JSON request for merging a pull request
+type PullRequestMergeRequest struct {
+	Method       string `json:"method"`
+	DeleteBranch bool   `json:"delete_branch"`
+}
+
+// NewPullRequestMergeHandler creates a new handler for merging pull requests
+func NewPullRequestMergeHandler(config config.Config) (PullRequestMergeHandler, error) {
+	return PullRequestMergeHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PullRequestMergeHandler handles the pull request merge request
+type PullRequestMergeHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PullRequestMergeHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/pull-requests/:number/merge", h.handler)
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/merge", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for merging a pull request
+func (h PullRequestMergeHandler) handler(c *gin.Context) {
+	var req PullRequestMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if req.Method == "" {
+		req.Method = "merge"
+	}
+	if req.Method != "merge" && req.Method != "squash" && req.Method != "rebase" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Merge method must be one of merge, squash or rebase",
+		})
+		return
+	}
+
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	err := github.MergePullRequest(c.Request.Context(), github.MergePullRequestInput{
+		Owner:        input.Owner,
+		Repo:         input.Repo,
+		Number:       input.Number,
+		Method:       req.Method,
+		DeleteBranch: req.DeleteBranch,
+		Token:        input.Token,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to merge pull request: %v", err),
+		})
+		return
+	}
+
+	// the site was already verified by sitePullRequestInput, so the id is known to be valid
+	if siteID, err := strconv.ParseUint(c.Param("siteId"), 10, 64); err == nil {
+		repoindex.Invalidate(h.Database, uint(siteID))
+		deploystatus.InvalidateSite(h.Database, uint(siteID))
+	}
+
+	c.Status(http.StatusOK)
+}
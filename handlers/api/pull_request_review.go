@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/github"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PullRequestReviewersRequest represents the JSON request for requesting reviewers on a pull request
+type PullRequestReviewersRequest struct {
+	Reviewers []string `json:"reviewers" binding:"required,min=1"`
+}
+
+// PullRequestLabelsRequest represents the JSON request for adding labels to a pull request
+type PullRequestLabelsRequest struct {
+	Labels []string `json:"labels" binding:"required,min=1"`
+}
+
+// NewPullRequestReviewHandler creates a new handler for requesting reviewers and labelling pull requests
+func NewPullRequestReviewHandler(config config.Config) (PullRequestReviewHandler, error) {
+	return PullRequestReviewHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PullRequestReviewHandler handles the pull request reviewer and label requests
+type PullRequestReviewHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PullRequestReviewHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/pull-requests/:number/reviewers", h.reviewersHandler)
+	r.POST("/sites/:siteId/pull-requests/:number/labels", h.labelsHandler)
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/reviewers", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/labels", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// reviewersHandler handles the POST request for requesting reviewers
+func (h PullRequestReviewHandler) reviewersHandler(c *gin.Context) {
+	var req PullRequestReviewersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	if err := github.RequestReviewers(c.Request.Context(), input, req.Reviewers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to request reviewers: %v", err),
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// labelsHandler handles the POST request for adding labels
+func (h PullRequestReviewHandler) labelsHandler(c *gin.Context) {
+	var req PullRequestLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	if err := github.AddLabels(c.Request.Context(), input, req.Labels); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to add labels: %v", err),
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
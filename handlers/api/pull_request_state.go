@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/github"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPullRequestStateHandler creates a new handler for closing and reopening pull requests
+func NewPullRequestStateHandler(config config.Config) (PullRequestStateHandler, error) {
+	return PullRequestStateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PullRequestStateHandler handles the pull request close and reopen requests
+type PullRequestStateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PullRequestStateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/pull-requests/:number/close", h.closeHandler)
+	r.POST("/sites/:siteId/pull-requests/:number/reopen", h.reopenHandler)
+	r.DELETE("/sites/:siteId/pull-requests/:number/branch", h.deleteBranchHandler)
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/close", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/reopen", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.OPTIONS("/sites/:siteId/pull-requests/:number/branch", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// closeHandler handles the POST request for closing a pull request
+func (h PullRequestStateHandler) closeHandler(c *gin.Context) {
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	if err := github.ClosePullRequest(c.Request.Context(), input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to close pull request: %v", err),
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// reopenHandler handles the POST request for reopening a pull request
+func (h PullRequestStateHandler) reopenHandler(c *gin.Context) {
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	if err := github.ReopenPullRequest(c.Request.Context(), input); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to reopen pull request: %v", err),
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// deleteBranchHandler handles the DELETE request for removing a merged pull request's branch
+func (h PullRequestStateHandler) deleteBranchHandler(c *gin.Context) {
+	input, ok := sitePullRequestInput(c, h.Database)
+	if !ok {
+		return
+	}
+
+	pr, err := github.GetPullRequest(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch pull request from GitHub",
+		})
+		return
+	}
+
+	if !pr.Merged {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only branches of merged pull requests can be deleted",
+		})
+		return
+	}
+
+	if err := github.DeleteBranch(c.Request.Context(), input.Owner, input.Repo, pr.Head.Ref, input.Token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to delete branch: %v", err),
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/github"
+	"static-admin/middleware"
+	"static-admin/provider"
+	"static-admin/publisher"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewPullRequestsHandler creates a new handler for the pull requests endpoint
+func NewPullRequestsHandler(config config.Config) (PullRequestsHandler, error) {
+	return PullRequestsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// PullRequestsHandler handles the pull requests request
+type PullRequestsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h PullRequestsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/pull-requests", h.handler)
+	r.OPTIONS("/sites/:siteId/pull-requests", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// sitePullRequestInput resolves the site and pull request number for a pull request route,
+// writing an error response and returning false if either cannot be resolved
+func sitePullRequestInput(c *gin.Context, db *gorm.DB) (github.PullRequestInput, bool) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return github.PullRequestInput{}, false
+	}
+
+	number, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid pull request number",
+		})
+		return github.PullRequestInput{}, false
+	}
+
+	site, err := database.GetSite(db, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return github.PullRequestInput{}, false
+	}
+	if !requireGitHubSite(c, site) {
+		return github.PullRequestInput{}, false
+	}
+
+	githubAuth, exists := middleware.GetGitHubAuth(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "GitHub authentication required",
+		})
+		return github.PullRequestInput{}, false
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return github.PullRequestInput{}, false
+	}
+
+	input := github.PullRequestInput{
+		Owner:  owner,
+		Repo:   repo,
+		Number: number,
+		Token:  githubAuth.AccessToken,
+	}
+
+	// only allow operating on pull requests opened by static-admin
+	pr, err := github.GetPullRequest(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Pull request not found",
+		})
+		return github.PullRequestInput{}, false
+	}
+	if !github.IsStaticAdminBranch(pr.Head.Ref) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Pull request was not created by static-admin",
+		})
+		return github.PullRequestInput{}, false
+	}
+	if !publisher.OwnsBranch(site, pr.Head.Ref) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Pull request belongs to another site",
+		})
+		return github.PullRequestInput{}, false
+	}
+
+	return input, true
+}
+
+// requireGitHubSite writes an error response and returns false if the site is not hosted on GitHub,
+// as pull requests are managed through the GitHub API
+func requireGitHubSite(c *gin.Context, site database.Site) bool {
+	if site.Provider == "" || site.Provider == provider.GitHub {
+		return true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": fmt.Sprintf("Pull requests are not supported on %s", site.Provider),
+	})
+	return false
+}
+
+// handler handles the GET request for pull requests
+func (h PullRequestsHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	// Fetch site details
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !requireGitHubSite(c, site) {
+		return
+	}
+
+	githubAuth, exists := middleware.GetGitHubAuth(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "GitHub authentication required",
+		})
+		return
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	prs, err := github.ListPullRequests(c.Request.Context(), github.ListPullRequestsInput{
+		Owner:      owner,
+		Repo:       repo,
+		BaseBranch: site.Branch(),
+		State:      c.DefaultQuery("state", "open"),
+		Token:      githubAuth.AccessToken,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch pull requests from GitHub",
+		})
+		return
+	}
+
+	// repositories holding several sites only list the pull requests of this site
+	response := make([]github.PullRequestDetails, 0, len(prs))
+	for _, pr := range prs {
+		if !publisher.OwnsBranch(site, pr.Head.Ref) {
+			continue
+		}
+
+		details, err := github.GetPullRequestDetails(c.Request.Context(), github.PullRequestInput{
+			Owner:  owner,
+			Repo:   repo,
+			Number: pr.Number,
+			Token:  githubAuth.AccessToken,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch pull request details from GitHub",
+			})
+			return
+		}
+		response = append(response, details)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
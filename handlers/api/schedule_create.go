@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/github"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScheduleCreateRequest represents the JSON request for scheduling a post's publication
+type ScheduleCreateRequest struct {
+	PostID    string    `json:"post_id" binding:"required"`
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+
+	// PullRequestNumber schedules a merge of the given pull request instead of publishing a draft
+	PullRequestNumber int64  `json:"pull_request_number"`
+	MergeMethod       string `json:"merge_method"`
+}
+
+// NewScheduleCreateHandler creates a new handler for scheduling publications
+func NewScheduleCreateHandler(config config.Config) (ScheduleCreateHandler, error) {
+	return ScheduleCreateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// ScheduleCreateHandler handles the schedule creation request
+type ScheduleCreateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h ScheduleCreateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.PUT("/sites/:siteId/schedules", h.handler)
+}
+
+// handler handles the PUT request for schedule creation
+func (h ScheduleCreateHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req ScheduleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if req.MergeMethod == "" {
+		req.MergeMethod = "merge"
+	}
+	if req.MergeMethod != "merge" && req.MergeMethod != "squash" && req.MergeMethod != "rebase" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Merge method must be one of merge, squash or rebase",
+		})
+		return
+	}
+
+	if !req.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Publish time must be in the future",
+		})
+		return
+	}
+
+	postPath, err := fromBase62(req.PostID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to decode post ID",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	action := database.ScheduleActionPublishDraft
+	if req.PullRequestNumber != 0 {
+		action = database.ScheduleActionMergePullRequest
+
+		// the scheduler can only merge pull requests on GitHub
+		if !requireGitHubSite(c, site) {
+			return
+		}
+
+		githubAuth, exists := middleware.GetGitHubAuth(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "GitHub authentication required",
+			})
+			return
+		}
+
+		owner, repo, ok := site.OwnerAndRepo()
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Invalid repository URL",
+			})
+			return
+		}
+
+		pr, err := github.GetPullRequest(c.Request.Context(), github.PullRequestInput{
+			Owner:  owner,
+			Repo:   repo,
+			Number: req.PullRequestNumber,
+			Token:  githubAuth.AccessToken,
+		})
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Pull request not found",
+			})
+			return
+		}
+		if !github.IsStaticAdminBranch(pr.Head.Ref) || pr.State != "open" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Only open pull requests created by static-admin can be scheduled",
+			})
+			return
+		}
+	}
+
+	schedule := database.ScheduledPublication{
+		SiteID:            site.ID,
+		UserID:            user.ID,
+		PostPath:          postPath,
+		Action:            action,
+		PullRequestNumber: req.PullRequestNumber,
+		MergeMethod:       req.MergeMethod,
+		PublishAt:         req.PublishAt,
+		Status:            database.ScheduleStatusPending,
+		NextAttemptAt:     req.PublishAt,
+	}
+	if err := h.Database.Create(&schedule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newScheduleResponse(schedule))
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewScheduleDeleteHandler creates a new handler for cancelling scheduled publications
+func NewScheduleDeleteHandler(config config.Config) (ScheduleDeleteHandler, error) {
+	return ScheduleDeleteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// ScheduleDeleteHandler handles the schedule deletion request
+type ScheduleDeleteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h ScheduleDeleteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.DELETE("/sites/:siteId/schedules/:scheduleId", h.handler)
+}
+
+// handler handles the DELETE request for cancelling a scheduled publication
+func (h ScheduleDeleteHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// running jobs cannot be cancelled part way through
+	result := h.Database.
+		Where("id = ? AND site_id = ? AND status <> ?", c.Param("scheduleId"), site.ID, database.ScheduleStatusRunning).
+		Delete(&database.ScheduledPublication{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete schedule",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Schedule not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
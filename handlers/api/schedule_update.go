@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScheduleUpdateRequest represents the JSON request for rescheduling a publication
+type ScheduleUpdateRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+// NewScheduleUpdateHandler creates a new handler for rescheduling publications
+func NewScheduleUpdateHandler(config config.Config) (ScheduleUpdateHandler, error) {
+	return ScheduleUpdateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// ScheduleUpdateHandler handles the schedule update request
+type ScheduleUpdateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h ScheduleUpdateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/schedules/:scheduleId", h.handler)
+	r.OPTIONS("/sites/:siteId/schedules/:scheduleId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for rescheduling a publication
+func (h ScheduleUpdateHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req ScheduleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if !req.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Publish time must be in the future",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var schedule database.ScheduledPublication
+	if err := h.Database.Where("id = ? AND site_id = ?", c.Param("scheduleId"), site.ID).First(&schedule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Schedule not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch schedule",
+		})
+		return
+	}
+
+	// failed jobs can be rescheduled, which also resets their retries
+	if schedule.Status != database.ScheduleStatusPending && schedule.Status != database.ScheduleStatusFailed {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only pending or failed schedules can be changed",
+		})
+		return
+	}
+
+	schedule.PublishAt = req.PublishAt
+	schedule.NextAttemptAt = req.PublishAt
+	schedule.Status = database.ScheduleStatusPending
+	schedule.Attempts = 0
+	schedule.LastError = ""
+
+	// guard against the scheduler claiming the job while it is being changed
+	result := h.Database.Model(&schedule).
+		Where("status IN ?", []string{database.ScheduleStatusPending, database.ScheduleStatusFailed}).
+		Select("PublishAt", "NextAttemptAt", "Status", "Attempts", "LastError").
+		Updates(&schedule)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update schedule",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Schedule is already running",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newScheduleResponse(schedule))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ScheduleResponse represents a scheduled publication in the JSON response
+type ScheduleResponse struct {
+	ID                uint   `json:"id"`
+	SiteID            uint   `json:"site_id"`
+	PostID            string `json:"post_id"`
+	PostPath          string `json:"post_path"`
+	Action            string `json:"action"`
+	PullRequestNumber int64  `json:"pull_request_number"`
+	MergeMethod       string `json:"merge_method"`
+	PublishAt         string `json:"publish_at"`
+	Status            string `json:"status"`
+	NextAttemptAt     string `json:"next_attempt_at"`
+	Attempts          int    `json:"attempts"`
+	LastError         string `json:"last_error"`
+}
+
+// newScheduleResponse converts a scheduled publication to its response format
+func newScheduleResponse(schedule database.ScheduledPublication) ScheduleResponse {
+	return ScheduleResponse{
+		ID:                schedule.ID,
+		SiteID:            schedule.SiteID,
+		PostID:            toBase62(schedule.PostPath),
+		PostPath:          schedule.PostPath,
+		Action:            schedule.Action,
+		PullRequestNumber: schedule.PullRequestNumber,
+		MergeMethod:       schedule.MergeMethod,
+		PublishAt:         schedule.PublishAt.Format(time.RFC3339),
+		Status:            schedule.Status,
+		NextAttemptAt:     schedule.NextAttemptAt.Format(time.RFC3339),
+		Attempts:          schedule.Attempts,
+		LastError:         schedule.LastError,
+	}
+}
+
+// NewSchedulesHandler creates a new handler for the scheduled publications endpoint
+func NewSchedulesHandler(config config.Config) (SchedulesHandler, error) {
+	return SchedulesHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SchedulesHandler handles the scheduled publications request
+type SchedulesHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SchedulesHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/schedules", h.handler)
+	r.OPTIONS("/sites/:siteId/schedules", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for scheduled publications
+func (h SchedulesHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	query := h.Database.Where("site_id = ?", site.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var schedules []database.ScheduledPublication
+	if err := query.Order("publish_at").Find(&schedules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch schedules",
+		})
+		return
+	}
+
+	response := make([]ScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		response[i] = newScheduleResponse(schedule)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/siteconfig"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// NewSiteConfigSyncHandler creates a new handler for reloading the configuration of a site from its repository
+func NewSiteConfigSyncHandler(config config.Config) (SiteConfigSyncHandler, error) {
+	return SiteConfigSyncHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteConfigSyncHandler handles the site configuration sync request
+type SiteConfigSyncHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteConfigSyncHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/config/sync", h.handler)
+	r.OPTIONS("/sites/:siteId/config/sync", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for reloading the settings and templates of a site from the
+// .static-admin directory of its repository, for sites whose webhook is not set up
+func (h SiteConfigSyncHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	err = siteconfig.Sync(c.Request.Context(), h.Database, &site, token)
+	var validationErrors siteconfig.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid .static-admin configuration",
+			"errors": validationErrors,
+		})
+		return
+	}
+	if err != nil {
+		glog.Errorf("Failed to sync the configuration of site %d: %v", site.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to sync site configuration",
+		})
+		return
+	}
+
+	var templates []database.Template
+	if err := database.SiteTemplates(h.Database, site.ID).Order("name").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch templates",
+		})
+		return
+	}
+
+	response := make([]TemplateResponse, len(templates))
+	for i, template := range templates {
+		response[i] = TemplateResponse{
+			ID:          template.ID,
+			Name:        template.Name,
+			WorkspaceID: template.WorkspaceID,
+			SiteID:      template.SiteID,
+			Collection:  template.Collection,
+			Default:     template.Default,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
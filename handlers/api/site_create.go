@@ -1,23 +1,37 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
-	"static-admin/github"
+	"static-admin/generator"
 	"static-admin/middleware"
+	"static-admin/provider"
+	"static-admin/siteconfig"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"gorm.io/gorm"
 )
 
 // SiteCreateRequest represents the JSON data for creating a new site
 type SiteCreateRequest struct {
-	RepositoryURL string `json:"repository_url" binding:"required"`
-	Description   string `json:"description"`
-	DefaultBranch string `json:"default_branch"`
-	Private       bool   `json:"private"`
+	RepositoryURL  string `json:"repository_url" binding:"required"`
+	Provider       string `json:"provider"`
+	Description    string `json:"description"`
+	DefaultBranch  string `json:"default_branch"`
+	Private        bool   `json:"private"`
+	PublishingMode string `json:"publishing_mode"`
+	StagingBranch  string `json:"staging_branch"`
+	Generator      string `json:"generator"`
+	RootPath       string `json:"root_path"`
+	WorkingBranch  string `json:"working_branch"`
+
+	// WorkspaceID registers the site in a workspace the user manages
+	WorkspaceID *uint `json:"workspace_id"`
 }
 
 // NewSiteCreateHandler creates a new handler for the site creation endpoint
@@ -49,33 +63,90 @@ func (h SiteCreateHandler) handler(c *gin.Context) {
 		return
 	}
 
-	githubAuth, exists := middleware.GetGitHubAuth(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Invalid token",
+	var req SiteCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
 		})
 		return
 	}
 
-	var req SiteCreateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if req.Provider == "" {
+		req.Provider = provider.GitHub
+	}
+	if !provider.Valid(req.Provider) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format",
+			"error": "Provider must be one of github, gitlab, gitea or local",
+		})
+		return
+	}
+
+	p, err := provider.Get(req.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Provider is not configured",
 		})
 		return
 	}
 
 	// Validate URL format
-	if !strings.HasPrefix(req.RepositoryURL, "https://github.com/") {
+	if !strings.HasPrefix(req.RepositoryURL, p.WebURL()+"/") {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid GitHub repository URL",
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, req.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
 		})
 		return
 	}
 
+	publishingMode, stagingBranch, ok := normalizePublishingMode(req.PublishingMode, req.StagingBranch)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Publishing mode must be one of pull_request, direct or staging",
+		})
+		return
+	}
+
+	rootPath, ok := database.NormalizeRootPath(req.RootPath)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid root path",
+		})
+		return
+	}
+
+	if req.Generator != "" && !generator.Valid(req.Generator) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Generator must be one of jekyll, hugo, eleventy or other",
+		})
+		return
+	}
+
+	if req.WorkspaceID != nil {
+		member, err := database.GetWorkspaceMember(h.Database, fmt.Sprint(*req.WorkspaceID), user.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Workspace not found",
+			})
+			return
+		}
+		if !member.CanManage() {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Your role in this workspace does not allow this action",
+			})
+			return
+		}
+	}
+
 	// Check if site already exists
 	var existingSite database.Site
-	result := h.Database.Where("user_id = ? AND repository_url = ?", user.ID, req.RepositoryURL).First(&existingSite)
+	result := h.Database.Where("user_id = ? AND repository_url = ? AND root_path = ?", user.ID, req.RepositoryURL, rootPath).First(&existingSite)
 	if result.Error == nil {
 		c.JSON(http.StatusConflict, gin.H{
 			"error": "Site already exists",
@@ -89,10 +160,16 @@ func (h SiteCreateHandler) handler(c *gin.Context) {
 	}
 
 	// fetch repository info
-	repo, err := github.FetchRepository(github.FetchRepositoryInput{
-		RepositoryURL: req.RepositoryURL,
-		Token:         githubAuth.AccessToken,
-	})
+	owner, name, ok := database.Site{RepositoryURL: strings.TrimSuffix(req.RepositoryURL, ".git")}.OwnerAndRepo()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	providerRepo := provider.Repo{Owner: owner, Name: name, Token: token}
+	repo, err := p.FetchRepository(c.Request.Context(), providerRepo)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch repository",
@@ -100,16 +177,79 @@ func (h SiteCreateHandler) handler(c *gin.Context) {
 		return
 	}
 
+	branch := repo.DefaultBranch
+	if req.WorkingBranch != "" && req.WorkingBranch != repo.DefaultBranch {
+		branch = req.WorkingBranch
+	} else {
+		req.WorkingBranch = ""
+	}
+
+	head, err := p.HeadCommit(c.Request.Context(), providerRepo, branch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Branch not found",
+		})
+		return
+	}
+
+	// sites are only registered with a valid configuration, whose settings override the requested ones
+	siteConfig, err := siteconfig.Load(c.Request.Context(), provider.Scoped(p, rootPath), providerRepo, head)
+	var validationErrors siteconfig.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid .static-admin configuration",
+			"errors": validationErrors,
+		})
+		return
+	}
+	if err != nil {
+		glog.Errorf("Failed to load the configuration of %s: %v", req.RepositoryURL, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load site configuration",
+		})
+		return
+	}
+
+	// sites without a generator are detected from the configuration files in their root path
+	if req.Generator == "" && (siteConfig.Settings == nil || siteConfig.Settings.Generator == "") {
+		req.Generator = h.detectGenerator(c, provider.Scoped(p, rootPath), providerRepo, head)
+	}
+
+	webhookSecret, err := database.GenerateWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate webhook secret",
+		})
+		return
+	}
+
 	// Create new site
 	site := database.Site{
-		UserID:        user.ID,
-		RepositoryURL: repo.HtmlURL,
-		Description:   repo.Description,
-		DefaultBranch: repo.DefaultBranch,
-		Private:       repo.Private,
+		UserID:         user.ID,
+		RepositoryURL:  repo.HtmlURL,
+		Provider:       req.Provider,
+		Description:    repo.Description,
+		DefaultBranch:  repo.DefaultBranch,
+		Private:        repo.Private,
+		PublishingMode: publishingMode,
+		StagingBranch:  stagingBranch,
+		Generator:      req.Generator,
+		RootPath:       rootPath,
+		WorkingBranch:  req.WorkingBranch,
+		WebhookSecret:  webhookSecret,
+		WorkspaceID:    req.WorkspaceID,
 	}
 
-	if err := h.Database.Create(&site).Error; err != nil {
+	err = h.Database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&site).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&database.SiteMember{SiteID: site.ID, UserID: user.ID, Role: database.RoleOwner}).Error; err != nil {
+			return err
+		}
+		return siteconfig.Apply(tx, &site, siteConfig)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create site",
 		})
@@ -117,7 +257,45 @@ func (h SiteCreateHandler) handler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"id":  site.ID,
-		"url": site.RepositoryURL,
+		"id":             site.ID,
+		"url":            site.RepositoryURL,
+		"webhook_url":    webhookPath(site),
+		"webhook_secret": site.WebhookSecret,
 	})
 }
+
+// detectGenerator guesses the generator of a site from its files, falling back to jekyll
+func (h SiteCreateHandler) detectGenerator(c *gin.Context, p provider.Provider, repo provider.Repo, commitSHA string) string {
+	entries, err := p.Tree(c.Request.Context(), repo, commitSHA)
+	if err != nil {
+		glog.Errorf("Failed to list files to detect the generator: %v", err)
+		return generator.Jekyll
+	}
+
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+	}
+
+	if detected := generator.Detect(paths); detected != "" {
+		return detected
+	}
+	return generator.Jekyll
+}
+
+// normalizePublishingMode applies defaults to a requested publishing mode and staging branch
+func normalizePublishingMode(mode, stagingBranch string) (string, string, bool) {
+	if mode == "" {
+		mode = database.PublishingModePullRequest
+	}
+
+	if !database.ValidPublishingMode(mode) {
+		return "", "", false
+	}
+
+	if mode == database.PublishingModeStaging && stagingBranch == "" {
+		stagingBranch = "staging"
+	}
+
+	return mode, stagingBranch, true
+}
@@ -7,6 +7,7 @@ import (
 	"static-admin/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"gorm.io/gorm"
 )
 
@@ -43,7 +44,7 @@ func (h SiteDeleteHandler) handler(c *gin.Context) {
 	}
 
 	siteID := c.Param("siteId")
-	_, err := database.GetSite(h.Database, siteID, user)
+	site, err := database.GetSite(h.Database, siteID, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -51,8 +52,8 @@ func (h SiteDeleteHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Delete site (only if it belongs to the user)
-	result := h.Database.Where("id = ? AND user_id = ?", c.Param("siteId"), user.ID).Delete(&database.Site{})
+	// only owners reach this point, the site middleware checks the delete permission
+	result := h.Database.Delete(&site)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete site",
@@ -60,5 +61,19 @@ func (h SiteDeleteHandler) handler(c *gin.Context) {
 		return
 	}
 
+	// the site can no longer be fetched once deleted, so leftover members do not grant any access
+	if err := database.DeleteSiteMembers(h.Database, site.ID); err != nil {
+		glog.Errorf("Failed to delete members for site %d: %v", site.ID, err)
+	}
+
+	if err := database.DeleteSiteTemplates(h.Database, site.ID); err != nil {
+		glog.Errorf("Failed to delete templates for site %d: %v", site.ID, err)
+	}
+
+	// the index is only a cache of the repository, so failing to remove it is not fatal
+	if err := database.DeleteRepositoryIndex(h.Database, site.ID); err != nil {
+		glog.Errorf("Failed to delete repository index for site %d: %v", site.ID, err)
+	}
+
 	c.Status(http.StatusOK)
 }
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteInvitationCreateRequest represents the JSON request for inviting someone to a site
+// by either email address or GitHub login
+type SiteInvitationCreateRequest struct {
+	Email       string `json:"email"`
+	GitHubLogin string `json:"github_login"`
+	Role        string `json:"role" binding:"required"`
+}
+
+// NewSiteInvitationCreateHandler creates a new handler for inviting someone to a site
+func NewSiteInvitationCreateHandler(config config.Config) (SiteInvitationCreateHandler, error) {
+	return SiteInvitationCreateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteInvitationCreateHandler handles the site invitation create request
+type SiteInvitationCreateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteInvitationCreateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.PUT("/sites/:siteId/invitations", h.handler)
+}
+
+// handler handles the PUT request for inviting someone to a site
+func (h SiteInvitationCreateHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetSiteMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req SiteInvitationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	email := database.NormalizeInvitee(req.Email)
+	githubLogin := database.NormalizeInvitee(req.GitHubLogin)
+	if (email == "") == (githubLogin == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Either an email or a GitHub login is required",
+		})
+		return
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid email address",
+		})
+		return
+	}
+
+	if !database.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role must be one of owner, admin, editor, author or viewer",
+		})
+		return
+	}
+	if !member.CanAssign(req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can invite owners",
+		})
+		return
+	}
+
+	// people who already have an account and are members do not need an invitation
+	users := h.Database.Model(&database.User{}).Select("id").Where("LOWER(email) = ?", email)
+	if githubLogin != "" {
+		users = h.Database.Model(&database.GitHubAuth{}).Select("user_id").Where("LOWER(login) = ?", githubLogin)
+	}
+	var members int64
+	if err := h.Database.Model(&database.SiteMember{}).Where("site_id = ? AND user_id IN (?)", member.SiteID, users).Count(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch members",
+		})
+		return
+	}
+	if members > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This person is already a member of the site",
+		})
+		return
+	}
+
+	// inviting someone again replaces their previous invitation
+	err := h.Database.Unscoped().
+		Where("site_id = ? AND email = ? AND git_hub_login = ?", member.SiteID, email, githubLogin).
+		Delete(&database.SiteInvitation{}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create invitation",
+		})
+		return
+	}
+
+	invitation := database.SiteInvitation{
+		SiteID:      member.SiteID,
+		Email:       email,
+		GitHubLogin: githubLogin,
+		Role:        req.Role,
+		InvitedByID: member.UserID,
+		ExpiresAt:   time.Now().Add(database.InvitationTTL),
+	}
+	if err := h.Database.Create(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create invitation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newSiteInvitationResponse(invitation))
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewSiteInvitationDeleteHandler creates a new handler for revoking an invitation to a site
+func NewSiteInvitationDeleteHandler(config config.Config) (SiteInvitationDeleteHandler, error) {
+	return SiteInvitationDeleteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteInvitationDeleteHandler handles the site invitation delete request
+type SiteInvitationDeleteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteInvitationDeleteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.DELETE("/sites/:siteId/invitations/:invitationId", h.handler)
+	r.OPTIONS("/sites/:siteId/invitations/:invitationId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the DELETE request for revoking an invitation
+func (h SiteInvitationDeleteHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetSiteMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	result := h.Database.Unscoped().
+		Where("id = ? AND site_id = ?", c.Param("invitationId"), member.SiteID).
+		Delete(&database.SiteInvitation{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete invitation",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invitation not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteInvitationResponse represents an invitation to a site in the JSON response
+type SiteInvitationResponse struct {
+	ID          uint   `json:"id"`
+	SiteID      uint   `json:"site_id"`
+	SiteName    string `json:"site_name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	GitHubLogin string `json:"github_login,omitempty"`
+	Role        string `json:"role"`
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// newSiteInvitationResponse converts an invitation to its response format
+func newSiteInvitationResponse(invitation database.SiteInvitation) SiteInvitationResponse {
+	return SiteInvitationResponse{
+		ID:          invitation.ID,
+		SiteID:      invitation.SiteID,
+		Email:       invitation.Email,
+		GitHubLogin: invitation.GitHubLogin,
+		Role:        invitation.Role,
+		CreatedAt:   invitation.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:   invitation.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// NewSiteInvitationsHandler creates a new handler for listing the pending invitations of a site
+func NewSiteInvitationsHandler(config config.Config) (SiteInvitationsHandler, error) {
+	return SiteInvitationsHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteInvitationsHandler handles the site invitations request
+type SiteInvitationsHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteInvitationsHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/invitations", h.handler)
+	r.OPTIONS("/sites/:siteId/invitations", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the pending invitations of a site
+func (h SiteInvitationsHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetSiteMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var invitations []database.SiteInvitation
+	err := h.Database.Where("site_id = ? AND expires_at > ?", member.SiteID, time.Now()).Order("created_at").Find(&invitations).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch invitations",
+		})
+		return
+	}
+
+	response := make([]SiteInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = newSiteInvitationResponse(invitation)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewSiteMemberDeleteHandler creates a new handler for removing a member from a site
+func NewSiteMemberDeleteHandler(config config.Config) (SiteMemberDeleteHandler, error) {
+	return SiteMemberDeleteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteMemberDeleteHandler handles the site member delete request
+type SiteMemberDeleteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteMemberDeleteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.DELETE("/sites/:siteId/members/:memberId", h.handler)
+}
+
+// handler handles the DELETE request for removing a member from a site
+func (h SiteMemberDeleteHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetSiteMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var member database.SiteMember
+	if err := h.Database.Where("id = ? AND site_id = ?", c.Param("memberId"), current.SiteID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Member not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch member",
+		})
+		return
+	}
+
+	if !current.CanAssign(member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can remove owners",
+		})
+		return
+	}
+
+	if member.Role == database.RoleOwner {
+		owners, err := database.CountOwners(h.Database, current.SiteID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch members",
+			})
+			return
+		}
+		if owners <= 1 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "A site must keep at least one owner",
+			})
+			return
+		}
+	}
+
+	// members are removed for good so that the user can be invited again
+	if err := h.Database.Unscoped().Delete(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove member",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteMemberUpdateRequest represents the JSON request for changing the role of a member
+type SiteMemberUpdateRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// NewSiteMemberUpdateHandler creates a new handler for changing the role of a member
+func NewSiteMemberUpdateHandler(config config.Config) (SiteMemberUpdateHandler, error) {
+	return SiteMemberUpdateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteMemberUpdateHandler handles the site member update request
+type SiteMemberUpdateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteMemberUpdateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/members/:memberId", h.handler)
+	r.OPTIONS("/sites/:siteId/members/:memberId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for changing the role of a member
+func (h SiteMemberUpdateHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetSiteMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req SiteMemberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if !database.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role must be one of owner, admin, editor, author or viewer",
+		})
+		return
+	}
+
+	var member database.SiteMember
+	if err := h.Database.Where("id = ? AND site_id = ?", c.Param("memberId"), current.SiteID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Member not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch member",
+		})
+		return
+	}
+
+	// only owners can change the role of an owner or make someone an owner
+	if !current.CanAssign(req.Role) || !current.CanAssign(member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can change owners",
+		})
+		return
+	}
+
+	if member.Role == database.RoleOwner && req.Role != database.RoleOwner {
+		owners, err := database.CountOwners(h.Database, current.SiteID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch members",
+			})
+			return
+		}
+		if owners <= 1 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "A site must keep at least one owner",
+			})
+			return
+		}
+	}
+
+	if err := h.Database.Model(&member).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSiteMemberResponse(h.Database, member))
+}
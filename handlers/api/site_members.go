@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteMemberResponse represents a member of a site in the JSON response
+type SiteMemberResponse struct {
+	ID          uint   `json:"id"`
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	GitHubLogin string `json:"github_login,omitempty"`
+	Role        string `json:"role"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// newSiteMemberResponse converts a member of a site to its response format
+func newSiteMemberResponse(db *gorm.DB, member database.SiteMember) SiteMemberResponse {
+	response := SiteMemberResponse{
+		ID:        member.ID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt.Format(time.RFC3339),
+	}
+
+	var user database.User
+	if err := db.First(&user, member.UserID).Error; err == nil {
+		response.Name = user.Name
+		response.Email = user.Email
+	}
+	var githubAuth database.GitHubAuth
+	if err := db.Where("user_id = ?", member.UserID).First(&githubAuth).Error; err == nil {
+		response.GitHubLogin = githubAuth.Login
+	}
+	return response
+}
+
+// NewSiteMembersHandler creates a new handler for listing the members of a site
+func NewSiteMembersHandler(config config.Config) (SiteMembersHandler, error) {
+	return SiteMembersHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteMembersHandler handles the site members request
+type SiteMembersHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteMembersHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/members", h.handler)
+	r.OPTIONS("/sites/:siteId/members", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the members of a site
+func (h SiteMembersHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var members []database.SiteMember
+	if err := h.Database.Where("site_id = ?", site.ID).Order("created_at").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch members",
+		})
+		return
+	}
+
+	response := make([]SiteMemberResponse, len(members))
+	for i, member := range members {
+		response[i] = newSiteMemberResponse(h.Database, member)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
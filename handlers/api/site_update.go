@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/middleware"
+	"static-admin/provider"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteUpdateRequest represents the JSON data for updating a site's settings
+type SiteUpdateRequest struct {
+	PublishingMode string `json:"publishing_mode"`
+	StagingBranch  string `json:"staging_branch"`
+	Generator      string `json:"generator"`
+
+	// RootPath and WorkingBranch are pointers so they can be cleared with an empty string
+	RootPath      *string `json:"root_path"`
+	WorkingBranch *string `json:"working_branch"`
+}
+
+// NewSiteUpdateHandler creates a new handler for the site update endpoint
+func NewSiteUpdateHandler(config config.Config) (SiteUpdateHandler, error) {
+	return SiteUpdateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteUpdateHandler handles the site update request
+type SiteUpdateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteUpdateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId", h.handler)
+}
+
+// handler handles the POST request for site updates
+func (h SiteUpdateHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req SiteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// unset settings keep their current values
+	if req.PublishingMode == "" {
+		req.PublishingMode = site.PublishingMode
+	}
+	if req.StagingBranch == "" {
+		req.StagingBranch = site.StagingBranch
+	}
+	if req.Generator == "" {
+		req.Generator = site.Generator
+	}
+
+	publishingMode, stagingBranch, ok := normalizePublishingMode(req.PublishingMode, req.StagingBranch)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Publishing mode must be one of pull_request, direct or staging",
+		})
+		return
+	}
+
+	if !generator.Valid(req.Generator) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Generator must be one of jekyll, hugo, eleventy or other",
+		})
+		return
+	}
+
+	if publishingMode == database.PublishingModeStaging && stagingBranch == site.Branch() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Staging branch must differ from the default branch",
+		})
+		return
+	}
+
+	scopeChanged := false
+	if req.RootPath != nil {
+		rootPath, ok := database.NormalizeRootPath(*req.RootPath)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid root path",
+			})
+			return
+		}
+		scopeChanged = rootPath != site.RootPath
+		site.RootPath = rootPath
+	}
+
+	if req.WorkingBranch != nil && *req.WorkingBranch != site.WorkingBranch {
+		site.WorkingBranch = *req.WorkingBranch
+		if site.WorkingBranch == site.DefaultBranch {
+			site.WorkingBranch = ""
+		}
+
+		if site.WorkingBranch != "" && !h.branchExists(c, site) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Branch not found",
+			})
+			return
+		}
+		scopeChanged = true
+	}
+
+	site.PublishingMode = publishingMode
+	site.StagingBranch = stagingBranch
+	site.Generator = req.Generator
+	if err := h.Database.Save(&site).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update site",
+		})
+		return
+	}
+
+	// the index holds the files of the previous root path or branch and is rebuilt on the next read
+	if scopeChanged {
+		if err := database.DeleteRepositoryIndex(h.Database, site.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to reset the site index",
+			})
+			return
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// branchExists returns true if the working branch of the site exists in its repository
+func (h SiteUpdateHandler) branchExists(c *gin.Context, site database.Site) bool {
+	user, _ := middleware.GetUser(c)
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		return false
+	}
+
+	p, repo, err := provider.RepoForSite(site, token)
+	if err != nil {
+		return false
+	}
+
+	_, err = p.HeadCommit(c.Request.Context(), repo, site.WorkingBranch)
+	return err == nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SiteWebhookResponse represents the webhook settings in the JSON response
+type SiteWebhookResponse struct {
+	WebhookURL    string `json:"webhook_url"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// webhookPath returns the path GitHub webhooks for a site are delivered to
+func webhookPath(site database.Site) string {
+	return fmt.Sprintf("/webhooks/github/%d", site.ID)
+}
+
+// NewSiteWebhookHandler creates a new handler for rotating a site's webhook secret
+func NewSiteWebhookHandler(config config.Config) (SiteWebhookHandler, error) {
+	return SiteWebhookHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// SiteWebhookHandler handles the webhook secret rotation request
+type SiteWebhookHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h SiteWebhookHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/sites/:siteId/webhook", h.handler)
+	r.OPTIONS("/sites/:siteId/webhook", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for generating a new webhook secret
+func (h SiteWebhookHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	secret, err := database.GenerateWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate webhook secret",
+		})
+		return
+	}
+
+	// deliveries signed with the old secret are rejected from now on, so the
+	// index can no longer rely on webhooks until a new delivery arrives
+	update := map[string]interface{}{
+		"webhook_secret":      secret,
+		"webhook_received_at": nil,
+	}
+	if err := h.Database.Model(&site).Updates(update).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update webhook secret",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SiteWebhookResponse{
+		WebhookURL:    webhookPath(site),
+		WebhookSecret: secret,
+	})
+}
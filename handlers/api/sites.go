@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
@@ -13,13 +14,30 @@ import (
 
 // SiteResponse represents a site in the JSON response
 type SiteResponse struct {
-	ID            uint   `json:"id"`
-	UserID        uint   `json:"user_id"`
-	RepositoryURL string `json:"url"`
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	DefaultBranch string `json:"default_branch"`
-	Private       bool   `json:"private"`
+	ID             uint   `json:"id"`
+	UserID         uint   `json:"user_id"`
+	RepositoryURL  string `json:"url"`
+	Provider       string `json:"provider"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	DefaultBranch  string `json:"default_branch"`
+	Private        bool   `json:"private"`
+	PublishingMode string `json:"publishing_mode"`
+	StagingBranch  string `json:"staging_branch"`
+	Generator      string `json:"generator"`
+	RootPath       string `json:"root_path"`
+	WorkingBranch  string `json:"working_branch"`
+	WebhookURL     string `json:"webhook_url"`
+	WebhookActive  bool   `json:"webhook_active"`
+
+	// Role is the role of the current user on the site
+	Role string `json:"role"`
+
+	// WorkspaceID is the workspace the site belongs to, null for sites outside of a workspace
+	WorkspaceID *uint `json:"workspace_id"`
+
+	// ConfigError describes why the .static-admin configuration of the repository was rejected
+	ConfigError string `json:"config_error"`
 }
 
 // NewSitesHandler creates a new handler for the sites endpoint
@@ -54,9 +72,14 @@ func (h SitesHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Fetch sites for user
+	// Fetch sites the user is a member of, optionally limited to a workspace
+	query := database.AccessibleSites(h.Database, user.ID)
+	if workspaceID := c.Query("workspace_id"); workspaceID != "" {
+		query = query.Where("workspace_id = ?", workspaceID)
+	}
+
 	var sites []database.Site
-	if err := h.Database.Where("user_id = ?", user.ID).Find(&sites).Error; err != nil {
+	if err := query.Find(&sites).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch sites",
 		})
@@ -68,14 +91,30 @@ func (h SitesHandler) handler(c *gin.Context) {
 	for i, site := range sites {
 		parts := strings.Split(site.RepositoryURL, "/")
 		repositoryName := parts[len(parts)-1]
+		if site.RootPath != "" {
+			repositoryName += "/" + site.RootPath
+		}
 		response[i] = SiteResponse{
-			ID:            site.ID,
-			UserID:        site.UserID,
-			RepositoryURL: site.RepositoryURL,
-			Name:          repositoryName,
-			Description:   site.Description,
-			DefaultBranch: site.DefaultBranch,
-			Private:       site.Private,
+			ID:             site.ID,
+			UserID:         site.UserID,
+			RepositoryURL:  site.RepositoryURL,
+			Provider:       site.Provider,
+			Name:           repositoryName,
+			Description:    site.Description,
+			DefaultBranch:  site.DefaultBranch,
+			Private:        site.Private,
+			PublishingMode: site.PublishingMode,
+			StagingBranch:  site.StagingBranch,
+			Generator:      site.Generator,
+			RootPath:       site.RootPath,
+			WorkingBranch:  site.WorkingBranch,
+			WebhookURL:     webhookPath(site),
+			WebhookActive:  site.WebhookReceivedAt != nil,
+			WorkspaceID:    site.WorkspaceID,
+			ConfigError:    site.ConfigError,
+		}
+		if member, err := database.GetSiteMember(h.Database, fmt.Sprint(site.ID), user.ID); err == nil {
+			response[i].Role = member.Role
 		}
 	}
 
@@ -5,11 +5,82 @@ import (
 	"static-admin/config"
 	"static-admin/database"
 	"static-admin/middleware"
+	"static-admin/schema"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// TemplateFieldSchema represents the constraints of a template field in JSON requests and responses,
+// dates are RFC 3339 timestamps and empty when unbounded
+type TemplateFieldSchema struct {
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowedValues"`
+	Min           *float64 `json:"min"`
+	Max           *float64 `json:"max"`
+	Pattern       string   `json:"pattern"`
+	MaxLength     int      `json:"maxLength"`
+	MinDate       string   `json:"minDate"`
+	MaxDate       string   `json:"maxDate"`
+	HelpText      string   `json:"helpText"`
+}
+
+// newTemplateFieldSchema converts the constraints of a template field to their JSON representation
+func newTemplateFieldSchema(field database.TemplateField) TemplateFieldSchema {
+	result := TemplateFieldSchema{
+		Required:      field.Required,
+		AllowedValues: field.AllowedValues,
+		Min:           field.Min,
+		Max:           field.Max,
+		Pattern:       field.Pattern,
+		MaxLength:     field.MaxLength,
+		HelpText:      field.HelpText,
+	}
+	if result.AllowedValues == nil {
+		result.AllowedValues = []string{}
+	}
+	if field.MinDate != nil {
+		result.MinDate = field.MinDate.Format(time.RFC3339)
+	}
+	if field.MaxDate != nil {
+		result.MaxDate = field.MaxDate.Format(time.RFC3339)
+	}
+	return result
+}
+
+// apply sets the constraints on a template field, returning an error when they are invalid
+func (s TemplateFieldSchema) apply(field *database.TemplateField) error {
+	field.Required = s.Required
+	field.AllowedValues = database.StringSliceValue(s.AllowedValues)
+	if field.AllowedValues == nil {
+		field.AllowedValues = database.StringSliceValue{}
+	}
+	field.Min = s.Min
+	field.Max = s.Max
+	field.Pattern = s.Pattern
+	field.MaxLength = s.MaxLength
+	field.HelpText = s.HelpText
+
+	field.MinDate = nil
+	if s.MinDate != "" {
+		minDate, err := time.Parse(time.RFC3339, s.MinDate)
+		if err != nil {
+			return err
+		}
+		field.MinDate = &minDate
+	}
+	field.MaxDate = nil
+	if s.MaxDate != "" {
+		maxDate, err := time.Parse(time.RFC3339, s.MaxDate)
+		if err != nil {
+			return err
+		}
+		field.MaxDate = &maxDate
+	}
+	return schema.Check(*field)
+}
+
 // TemplateFieldResponse represents a template field in the JSON response
 type TemplateFieldResponse struct {
 	ID               uint     `json:"id"`
@@ -20,13 +91,18 @@ type TemplateFieldResponse struct {
 	NumberValue      float64  `json:"numberValue"`
 	DateTimeValue    string   `json:"dateTimeValue"`
 	StringSliceValue []string `json:"stringSliceValue"`
+	TemplateFieldSchema
 }
 
 // SingleTemplateResponse represents a single template with its fields
 type SingleTemplateResponse struct {
-	ID     uint                    `json:"id"`
-	Name   string                  `json:"name"`
-	Fields []TemplateFieldResponse `json:"fields"`
+	ID          uint                    `json:"id"`
+	Name        string                  `json:"name"`
+	WorkspaceID *uint                   `json:"workspace_id"`
+	SiteID      *uint                   `json:"site_id"`
+	Collection  string                  `json:"collection"`
+	Default     bool                    `json:"default"`
+	Fields      []TemplateFieldResponse `json:"fields"`
 }
 
 // NewTemplateHandler creates a new handler for the single template endpoint
@@ -49,6 +125,10 @@ func (h TemplateHandler) GroupRegister(r *gin.RouterGroup) {
 	r.OPTIONS("/templates/:templateId", func(c *gin.Context) {
 		c.Status(http.StatusNoContent)
 	})
+	r.GET("/sites/:siteId/templates/:templateId", h.handler)
+	r.OPTIONS("/sites/:siteId/templates/:templateId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
 }
 
 // handler handles the GET request for a single template
@@ -69,9 +149,9 @@ func (h TemplateHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Fetch template and verify ownership
+	// Fetch template and verify the user can access it
 	var template database.Template
-	if err := h.Database.Where("id = ? AND user_id = ?", templateID, user.ID).First(&template).Error; err != nil {
+	if err := templateScope(c, h.Database, user, false).Where("id = ?", templateID).First(&template).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Template not found",
@@ -105,13 +185,19 @@ func (h TemplateHandler) handler(c *gin.Context) {
 			NumberValue:      field.NumberValue,
 			DateTimeValue:    field.DateTimeValue.Format("2006-01-02T15:04:05Z07:00"),
 			StringSliceValue: field.StringSliceValue,
+
+			TemplateFieldSchema: newTemplateFieldSchema(field),
 		}
 	}
 
 	// Return combined response
 	c.JSON(http.StatusOK, SingleTemplateResponse{
-		ID:     template.ID,
-		Name:   template.Name,
-		Fields: fieldResponses,
+		ID:          template.ID,
+		Name:        template.Name,
+		WorkspaceID: template.WorkspaceID,
+		SiteID:      template.SiteID,
+		Collection:  template.Collection,
+		Default:     template.Default,
+		Fields:      fieldResponses,
 	})
 }
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
 	"static-admin/middleware"
+	"static-admin/siteconfig"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -14,6 +17,14 @@ import (
 type TemplateCreateRequest struct {
 	Name   string                `json:"name" binding:"required"`
 	Fields []TemplateCreateField `json:"fields" binding:"required"`
+
+	// WorkspaceID creates the template in a workspace the user manages instead of as a personal template
+	WorkspaceID *uint `json:"workspace_id"`
+
+	// Collection and Default only apply to site templates, a default template pre-populates the
+	// frontmatter of new posts in its collection
+	Collection string `json:"collection"`
+	Default    bool   `json:"default"`
 }
 
 type TemplateCreateField struct {
@@ -24,6 +35,7 @@ type TemplateCreateField struct {
 	NumberValue      float64  `json:"numberValue"`
 	DateTimeValue    string   `json:"dateTimeValue"`
 	StringSliceValue []string `json:"stringSliceValue"`
+	TemplateFieldSchema
 }
 
 // NewTemplateCreateHandler creates a new handler for template creation
@@ -43,6 +55,15 @@ type TemplateCreateHandler struct {
 // GroupRegister registers the handler with the given router group
 func (h TemplateCreateHandler) GroupRegister(r *gin.RouterGroup) {
 	r.PUT("/templates", h.handler)
+	r.PUT("/sites/:siteId/templates", h.handler)
+}
+
+// parseTemplateDateTime parses the date and time value of a template field, empty values are the zero time
+func parseTemplateDateTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
 }
 
 // handler handles the PUT request for template creation
@@ -63,29 +84,92 @@ func (h TemplateCreateHandler) handler(c *gin.Context) {
 		return
 	}
 
+	siteMember, isSiteTemplate := middleware.GetSiteMember(c)
+	if req.WorkspaceID != nil && !isSiteTemplate {
+		member, err := database.GetWorkspaceMember(h.Database, fmt.Sprint(*req.WorkspaceID), user.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Workspace not found",
+			})
+			return
+		}
+		if !member.CanManage() {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Your role in this workspace does not allow this action",
+			})
+			return
+		}
+	}
+
+	collection, ok := database.NormalizeRootPath(req.Collection)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid collection",
+		})
+		return
+	}
+	if !isSiteTemplate && (collection != "" || req.Default) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Only site templates can have a collection or be the default for new posts",
+		})
+		return
+	}
+
+	templateFields := make([]database.TemplateField, len(req.Fields))
+	for i, field := range req.Fields {
+		dateTime, err := parseTemplateDateTime(field.DateTimeValue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid date and time for field %s", field.Name),
+			})
+			return
+		}
+		templateFields[i] = database.TemplateField{
+			Name:             field.Name,
+			Type:             field.Type,
+			StringValue:      field.StringValue,
+			BoolValue:        field.BoolValue,
+			NumberValue:      field.NumberValue,
+			DateTimeValue:    dateTime,
+			StringSliceValue: field.StringSliceValue,
+		}
+		if err := field.TemplateFieldSchema.apply(&templateFields[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid constraints for field %s: %v", field.Name, err),
+			})
+			return
+		}
+	}
+
+	template := database.Template{
+		UserID:      user.ID,
+		Name:        req.Name,
+		WorkspaceID: req.WorkspaceID,
+		Collection:  collection,
+		Default:     req.Default,
+	}
+	if isSiteTemplate {
+		template.SiteID = &siteMember.SiteID
+		template.WorkspaceID = nil
+		template.Path = siteconfig.TemplatePath(template.Name)
+	}
+
 	// Start a transaction
 	err := h.Database.Transaction(func(tx *gorm.DB) error {
-		// Create template
-		template := database.Template{
-			UserID: user.ID,
-			Name:   req.Name,
-		}
+		// Create template, replacing the previous default template of its collection
 		if err := tx.Create(&template).Error; err != nil {
 			return err
 		}
+		if template.Default {
+			if err := database.ClearDefaultTemplate(tx, *template.SiteID, template.Collection, template.ID); err != nil {
+				return err
+			}
+		}
 
 		// Create template fields
-		for _, field := range req.Fields {
-			templateField := database.TemplateField{
-				TemplateID:       template.ID,
-				Name:             field.Name,
-				Type:             field.Type,
-				StringValue:      field.StringValue,
-				BoolValue:        field.BoolValue,
-				NumberValue:      field.NumberValue,
-				StringSliceValue: field.StringSliceValue,
-			}
-			if err := tx.Create(&templateField).Error; err != nil {
+		for i := range templateFields {
+			templateFields[i].TemplateID = template.ID
+			if err := tx.Create(&templateFields[i]).Error; err != nil {
 				return err
 			}
 		}
@@ -100,5 +184,13 @@ func (h TemplateCreateHandler) handler(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	c.JSON(http.StatusCreated, TemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		WorkspaceID: template.WorkspaceID,
+		SiteID:      template.SiteID,
+		Collection:  template.Collection,
+		Default:     template.Default,
+		PRURL:       writeBackTemplate(c, h.Database, user, template, false),
+	})
 }
@@ -27,6 +27,7 @@ type TemplateDeleteHandler struct {
 // GroupRegister registers the handler with the given router group
 func (h TemplateDeleteHandler) GroupRegister(r *gin.RouterGroup) {
 	r.DELETE("/templates/:templateId", h.handler)
+	r.DELETE("/sites/:siteId/templates/:templateId", h.handler)
 }
 
 // handler handles the DELETE request for template deletion
@@ -48,10 +49,10 @@ func (h TemplateDeleteHandler) handler(c *gin.Context) {
 	}
 
 	// Start a transaction
+	var template database.Template
 	err := h.Database.Transaction(func(tx *gorm.DB) error {
-		// Verify template exists and is owned by user
-		var template database.Template
-		if err := tx.Where("id = ? AND user_id = ?", templateID, user.ID).First(&template).Error; err != nil {
+		// Verify template exists and the user can edit it
+		if err := templateScope(c, tx, user, true).Where("id = ?", templateID).First(&template).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return err
 			}
@@ -84,5 +85,12 @@ func (h TemplateDeleteHandler) handler(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	// templates only stored here have no file to delete
+	prURL := ""
+	if template.Path != "" {
+		prURL = writeBackTemplate(c, h.Database, user, template, true)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"pr_url": prURL,
+	})
 }
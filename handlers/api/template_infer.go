@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/markdown"
+	"static-admin/middleware"
+	"static-admin/provider"
+	"static-admin/repoindex"
+	"static-admin/schema"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultInferSample is the number of posts sampled to infer a template when none is requested
+	defaultInferSample = 20
+
+	// maxInferSample is the largest number of posts sampled to infer a template
+	maxInferSample = 100
+)
+
+// TemplateInferResponse represents the template proposed for a collection from a sample of its posts
+type TemplateInferResponse struct {
+	Collection string `json:"collection"`
+
+	// Sampled is the number of posts read, Skipped the number of them whose frontmatter could not be parsed
+	Sampled int                 `json:"sampled"`
+	Skipped int                 `json:"skipped"`
+	Fields  []schema.FieldStats `json:"fields"`
+
+	// Template is the proposed template, accepted by sending it as is to PUT /api/sites/:siteId/templates
+	Template TemplateCreateRequest `json:"template"`
+}
+
+// NewTemplateInferHandler creates a new handler for inferring a template from the posts of a site
+func NewTemplateInferHandler(config config.Config) (TemplateInferHandler, error) {
+	return TemplateInferHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// TemplateInferHandler handles the template inference request
+type TemplateInferHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h TemplateInferHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/sites/:siteId/templates/infer", h.handler)
+	r.OPTIONS("/sites/:siteId/templates/infer", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for proposing a template from the frontmatter of the most recent posts
+// of a collection, the posts directory of the generator by default
+func (h TemplateInferHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	site, err := database.GetSite(h.Database, c.Param("siteId"), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	collection, ok := database.NormalizeRootPath(c.Query("collection"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid collection",
+		})
+		return
+	}
+	if collection == "" {
+		collection = generator.PostsDirectory(site.Generator)
+	}
+
+	sample := defaultInferSample
+	if value := c.Query("sample"); value != "" {
+		sample, err = strconv.Atoi(value)
+		if err != nil || sample < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Sample must be a positive number",
+			})
+			return
+		}
+		sample = min(sample, maxInferSample)
+	}
+
+	token, err := database.GetProviderToken(h.Database, user.ID, site.Provider)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	p, repo, err := provider.RepoForSite(site, token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid repository URL",
+		})
+		return
+	}
+
+	index, err := repoindex.Get(c.Request.Context(), h.Database, repoindex.RefreshInput{
+		Site:  site,
+		Owner: repo.Owner,
+		Repo:  repo.Name,
+		Token: token,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch posts",
+		})
+		return
+	}
+
+	// the most recent posts are sampled, as they follow the current conventions of the site
+	posts := []repoindex.Post{}
+	for _, post := range index.Posts {
+		if strings.HasPrefix(post.Path, collection+"/") {
+			posts = append(posts, post)
+		}
+	}
+	if len(posts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No posts found in the collection",
+		})
+		return
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].Date.Equal(posts[j].Date) {
+			return posts[i].Date.After(posts[j].Date)
+		}
+		return posts[i].Path < posts[j].Path
+	})
+	if len(posts) > sample {
+		posts = posts[:sample]
+	}
+
+	entries := make([]provider.TreeEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = provider.TreeEntry{Path: post.Path, Type: "blob", SHA: post.SHA}
+	}
+	blobs, err := p.ReadBlobs(c.Request.Context(), repo, index.CommitSHA, entries)
+	if err != nil {
+		glog.Errorf("Failed to read posts of site %d to infer a template: %v", site.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read posts",
+		})
+		return
+	}
+
+	frontmatters := [][]markdown.FrontmatterField{}
+	skipped := 0
+	for _, post := range posts {
+		fields, _, err := markdown.ExtractFrontMatter([]byte(blobs[post.Path].Text))
+		if err != nil {
+			skipped++
+			continue
+		}
+		frontmatters = append(frontmatters, fields)
+	}
+
+	inference := schema.Infer(frontmatters)
+	response := TemplateInferResponse{
+		Collection: collection,
+		Sampled:    len(posts),
+		Skipped:    skipped,
+		Fields:     inference.Fields,
+		Template: TemplateCreateRequest{
+			Name:       collection,
+			Collection: collection,
+			Fields:     make([]TemplateCreateField, len(inference.TemplateFields)),
+		},
+	}
+	for i, field := range inference.TemplateFields {
+		response.Template.Fields[i] = TemplateCreateField{
+			Name:                field.Name,
+			Type:                field.Type,
+			BoolValue:           field.BoolValue,
+			StringSliceValue:    field.StringSliceValue,
+			TemplateFieldSchema: newTemplateFieldSchema(field),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
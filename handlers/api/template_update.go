@@ -1,10 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
 	"static-admin/middleware"
+	"static-admin/siteconfig"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -14,6 +16,10 @@ import (
 type TemplateUpdateRequest struct {
 	Name   string                `json:"name" binding:"required"`
 	Fields []TemplateUpdateField `json:"fields" binding:"required"`
+
+	// Collection and Default only apply to site templates
+	Collection string `json:"collection"`
+	Default    bool   `json:"default"`
 }
 
 type TemplateUpdateField struct {
@@ -25,6 +31,7 @@ type TemplateUpdateField struct {
 	NumberValue      float64  `json:"numberValue"`
 	DateTimeValue    string   `json:"dateTimeValue"`
 	StringSliceValue []string `json:"stringSliceValue"`
+	TemplateFieldSchema
 }
 
 // NewTemplateUpdateHandler creates a new handler for template updates
@@ -44,6 +51,7 @@ type TemplateUpdateHandler struct {
 // GroupRegister registers the handler with the given router group
 func (h TemplateUpdateHandler) GroupRegister(r *gin.RouterGroup) {
 	r.POST("/templates/:templateId", h.handler)
+	r.POST("/sites/:siteId/templates/:templateId", h.handler)
 }
 
 // handler handles the POST request for template updates
@@ -72,19 +80,69 @@ func (h TemplateUpdateHandler) handler(c *gin.Context) {
 		return
 	}
 
+	collection, ok := database.NormalizeRootPath(req.Collection)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid collection",
+		})
+		return
+	}
+	if _, isSiteTemplate := middleware.GetSiteMember(c); !isSiteTemplate && (collection != "" || req.Default) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Only site templates can have a collection or be the default for new posts",
+		})
+		return
+	}
+
+	templateFields := make([]database.TemplateField, len(req.Fields))
+	for i, field := range req.Fields {
+		dateTime, err := parseTemplateDateTime(field.DateTimeValue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid date and time for field %s", field.Name),
+			})
+			return
+		}
+		templateFields[i] = database.TemplateField{
+			Name:             field.Name,
+			Type:             field.Type,
+			StringValue:      field.StringValue,
+			BoolValue:        field.BoolValue,
+			NumberValue:      field.NumberValue,
+			DateTimeValue:    dateTime,
+			StringSliceValue: field.StringSliceValue,
+		}
+		if err := field.TemplateFieldSchema.apply(&templateFields[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid constraints for field %s: %v", field.Name, err),
+			})
+			return
+		}
+	}
+
 	// Start a transaction
+	var template database.Template
 	err := h.Database.Transaction(func(tx *gorm.DB) error {
-		// Verify template exists and is owned by user
-		var template database.Template
-		if err := tx.Where("id = ? AND user_id = ?", templateID, user.ID).First(&template).Error; err != nil {
+		// Verify template exists and the user can edit it
+		if err := templateScope(c, tx, user, true).Where("id = ?", templateID).First(&template).Error; err != nil {
 			return err
 		}
 
-		// Update template name
+		// Update template, replacing the previous default template of its collection
 		template.Name = req.Name
+		template.Collection = collection
+		template.Default = req.Default
+		if template.SiteID != nil && template.Path == "" {
+			template.Path = siteconfig.TemplatePath(template.Name)
+		}
 		if err := tx.Save(&template).Error; err != nil {
 			return err
 		}
+		if template.Default {
+			if err := database.ClearDefaultTemplate(tx, *template.SiteID, template.Collection, template.ID); err != nil {
+				return err
+			}
+		}
 
 		// Delete existing fields
 		if err := tx.Where("template_id = ?", template.ID).Delete(&database.TemplateField{}).Error; err != nil {
@@ -92,17 +150,9 @@ func (h TemplateUpdateHandler) handler(c *gin.Context) {
 		}
 
 		// Create new fields
-		for _, field := range req.Fields {
-			templateField := database.TemplateField{
-				TemplateID:       template.ID,
-				Name:             field.Name,
-				Type:             field.Type,
-				StringValue:      field.StringValue,
-				BoolValue:        field.BoolValue,
-				NumberValue:      field.NumberValue,
-				StringSliceValue: field.StringSliceValue,
-			}
-			if err := tx.Create(&templateField).Error; err != nil {
+		for i := range templateFields {
+			templateFields[i].TemplateID = template.ID
+			if err := tx.Create(&templateFields[i]).Error; err != nil {
 				return err
 			}
 		}
@@ -123,5 +173,7 @@ func (h TemplateUpdateHandler) handler(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, gin.H{
+		"pr_url": writeBackTemplate(c, h.Database, user, template, false),
+	})
 }
@@ -1,19 +1,85 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"static-admin/config"
 	"static-admin/database"
 	"static-admin/middleware"
+	"static-admin/siteconfig"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
 	"gorm.io/gorm"
 )
 
 // TemplateResponse represents a template in the JSON response
 type TemplateResponse struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID *uint  `json:"workspace_id"`
+	SiteID      *uint  `json:"site_id"`
+	Collection  string `json:"collection"`
+	Default     bool   `json:"default"`
+
+	// PRURL is the pull request writing a site template back to the repository of its site
+	PRURL string `json:"pr_url,omitempty"`
+}
+
+// templateScope scopes a template query to the templates of the site of site routes, whose access is
+// checked by the site middleware, or to the personal and workspace templates the user can read, or edit
+// when edit is true, for the global template routes
+func templateScope(c *gin.Context, db *gorm.DB, user database.User, edit bool) *gorm.DB {
+	if member, ok := middleware.GetSiteMember(c); ok {
+		return database.SiteTemplates(db, member.SiteID)
+	}
+	if edit {
+		return database.EditableTemplates(db, user.ID)
+	}
+	return database.AccessibleTemplates(db, user.ID)
+}
+
+// writeBackTemplate opens a pull request writing a site template changed in the admin to the repository of its
+// site, which holds the templates of the site. Failures are logged without failing the change in the admin.
+func writeBackTemplate(c *gin.Context, db *gorm.DB, user database.User, template database.Template, remove bool) string {
+	if template.SiteID == nil {
+		return ""
+	}
+
+	site, err := database.GetSite(db, fmt.Sprint(*template.SiteID), user)
+	if err != nil {
+		glog.Errorf("Failed to fetch site %d to write back template %d: %v", *template.SiteID, template.ID, err)
+		return ""
+	}
+
+	token, err := database.GetProviderToken(db, user.ID, site.Provider)
+	if err != nil {
+		glog.Errorf("Failed to fetch token to write back template %d: %v", template.ID, err)
+		return ""
+	}
+
+	var fields []database.TemplateField
+	if !remove {
+		fields, err = database.GetTemplateFields(db, template.ID)
+		if err != nil {
+			glog.Errorf("Failed to fetch fields to write back template %d: %v", template.ID, err)
+			return ""
+		}
+	}
+
+	result, err := siteconfig.WriteTemplate(c.Request.Context(), siteconfig.WriteInput{
+		Site:     site,
+		Token:    token,
+		Template: template,
+		Fields:   fields,
+		Delete:   remove,
+	})
+	if err != nil {
+		glog.Errorf("Failed to write back template %d to %s: %v", template.ID, site.RepositoryURL, err)
+		return ""
+	}
+	return result.PRURL
 }
 
 // NewTemplatesHandler creates a new handler for the templates endpoint
@@ -36,6 +102,10 @@ func (h TemplatesHandler) GroupRegister(r *gin.RouterGroup) {
 	r.OPTIONS("/templates", func(c *gin.Context) {
 		c.Status(http.StatusNoContent)
 	})
+	r.GET("/sites/:siteId/templates", h.handler)
+	r.OPTIONS("/sites/:siteId/templates", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
 }
 
 // handler handles the GET request for templates
@@ -48,9 +118,18 @@ func (h TemplatesHandler) handler(c *gin.Context) {
 		return
 	}
 
-	// Fetch templates for user
+	// Fetch the templates of the site, or the personal templates of the user and the templates
+	// of their workspaces, optionally limited to a collection or a workspace
+	query := templateScope(c, h.Database, user, false)
+	if collection, ok := c.GetQuery("collection"); ok {
+		query = query.Where("collection = ?", strings.Trim(collection, "/"))
+	}
+	if workspaceID := c.Query("workspace_id"); workspaceID != "" {
+		query = query.Where("workspace_id = ?", workspaceID)
+	}
+
 	var templates []database.Template
-	if err := h.Database.Where("user_id = ?", user.ID).Find(&templates).Error; err != nil {
+	if err := query.Find(&templates).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to fetch templates",
@@ -63,8 +142,12 @@ func (h TemplatesHandler) handler(c *gin.Context) {
 	response := make([]TemplateResponse, len(templates))
 	for i, template := range templates {
 		response[i] = TemplateResponse{
-			ID:   template.ID,
-			Name: template.Name,
+			ID:          template.ID,
+			Name:        template.Name,
+			WorkspaceID: template.WorkspaceID,
+			SiteID:      template.SiteID,
+			Collection:  template.Collection,
+			Default:     template.Default,
 		}
 	}
 
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewWorkspaceHandler creates a new handler for the single workspace endpoint
+func NewWorkspaceHandler(config config.Config) (WorkspaceHandler, error) {
+	return WorkspaceHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceHandler handles the single workspace request
+type WorkspaceHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/workspaces/:workspaceId", h.handler)
+	r.OPTIONS("/workspaces/:workspaceId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for a single workspace
+func (h WorkspaceHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var workspace database.Workspace
+	if err := h.Database.First(&workspace, member.WorkspaceID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch workspace",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newWorkspaceResponse(workspace, member.Role))
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceCreateRequest represents the JSON request for creating a workspace
+type WorkspaceCreateRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// NewWorkspaceCreateHandler creates a new handler for workspace creation
+func NewWorkspaceCreateHandler(config config.Config) (WorkspaceCreateHandler, error) {
+	return WorkspaceCreateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceCreateHandler handles the workspace creation request
+type WorkspaceCreateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceCreateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.PUT("/workspaces", h.handler)
+}
+
+// handler handles the PUT request for workspace creation, the creator becomes its owner
+func (h WorkspaceCreateHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req WorkspaceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	workspace := database.Workspace{
+		Name:            strings.TrimSpace(req.Name),
+		DefaultSiteRole: database.RoleEditor,
+	}
+	err := h.Database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&database.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      user.ID,
+			Role:        database.WorkspaceRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create workspace",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newWorkspaceResponse(workspace, database.WorkspaceRoleOwner))
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewWorkspaceDeleteHandler creates a new handler for the workspace deletion endpoint
+func NewWorkspaceDeleteHandler(config config.Config) (WorkspaceDeleteHandler, error) {
+	return WorkspaceDeleteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceDeleteHandler handles the workspace deletion request
+type WorkspaceDeleteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceDeleteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.DELETE("/workspaces/:workspaceId", h.handler)
+}
+
+// handler handles the DELETE request for workspace deletion. Workspaces still owning sites cannot
+// be deleted, their templates go back to the users who created them.
+func (h WorkspaceDeleteHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	if member.Role != database.WorkspaceRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can delete a workspace",
+		})
+		return
+	}
+
+	var sites int64
+	if err := h.Database.Model(&database.Site{}).Where("workspace_id = ?", member.WorkspaceID).Count(&sites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch sites",
+		})
+		return
+	}
+	if sites > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Move or delete the sites of the workspace before deleting it",
+		})
+		return
+	}
+
+	err := h.Database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.Template{}).Where("workspace_id = ?", member.WorkspaceID).Update("workspace_id", nil).Error; err != nil {
+			return err
+		}
+		if err := database.DeleteWorkspaceMembers(tx, member.WorkspaceID); err != nil {
+			return err
+		}
+		return tx.Delete(&database.Workspace{}, member.WorkspaceID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete workspace",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
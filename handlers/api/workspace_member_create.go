@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceMemberCreateRequest represents the JSON request for adding a user to a workspace,
+// identified by either their email address or their GitHub login
+type WorkspaceMemberCreateRequest struct {
+	Email       string `json:"email"`
+	GitHubLogin string `json:"github_login"`
+	Role        string `json:"role" binding:"required"`
+}
+
+// NewWorkspaceMemberCreateHandler creates a new handler for adding members to a workspace
+func NewWorkspaceMemberCreateHandler(config config.Config) (WorkspaceMemberCreateHandler, error) {
+	return WorkspaceMemberCreateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceMemberCreateHandler handles the workspace member creation request
+type WorkspaceMemberCreateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceMemberCreateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.PUT("/workspaces/:workspaceId/members", h.handler)
+}
+
+// handler handles the PUT request for adding an existing user to a workspace
+func (h WorkspaceMemberCreateHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req WorkspaceMemberCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	email := database.NormalizeInvitee(req.Email)
+	githubLogin := database.NormalizeInvitee(req.GitHubLogin)
+	if (email == "") == (githubLogin == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Either an email or a GitHub login is required",
+		})
+		return
+	}
+
+	if !database.ValidWorkspaceRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role must be one of owner, admin or member",
+		})
+		return
+	}
+	if !current.CanAssign(req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can add owners",
+		})
+		return
+	}
+
+	var userID uint
+	var err error
+	if githubLogin != "" {
+		var githubAuth database.GitHubAuth
+		err = h.Database.Where("LOWER(login) = ?", githubLogin).First(&githubAuth).Error
+		userID = githubAuth.UserID
+	} else {
+		var user database.User
+		err = h.Database.Where("LOWER(email) = ?", email).First(&user).Error
+		userID = user.ID
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found, they must sign in once before being added to a workspace",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch user",
+		})
+		return
+	}
+
+	var existing int64
+	if err := h.Database.Model(&database.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", current.WorkspaceID, userID).Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch members",
+		})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This person is already a member of the workspace",
+		})
+		return
+	}
+
+	member := database.WorkspaceMember{
+		WorkspaceID: current.WorkspaceID,
+		UserID:      userID,
+		Role:        req.Role,
+	}
+	if err := h.Database.Create(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to add member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newWorkspaceMemberResponse(h.Database, member))
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NewWorkspaceMemberDeleteHandler creates a new handler for removing members from a workspace
+func NewWorkspaceMemberDeleteHandler(config config.Config) (WorkspaceMemberDeleteHandler, error) {
+	return WorkspaceMemberDeleteHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceMemberDeleteHandler handles the workspace member deletion request
+type WorkspaceMemberDeleteHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceMemberDeleteHandler) GroupRegister(r *gin.RouterGroup) {
+	r.DELETE("/workspaces/:workspaceId/members/:memberId", h.handler)
+}
+
+// handler handles the DELETE request for removing a member from a workspace
+func (h WorkspaceMemberDeleteHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var member database.WorkspaceMember
+	if err := h.Database.Where("id = ? AND workspace_id = ?", c.Param("memberId"), current.WorkspaceID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Member not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch member",
+		})
+		return
+	}
+
+	if !current.CanAssign(member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can remove owners",
+		})
+		return
+	}
+
+	if member.Role == database.WorkspaceRoleOwner {
+		owners, err := database.CountWorkspaceOwners(h.Database, current.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch members",
+			})
+			return
+		}
+		if owners <= 1 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "A workspace must keep at least one owner",
+			})
+			return
+		}
+	}
+
+	// members are removed for good so that the user can be added again
+	if err := h.Database.Unscoped().Delete(&member).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove member",
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceMemberUpdateRequest represents the JSON request for changing the role of a workspace member
+type WorkspaceMemberUpdateRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// NewWorkspaceMemberUpdateHandler creates a new handler for changing the role of a workspace member
+func NewWorkspaceMemberUpdateHandler(config config.Config) (WorkspaceMemberUpdateHandler, error) {
+	return WorkspaceMemberUpdateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceMemberUpdateHandler handles the workspace member update request
+type WorkspaceMemberUpdateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceMemberUpdateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/workspaces/:workspaceId/members/:memberId", h.handler)
+	r.OPTIONS("/workspaces/:workspaceId/members/:memberId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for changing the role of a workspace member
+func (h WorkspaceMemberUpdateHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var req WorkspaceMemberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	if !database.ValidWorkspaceRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Role must be one of owner, admin or member",
+		})
+		return
+	}
+
+	var member database.WorkspaceMember
+	if err := h.Database.Where("id = ? AND workspace_id = ?", c.Param("memberId"), current.WorkspaceID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Member not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch member",
+		})
+		return
+	}
+
+	// only owners can change the role of an owner or make someone an owner
+	if !current.CanAssign(req.Role) || !current.CanAssign(member.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only owners can change owners",
+		})
+		return
+	}
+
+	if member.Role == database.WorkspaceRoleOwner && req.Role != database.WorkspaceRoleOwner {
+		owners, err := database.CountWorkspaceOwners(h.Database, current.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch members",
+			})
+			return
+		}
+		if owners <= 1 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "A workspace must keep at least one owner",
+			})
+			return
+		}
+	}
+
+	// a member whose role was changed by hand is no longer removed when they leave the GitHub organization
+	err := h.Database.Model(&member).Updates(map[string]interface{}{"role": req.Role, "synced": false}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update member",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newWorkspaceMemberResponse(h.Database, member))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceMemberResponse represents a member of a workspace in the JSON response
+type WorkspaceMemberResponse struct {
+	ID          uint   `json:"id"`
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	GitHubLogin string `json:"github_login,omitempty"`
+	Role        string `json:"role"`
+	Synced      bool   `json:"synced"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// newWorkspaceMemberResponse converts a member of a workspace to its response format
+func newWorkspaceMemberResponse(db *gorm.DB, member database.WorkspaceMember) WorkspaceMemberResponse {
+	response := WorkspaceMemberResponse{
+		ID:        member.ID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		Synced:    member.Synced,
+		CreatedAt: member.CreatedAt.Format(time.RFC3339),
+	}
+
+	var user database.User
+	if err := db.First(&user, member.UserID).Error; err == nil {
+		response.Name = user.Name
+		response.Email = user.Email
+	}
+	var githubAuth database.GitHubAuth
+	if err := db.Where("user_id = ?", member.UserID).First(&githubAuth).Error; err == nil {
+		response.GitHubLogin = githubAuth.Login
+	}
+	return response
+}
+
+// NewWorkspaceMembersHandler creates a new handler for listing the members of a workspace
+func NewWorkspaceMembersHandler(config config.Config) (WorkspaceMembersHandler, error) {
+	return WorkspaceMembersHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceMembersHandler handles the workspace members request
+type WorkspaceMembersHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceMembersHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/workspaces/:workspaceId/members", h.handler)
+	r.OPTIONS("/workspaces/:workspaceId/members", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the members of a workspace
+func (h WorkspaceMembersHandler) handler(c *gin.Context) {
+	current, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var members []database.WorkspaceMember
+	if err := h.Database.Where("workspace_id = ?", current.WorkspaceID).Order("created_at").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch members",
+		})
+		return
+	}
+
+	response := make([]WorkspaceMemberResponse, len(members))
+	for i, member := range members {
+		response[i] = newWorkspaceMemberResponse(h.Database, member)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceMoveRequest represents the JSON request for moving sites and templates into a workspace.
+// All moves every site the user owns and every personal template of the user that are not in a workspace yet.
+type WorkspaceMoveRequest struct {
+	SiteIDs     []uint `json:"site_ids"`
+	TemplateIDs []uint `json:"template_ids"`
+	All         bool   `json:"all"`
+}
+
+// WorkspaceMoveResponse represents the number of records moved into a workspace
+type WorkspaceMoveResponse struct {
+	Sites     int `json:"sites"`
+	Templates int `json:"templates"`
+}
+
+// NewWorkspaceMoveHandler creates a new handler for moving records into a workspace
+func NewWorkspaceMoveHandler(config config.Config) (WorkspaceMoveHandler, error) {
+	return WorkspaceMoveHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceMoveHandler handles the workspace move request
+type WorkspaceMoveHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceMoveHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/workspaces/:workspaceId/move", h.handler)
+	r.OPTIONS("/workspaces/:workspaceId/move", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for moving sites and templates into a workspace. Only owners of
+// a site can move it, templates must be personal templates of the user or belong to a workspace they manage.
+func (h WorkspaceMoveHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	member, _ := middleware.GetWorkspaceMember(c)
+
+	var req WorkspaceMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	siteIDs := req.SiteIDs
+	templateIDs := req.TemplateIDs
+	if req.All {
+		err := h.Database.Model(&database.SiteMember{}).
+			Where("user_id = ? AND role = ?", user.ID, database.RoleOwner).
+			Where("site_id IN (?)", h.Database.Model(&database.Site{}).Select("id").Where("workspace_id IS NULL")).
+			Pluck("site_id", &siteIDs).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch sites",
+			})
+			return
+		}
+		err = h.Database.Model(&database.Template{}).
+			Where("user_id = ? AND workspace_id IS NULL", user.ID).
+			Pluck("id", &templateIDs).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch templates",
+			})
+			return
+		}
+	}
+
+	for _, siteID := range siteIDs {
+		siteMember, err := database.GetSiteMember(h.Database, fmt.Sprint(siteID), user.ID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("Site %d not found", siteID),
+			})
+			return
+		}
+		if siteMember.Role != database.RoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": fmt.Sprintf("Only owners can move site %d", siteID),
+			})
+			return
+		}
+	}
+
+	var editable int64
+	if len(templateIDs) > 0 {
+		err := database.EditableTemplates(h.Database.Model(&database.Template{}), user.ID).
+			Where("id IN ?", templateIDs).
+			Count(&editable).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch templates",
+			})
+			return
+		}
+	}
+	if int(editable) != len(templateIDs) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Template not found",
+		})
+		return
+	}
+
+	err := h.Database.Transaction(func(tx *gorm.DB) error {
+		if len(siteIDs) > 0 {
+			if err := tx.Model(&database.Site{}).Where("id IN ?", siteIDs).Update("workspace_id", member.WorkspaceID).Error; err != nil {
+				return err
+			}
+		}
+		if len(templateIDs) > 0 {
+			if err := tx.Model(&database.Template{}).Where("id IN ?", templateIDs).Update("workspace_id", member.WorkspaceID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to move records into the workspace",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WorkspaceMoveResponse{
+		Sites:     len(siteIDs),
+		Templates: len(templateIDs),
+	})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"static-admin/orgsync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// NewWorkspaceSyncHandler creates a new handler for syncing the members of a workspace with its GitHub organization
+func NewWorkspaceSyncHandler(config config.Config) (WorkspaceSyncHandler, error) {
+	return WorkspaceSyncHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceSyncHandler handles the workspace sync request
+type WorkspaceSyncHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceSyncHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/workspaces/:workspaceId/sync", h.handler)
+	r.OPTIONS("/workspaces/:workspaceId/sync", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the POST request for syncing the members of a workspace right away
+func (h WorkspaceSyncHandler) handler(c *gin.Context) {
+	member, exists := middleware.GetWorkspaceMember(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var workspace database.Workspace
+	if err := h.Database.First(&workspace, member.WorkspaceID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch workspace",
+		})
+		return
+	}
+
+	if workspace.GitHubOrganization == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Workspace is not linked to a GitHub organization",
+		})
+		return
+	}
+
+	result, err := orgsync.Sync(c.Request.Context(), h.Database, workspace)
+	if err != nil {
+		glog.Errorf("Failed to sync workspace %d with GitHub organization %s: %v", workspace.ID, workspace.GitHubOrganization, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to sync members with the GitHub organization",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
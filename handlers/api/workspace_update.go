@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/github"
+	"static-admin/middleware"
+	"static-admin/orgsync"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// WorkspaceUpdateRequest represents the JSON request for updating the settings of a workspace
+type WorkspaceUpdateRequest struct {
+	Name            string `json:"name"`
+	DefaultSiteRole string `json:"default_site_role"`
+
+	// MediaDirectory and GitHubOrganization are pointers so they can be cleared with an empty string
+	MediaDirectory     *string `json:"media_directory"`
+	GitHubOrganization *string `json:"github_organization"`
+}
+
+// NewWorkspaceUpdateHandler creates a new handler for workspace updates
+func NewWorkspaceUpdateHandler(config config.Config) (WorkspaceUpdateHandler, error) {
+	return WorkspaceUpdateHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspaceUpdateHandler handles the workspace update request
+type WorkspaceUpdateHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspaceUpdateHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/workspaces/:workspaceId", h.handler)
+}
+
+// handler handles the POST request for workspace updates
+func (h WorkspaceUpdateHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+	member, _ := middleware.GetWorkspaceMember(c)
+
+	var req WorkspaceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+		})
+		return
+	}
+
+	var workspace database.Workspace
+	if err := h.Database.First(&workspace, member.WorkspaceID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch workspace",
+		})
+		return
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		workspace.Name = name
+	}
+
+	if req.DefaultSiteRole != "" {
+		if !database.ValidRole(req.DefaultSiteRole) || req.DefaultSiteRole == database.RoleOwner {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Default site role must be one of admin, editor, author or viewer",
+			})
+			return
+		}
+		workspace.DefaultSiteRole = req.DefaultSiteRole
+	}
+
+	if req.MediaDirectory != nil {
+		mediaDirectory, ok := database.NormalizeRootPath(*req.MediaDirectory)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid media directory",
+			})
+			return
+		}
+		workspace.MediaDirectory = mediaDirectory
+	}
+
+	linked := false
+	if req.GitHubOrganization != nil {
+		organization := database.NormalizeInvitee(*req.GitHubOrganization)
+		if organization != "" && organization != strings.ToLower(workspace.GitHubOrganization) {
+			// the organization must be visible to the user linking it, whose token is used for syncing
+			token, err := database.GetProviderToken(h.Database, user.ID, "github")
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			if _, err := github.ListOrganizationMembers(c.Request.Context(), organization, token); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to fetch the members of the GitHub organization",
+				})
+				return
+			}
+			workspace.LinkedByID = user.ID
+			linked = true
+		}
+		if organization == "" {
+			workspace.LinkedByID = 0
+			workspace.GitHubSyncedAt = nil
+		}
+		workspace.GitHubOrganization = organization
+	}
+
+	if err := h.Database.Save(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update workspace",
+		})
+		return
+	}
+
+	// members of a newly linked organization are added right away instead of on the next periodic sync
+	if linked {
+		if _, err := orgsync.Sync(c.Request.Context(), h.Database, workspace); err != nil {
+			glog.Errorf("Failed to sync workspace %d with GitHub organization %s: %v", workspace.ID, workspace.GitHubOrganization, err)
+		}
+		h.Database.First(&workspace, workspace.ID)
+	}
+
+	c.JSON(http.StatusOK, newWorkspaceResponse(workspace, member.Role))
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceResponse represents a workspace in the JSON response
+type WorkspaceResponse struct {
+	ID                 uint   `json:"id"`
+	Name               string `json:"name"`
+	GitHubOrganization string `json:"github_organization"`
+	GitHubSyncedAt     string `json:"github_synced_at,omitempty"`
+	MediaDirectory     string `json:"media_directory"`
+	DefaultSiteRole    string `json:"default_site_role"`
+	CreatedAt          string `json:"created_at"`
+
+	// Role is the role of the current user in the workspace
+	Role string `json:"role"`
+}
+
+// newWorkspaceResponse converts a workspace to its response format
+func newWorkspaceResponse(workspace database.Workspace, role string) WorkspaceResponse {
+	response := WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		GitHubOrganization: workspace.GitHubOrganization,
+		MediaDirectory:     workspace.MediaDirectory,
+		DefaultSiteRole:    workspace.DefaultSiteRole,
+		CreatedAt:          workspace.CreatedAt.Format(time.RFC3339),
+		Role:               role,
+	}
+	if workspace.GitHubSyncedAt != nil {
+		response.GitHubSyncedAt = workspace.GitHubSyncedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// NewWorkspacesHandler creates a new handler for the workspaces endpoint
+func NewWorkspacesHandler(config config.Config) (WorkspacesHandler, error) {
+	return WorkspacesHandler{
+		Database:  config.Database,
+		JWTSecret: []byte(config.JWTSecret),
+	}, nil
+}
+
+// WorkspacesHandler handles the workspaces request
+type WorkspacesHandler struct {
+	Database  *gorm.DB
+	JWTSecret []byte
+}
+
+// GroupRegister registers the handler with the given router group
+func (h WorkspacesHandler) GroupRegister(r *gin.RouterGroup) {
+	r.GET("/workspaces", h.handler)
+	r.OPTIONS("/workspaces", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handler handles the GET request for the workspaces of the current user
+func (h WorkspacesHandler) handler(c *gin.Context) {
+	user, exists := middleware.GetUser(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+		return
+	}
+
+	var members []database.WorkspaceMember
+	if err := h.Database.Where("user_id = ?", user.ID).Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch workspaces",
+		})
+		return
+	}
+	roles := make(map[uint]string, len(members))
+	workspaceIDs := make([]uint, len(members))
+	for i, member := range members {
+		roles[member.WorkspaceID] = member.Role
+		workspaceIDs[i] = member.WorkspaceID
+	}
+
+	var workspaces []database.Workspace
+	if err := h.Database.Where("id IN ?", workspaceIDs).Order("name").Find(&workspaces).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch workspaces",
+		})
+		return
+	}
+
+	response := make([]WorkspaceResponse, len(workspaces))
+	for i, workspace := range workspaces {
+		response[i] = newWorkspaceResponse(workspace, roles[workspace.ID])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
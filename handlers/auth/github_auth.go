@@ -7,6 +7,7 @@ import (
 
 	"static-admin/config"
 	"static-admin/database"
+	github_api "static-admin/github"
 	"static-admin/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -65,7 +66,7 @@ func (h GithubCallbackHandler) handler(c *gin.Context) {
 		return
 	}
 
-	stdctx := context.Background()
+	stdctx := github_api.DefaultClient().OAuth2Context(context.Background())
 	tok, err := middleware.GithubConfig.Exchange(stdctx, c.Query("code"))
 	if err != nil {
 		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("failed to do exchange: %w", err))
@@ -73,6 +74,9 @@ func (h GithubCallbackHandler) handler(c *gin.Context) {
 	}
 
 	client := github.NewClient(middleware.GithubConfig.Client(stdctx, tok))
+	if baseURL, err := github_api.DefaultClient().RESTBaseURL(); err == nil {
+		client.BaseURL = baseURL
+	}
 	user, _, err := client.Users.Get(stdctx, "")
 	if err != nil {
 		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("failed to get user: %w", err))
@@ -95,6 +99,9 @@ func (h GithubCallbackHandler) handler(c *gin.Context) {
 		return
 	}
 
+	// organization access may have changed with the new authorization
+	github_api.InvalidateUser(githubAuth.AccessToken)
+
 	c.Redirect(http.StatusFound, "http://localhost:3000/dashboard?refetch-token=true")
 }
 
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/provider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// NewProviderCallbackHandler creates a new handler for the OAuth callback of GitLab and Gitea
+func NewProviderCallbackHandler(config config.Config) (ProviderCallbackHandler, error) {
+	return ProviderCallbackHandler{
+		JWTSecret: []byte(config.JWTSecret),
+		Database:  config.Database,
+	}, nil
+}
+
+// ProviderCallbackHandler handles the OAuth callback request of a git hosting provider
+type ProviderCallbackHandler struct {
+	JWTSecret []byte
+	Database  *gorm.DB
+}
+
+// GroupRegister registers the handler with the given router
+func (h ProviderCallbackHandler) GroupRegister(auth *gin.RouterGroup) {
+	auth.GET("/auth/providers/:provider/callback", h.handler)
+}
+
+// handler handles the request for the page
+func (h ProviderCallbackHandler) handler(c *gin.Context) {
+	name := c.Param("provider")
+	if name == provider.GitHub {
+		// GitHub logins are stored with the GitHub callback
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	p, err := provider.Get(name)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+
+	// the state is the JWT token of the user that started the login
+	tokenString := c.Query("state")
+	if tokenString == "" {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return h.JWTSecret, nil
+	})
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		_ = c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("invalid token claims"))
+		return
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		_ = c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("invalid user_id in token"))
+		return
+	}
+
+	account, err := p.Exchange(c.Request.Context(), c.Query("code"))
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	// Update or create the provider auth record in database
+	providerAuth := database.ProviderAuth{
+		UserID:   uint(userID),
+		Provider: name,
+	}
+	result := h.Database.Where("user_id = ? AND provider = ?", uint(userID), name).
+		Assign(database.ProviderAuth{
+			Login:       account.Login,
+			Name:        account.Name,
+			URL:         account.URL,
+			AccessToken: account.AccessToken,
+		}).
+		FirstOrCreate(&providerAuth)
+	if result.Error != nil {
+		glog.Errorf("Failed to save %s auth: %v", name, result.Error)
+		_ = c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("failed to save auth data"))
+		return
+	}
+
+	c.Redirect(http.StatusFound, "http://localhost:3000/dashboard?refetch-token=true")
+}
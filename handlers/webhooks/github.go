@@ -0,0 +1,344 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/deploystatus"
+	"static-admin/github"
+	"static-admin/repoindex"
+	"static-admin/siteconfig"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"gorm.io/gorm"
+)
+
+// maxPayloadSize is the largest webhook payload GitHub delivers
+const maxPayloadSize = 25 << 20
+
+// repositoryPayload represents the repository included in every webhook payload
+type repositoryPayload struct {
+	Name          string `json:"name"`
+	HtmlURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	Private       bool   `json:"private"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// pushPayload represents the payload of a push event
+type pushPayload struct {
+	Ref        string            `json:"ref"`
+	Before     string            `json:"before"`
+	After      string            `json:"after"`
+	Repository repositoryPayload `json:"repository"`
+	Commits    []pushCommit      `json:"commits"`
+}
+
+// pushCommit represents the files changed by a commit of a push event
+type pushCommit struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// pullRequestPayload represents the payload of a pull_request event
+type pullRequestPayload struct {
+	Action      string             `json:"action"`
+	Number      int64              `json:"number"`
+	PullRequest github.PullRequest `json:"pull_request"`
+	Repository  repositoryPayload  `json:"repository"`
+}
+
+// commitStatusPayload represents the payload of a status event
+type commitStatusPayload struct {
+	SHA      string `json:"sha"`
+	Branches []struct {
+		Name string `json:"name"`
+	} `json:"branches"`
+}
+
+// checkPayload represents the payload of a check_run or check_suite event, which carry the suite under different keys
+type checkPayload struct {
+	CheckRun struct {
+		HeadSHA    string `json:"head_sha"`
+		CheckSuite struct {
+			HeadBranch string `json:"head_branch"`
+		} `json:"check_suite"`
+	} `json:"check_run"`
+	CheckSuite struct {
+		HeadSHA    string `json:"head_sha"`
+		HeadBranch string `json:"head_branch"`
+	} `json:"check_suite"`
+}
+
+// deploymentPayload represents the payload of a deployment or deployment_status event
+type deploymentPayload struct {
+	Deployment struct {
+		SHA string `json:"sha"`
+		Ref string `json:"ref"`
+	} `json:"deployment"`
+}
+
+// repositoryEventPayload represents the payload of a repository event
+type repositoryEventPayload struct {
+	Action     string            `json:"action"`
+	Repository repositoryPayload `json:"repository"`
+}
+
+// NewGithubWebhookHandler creates a new handler for GitHub webhook deliveries
+func NewGithubWebhookHandler(config config.Config) (GithubWebhookHandler, error) {
+	return GithubWebhookHandler{
+		Database: config.Database,
+	}, nil
+}
+
+// GithubWebhookHandler handles webhook deliveries from GitHub
+type GithubWebhookHandler struct {
+	Database *gorm.DB
+}
+
+// GroupRegister registers the handler with the given router group
+func (h GithubWebhookHandler) GroupRegister(r *gin.RouterGroup) {
+	r.POST("/webhooks/github/:siteId", h.handler)
+}
+
+// handler handles the POST request for a webhook delivery
+func (h GithubWebhookHandler) handler(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPayloadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to read payload",
+		})
+		return
+	}
+
+	var site database.Site
+	if err := h.Database.Where("id = ?", c.Param("siteId")).First(&site).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Site not found",
+		})
+		return
+	}
+
+	if !validSignature(site.WebhookSecret, c.GetHeader("X-Hub-Signature-256"), body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid signature",
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := h.Database.Model(&site).Update("webhook_received_at", now).Error; err != nil {
+		glog.Errorf("Failed to record webhook delivery for site %d: %v", site.ID, err)
+	}
+
+	switch c.GetHeader("X-GitHub-Event") {
+	case "ping":
+		c.Status(http.StatusOK)
+	case "push":
+		var payload pushPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		h.handlePush(site, payload)
+		c.Status(http.StatusOK)
+	case "pull_request":
+		var payload pullRequestPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		h.handlePullRequest(site, payload)
+		c.Status(http.StatusOK)
+	case "repository":
+		var payload repositoryEventPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		h.handleRepository(site, payload)
+		c.Status(http.StatusOK)
+	case "status":
+		var payload commitStatusPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		deploystatus.InvalidateCommit(h.Database, site.ID, payload.SHA)
+		for _, branch := range payload.Branches {
+			deploystatus.Invalidate(h.Database, site.ID, branch.Name)
+		}
+		c.Status(http.StatusOK)
+	case "check_run", "check_suite":
+		var payload checkPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		for _, sha := range []string{payload.CheckRun.HeadSHA, payload.CheckSuite.HeadSHA} {
+			if sha != "" {
+				deploystatus.InvalidateCommit(h.Database, site.ID, sha)
+			}
+		}
+		for _, branch := range []string{payload.CheckRun.CheckSuite.HeadBranch, payload.CheckSuite.HeadBranch} {
+			if branch != "" {
+				deploystatus.Invalidate(h.Database, site.ID, branch)
+			}
+		}
+		c.Status(http.StatusOK)
+	case "deployment", "deployment_status":
+		var payload deploymentPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid payload",
+			})
+			return
+		}
+		// deployments are looked up by branch, and a deployment of an older commit still changes what is live
+		deploystatus.Invalidate(h.Database, site.ID, payload.Deployment.Ref)
+		c.Status(http.StatusOK)
+	default:
+		// other events are acknowledged so GitHub does not report failed deliveries
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// handlePush invalidates cached repository contents, and the index when the default branch moved. Pushes
+// changing the configuration of the site on its branch reload the configuration in the background.
+func (h GithubWebhookHandler) handlePush(site database.Site, payload pushPayload) {
+	github.InvalidateRepository(payload.Repository.Owner.Login, payload.Repository.Name)
+	deploystatus.Invalidate(h.Database, site.ID, strings.TrimPrefix(payload.Ref, "refs/heads/"))
+
+	if payload.Ref != "refs/heads/"+site.Branch() {
+		return
+	}
+	repoindex.Invalidate(h.Database, site.ID)
+
+	if changesConfig(site, payload.Commits) {
+		go h.syncConfig(site)
+	}
+}
+
+// changesConfig returns true if commits of a push touch the configuration directory of a site
+func changesConfig(site database.Site, commits []pushCommit) bool {
+	prefix := site.RepositoryPath(siteconfig.Directory) + "/"
+	for _, commit := range commits {
+		for _, files := range [][]string{commit.Added, commit.Removed, commit.Modified} {
+			for _, file := range files {
+				if strings.HasPrefix(file, prefix) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// syncConfig reloads the configuration of a site with the token of the user who registered it
+func (h GithubWebhookHandler) syncConfig(site database.Site) {
+	token, err := database.GetProviderToken(h.Database, site.UserID, site.Provider)
+	if err != nil {
+		glog.Errorf("Failed to fetch token to sync the configuration of site %d: %v", site.ID, err)
+		return
+	}
+	if err := siteconfig.Sync(context.Background(), h.Database, &site, token); err != nil {
+		glog.Errorf("Failed to sync the configuration of site %d: %v", site.ID, err)
+	}
+}
+
+// handlePullRequest keeps scheduled merges in sync with pull requests closed outside the admin
+func (h GithubWebhookHandler) handlePullRequest(site database.Site, payload pullRequestPayload) {
+	deploystatus.Invalidate(h.Database, site.ID, payload.PullRequest.Head.Ref)
+
+	if payload.Action != "closed" || !github.IsStaticAdminBranch(payload.PullRequest.Head.Ref) {
+		return
+	}
+
+	if payload.PullRequest.Merged {
+		repoindex.Invalidate(h.Database, site.ID)
+	}
+
+	update := map[string]interface{}{
+		"status":     database.ScheduleStatusCompleted,
+		"last_error": "",
+	}
+	if !payload.PullRequest.Merged {
+		update["status"] = database.ScheduleStatusFailed
+		update["last_error"] = "Pull request was closed without merging"
+	}
+
+	err := h.Database.Model(&database.ScheduledPublication{}).
+		Where("site_id = ? AND pull_request_number = ? AND action = ? AND status = ?",
+			site.ID, payload.Number, database.ScheduleActionMergePullRequest, database.ScheduleStatusPending).
+		Updates(update).Error
+	if err != nil {
+		glog.Errorf("Failed to update schedules for pull request %d on site %d: %v", payload.Number, site.ID, err)
+	}
+}
+
+// handleRepository applies repository renames, transfers and setting changes to the site
+func (h GithubWebhookHandler) handleRepository(site database.Site, payload repositoryEventPayload) {
+	owner, repo, _ := site.OwnerAndRepo()
+	github.InvalidateRepository(owner, repo)
+	github.InvalidateRepository(payload.Repository.Owner.Login, payload.Repository.Name)
+
+	update := map[string]interface{}{}
+	switch payload.Action {
+	case "renamed", "transferred":
+		if payload.Repository.HtmlURL != "" {
+			update["repository_url"] = payload.Repository.HtmlURL
+		}
+	case "privatized", "publicized":
+		update["private"] = payload.Repository.Private
+	case "edited":
+		if payload.Repository.DefaultBranch != "" && payload.Repository.DefaultBranch != site.DefaultBranch {
+			update["default_branch"] = payload.Repository.DefaultBranch
+			repoindex.Invalidate(h.Database, site.ID)
+		}
+	}
+
+	if len(update) == 0 {
+		return
+	}
+	if err := h.Database.Model(&site).Updates(update).Error; err != nil {
+		glog.Errorf("Failed to update site %d from repository event: %v", site.ID, err)
+	}
+}
+
+// validSignature verifies the X-Hub-Signature-256 header against the payload
+func validSignature(secret, signature string, body []byte) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// sign computes the X-Hub-Signature-256 header GitHub sends for a payload
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main","repository":{"name":"site"}}`)
+	valid := sign("secret", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		body      []byte
+		want      bool
+	}{
+		{name: "valid signature", secret: "secret", signature: valid, body: body, want: true},
+		{name: "valid signature of an empty body", secret: "secret", signature: sign("secret", nil), body: nil, want: true},
+		{name: "wrong secret", secret: "other", signature: valid, body: body},
+		{name: "empty secret", secret: "", signature: sign("", body), body: body},
+		{name: "missing signature", secret: "secret", signature: "", body: body},
+		{name: "missing sha256 prefix", secret: "secret", signature: strings.TrimPrefix(valid, "sha256="), body: body},
+		{name: "sha1 signature", secret: "secret", signature: "sha1=" + strings.TrimPrefix(valid, "sha256="), body: body},
+		{name: "bad hex", secret: "secret", signature: "sha256=" + strings.Repeat("zz", sha256.Size), body: body},
+		{name: "truncated signature", secret: "secret", signature: valid[:len(valid)-2], body: body},
+		{name: "uppercase hex", secret: "secret", signature: "sha256=" + strings.ToUpper(strings.TrimPrefix(valid, "sha256=")), body: body, want: true},
+		{name: "tampered body", secret: "secret", signature: valid, body: []byte(`{"ref":"refs/heads/evil","repository":{"name":"site"}}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.secret, tt.signature, tt.body); got != tt.want {
+				t.Errorf("validSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"static-admin/cache"
 	"static-admin/config"
 	"static-admin/database"
 	"static-admin/embedded_box/frontend"
@@ -19,7 +20,12 @@ import (
 	"static-admin/handlers"
 	api_handlers "static-admin/handlers/api"
 	auth_handlers "static-admin/handlers/auth"
+	webhook_handlers "static-admin/handlers/webhooks"
 	"static-admin/middleware"
+	"static-admin/orgsync"
+	"static-admin/previewbuild"
+	"static-admin/provider"
+	"static-admin/scheduler"
 
 	"github.com/foolin/goview"
 	"github.com/foolin/goview/supports/ginview"
@@ -93,13 +99,45 @@ func main() {
 		_ = dbInstance.Close()
 	}()
 
-	// Create a quit channel to signal the cache cleaner
+	config := config.NewConfig(db, staticFiles)
+	github.SetDefaultClient(github.NewClient(
+		github.WithBaseURL(config.GithubAPIURL),
+		github.WithTimeout(config.GithubTimeout),
+	))
+
+	if config.CacheBackend == "database" {
+		github.SetCache(cache.NewDatabase(db, config.CacheMaxEntries, config.CacheTTLs))
+	} else {
+		github.SetCache(cache.NewMemory(config.CacheMaxEntries, config.CacheTTLs))
+	}
+
+	// Create a quit channel to signal the cache cleaner, scheduler and organization sync
 	quit := make(chan struct{})
-	github.StartCacheCleaner(quit)
+	cache.StartCleaner(github.Cache(), quit)
+	scheduler.Start(db, quit)
+	orgsync.Start(db, quit)
+
+	if config.PreviewBuildsDir != "" {
+		builder := previewbuild.New(config)
+		builder.Start(quit)
+		previewbuild.SetDefault(builder)
+	}
 
-	config := config.NewConfig(db, staticFiles)
 	middleware.Github(config)
 
+	if config.GithubClientID != "" {
+		provider.Register(provider.NewGitHub(config))
+	}
+	if config.GitLabClientID != "" {
+		provider.Register(provider.NewGitLab(config))
+	}
+	if config.GiteaClientID != "" {
+		provider.Register(provider.NewGitea(config))
+	}
+	if config.LocalSitesRoot != "" {
+		provider.Register(provider.NewLocal(config))
+	}
+
 	r := gin.Default()
 
 	r.SetHTMLTemplate(template.Must(template.ParseFS(staticFiles, "assets/*.html")))
@@ -116,7 +154,7 @@ func main() {
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Next-Cursor"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -129,6 +167,12 @@ func main() {
 			"/api/register",
 		},
 	}))
+	apiUnauthenticated.Use(middleware.Site(middleware.SiteMiddleware{
+		Database: db,
+	}))
+	apiUnauthenticated.Use(middleware.Workspace(middleware.WorkspaceMiddleware{
+		Database: db,
+	}))
 
 	auth := r.Group("/")
 	// auth.Use(middleware.Auth(db, []byte(config.JWTSecret)))
@@ -136,20 +180,69 @@ func main() {
 	registry := &Registry{Engine: r, AuthGroup: auth, ApiGroup: apiUnauthenticated}
 
 	registry.AuthRegister(auth_handlers.NewGithubCallbackHandler(config))
+	registry.AuthRegister(auth_handlers.NewProviderCallbackHandler(config))
+	registry.AuthRegister(webhook_handlers.NewGithubWebhookHandler(config))
+	registry.AuthRegister(api_handlers.NewPreviewAssetHandler(config))
+	registry.AuthRegister(api_handlers.NewPreviewSiteHandler(config))
 	registry.ApiRegister(api_handlers.NewLoginHandler(config))
 	registry.ApiRegister(api_handlers.NewCreateAccountHandler(config))
 	registry.ApiRegister(api_handlers.NewGitHubAuthURLHandler(config))
+	registry.ApiRegister(api_handlers.NewProviderAuthURLHandler(config))
 	registry.ApiRegister(api_handlers.NewRevalidateHandler(config))
 	registry.ApiRegister(api_handlers.NewGitHubRepositoriesHandler(config))
 	registry.ApiRegister(api_handlers.NewGitHubOrganizationsHandler(config))
+	registry.ApiRegister(api_handlers.NewProviderRepositoriesHandler(config))
+	registry.ApiRegister(api_handlers.NewGitHubRateLimitHandler(config))
+	registry.ApiRegister(api_handlers.NewCacheStatsHandler(config))
 	registry.ApiRegister(api_handlers.NewSitesHandler(config))
 	registry.ApiRegister(api_handlers.NewSiteCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteUpdateHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteWebhookHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteConfigSyncHandler(config))
 	registry.ApiRegister(api_handlers.NewSiteDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteMembersHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteMemberUpdateHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteMemberDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteInvitationsHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteInvitationCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewSiteInvitationDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewInvitationsHandler(config))
+	registry.ApiRegister(api_handlers.NewInvitationResponseHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspacesHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceUpdateHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceMembersHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceMemberCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceMemberUpdateHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceMemberDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceSyncHandler(config))
+	registry.ApiRegister(api_handlers.NewWorkspaceMoveHandler(config))
 	registry.ApiRegister(api_handlers.NewPostsHandler(config))
 	registry.ApiRegister(api_handlers.NewPostHandler(config))
+	registry.ApiRegister(api_handlers.NewMediaHandler(config))
 	registry.ApiRegister(api_handlers.NewPostSaveHandler(config))
+	registry.ApiRegister(api_handlers.NewPostStatusHandler(config))
+	registry.ApiRegister(api_handlers.NewPostRevisionsHandler(config))
+	registry.ApiRegister(api_handlers.NewPostRevisionHandler(config))
+	registry.ApiRegister(api_handlers.NewPostRevisionDiffHandler(config))
+	registry.ApiRegister(api_handlers.NewPostRevisionRestoreHandler(config))
+	registry.ApiRegister(api_handlers.NewPostPreviewHandler(config))
+	registry.ApiRegister(api_handlers.NewPreviewBuildsHandler(config))
+	registry.ApiRegister(api_handlers.NewPreviewBuildCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewPreviewBuildLogHandler(config))
+	registry.ApiRegister(api_handlers.NewSchedulesHandler(config))
+	registry.ApiRegister(api_handlers.NewScheduleCreateHandler(config))
+	registry.ApiRegister(api_handlers.NewScheduleUpdateHandler(config))
+	registry.ApiRegister(api_handlers.NewScheduleDeleteHandler(config))
+	registry.ApiRegister(api_handlers.NewPullRequestsHandler(config))
+	registry.ApiRegister(api_handlers.NewPullRequestMergeHandler(config))
+	registry.ApiRegister(api_handlers.NewPullRequestStateHandler(config))
+	registry.ApiRegister(api_handlers.NewPullRequestReviewHandler(config))
 	registry.ApiRegister(api_handlers.NewTemplatesHandler(config))
 	registry.ApiRegister(api_handlers.NewTemplateHandler(config))
+	registry.ApiRegister(api_handlers.NewTemplateInferHandler(config))
 	registry.ApiRegister(api_handlers.NewTemplateCreateHandler(config))
 	registry.ApiRegister(api_handlers.NewTemplateUpdateHandler(config))
 	registry.ApiRegister(api_handlers.NewTemplateDeleteHandler(config))
@@ -173,7 +266,7 @@ func main() {
 	sig := <-sigChan
 	log.Printf("Received signal: %v. Shutting down...", sig)
 
-	// Signal the cache cleaner to stop
+	// Signal the cache cleaner, scheduler and organization sync to stop
 	close(quit)
 
 	// Gracefully shut down the server
@@ -74,6 +74,37 @@ func ExtractFrontMatter(content []byte) ([]FrontmatterField, string, error) {
 	return fields, markdownContent.String(), nil
 }
 
+// dateLayouts are the date formats accepted in frontmatter, in order of preference
+var dateLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate parses a frontmatter date in any of the formats commonly used by static site generators
+func ParseDate(value string) (time.Time, error) {
+	layout, ok := DateLayout(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unsupported date format: %s", value)
+	}
+	return time.Parse(layout, strings.TrimSpace(value))
+}
+
+// DateLayout returns the layout a frontmatter date is written in, false if it is not a date
+func DateLayout(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return layout, true
+		}
+	}
+	return "", false
+}
+
 func parseFrontmatterFields(frontmatter map[string]interface{}) ([]FrontmatterField, error) {
 	fields := map[string]FrontmatterField{}
 	for key, value := range frontmatter {
@@ -87,7 +118,11 @@ func parseFrontmatterFields(frontmatter map[string]interface{}) ([]FrontmatterFi
 		}
 
 		if field.Name == "date" {
-			date, err := time.Parse("2006-01-02 15:04", value.(string))
+			dateString, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse date: unexpected type %T", value)
+			}
+			date, err := ParseDate(dateString)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse date: %w", err)
 			}
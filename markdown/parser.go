@@ -504,6 +504,15 @@ func handleImageBlock(_ ast.Node, _ string, htmlContent string, config *ParseCon
 	}
 }
 
+// RenderHTML renders a Markdown document to HTML with the same pipeline used for block content
+func RenderHTML(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := MarkdownParser.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 func markdownToHTML(markdown string) (string, error) {
 	var buf bytes.Buffer
 	err := MarkdownParser.Convert([]byte(markdown), &buf)
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"static-admin/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jxskiss/base62"
+	"gorm.io/gorm"
+)
+
+// sitePermissions are the permissions required by site routes, routes not listed here require
+// the read permission for GET requests and the manage permission otherwise
+var sitePermissions = map[string]string{
+	"DELETE /api/sites/:siteId":          database.PermissionDelete,
+	"GET /api/sites/:siteId/invitations": database.PermissionManage,
+
+	"POST /api/sites/:siteId/preview":                              database.PermissionRead,
+	"PUT /api/sites/:siteId/posts":                                 database.PermissionWrite,
+	"POST /api/sites/:siteId/posts/:postId":                        database.PermissionWrite,
+	"POST /api/sites/:siteId/posts/:postId/revisions/:sha/restore": database.PermissionWrite,
+	"POST /api/sites/:siteId/builds":                               database.PermissionWrite,
+
+	"POST /api/sites/:siteId/posts/:postId/publish":           database.PermissionPublish,
+	"POST /api/sites/:siteId/posts/:postId/unpublish":         database.PermissionPublish,
+	"PUT /api/sites/:siteId/schedules":                        database.PermissionPublish,
+	"POST /api/sites/:siteId/schedules/:scheduleId":           database.PermissionPublish,
+	"DELETE /api/sites/:siteId/schedules/:scheduleId":         database.PermissionPublish,
+	"POST /api/sites/:siteId/pull-requests/:number/merge":     database.PermissionPublish,
+	"POST /api/sites/:siteId/pull-requests/:number/close":     database.PermissionPublish,
+	"POST /api/sites/:siteId/pull-requests/:number/reopen":    database.PermissionPublish,
+	"POST /api/sites/:siteId/pull-requests/:number/labels":    database.PermissionPublish,
+	"POST /api/sites/:siteId/pull-requests/:number/reviewers": database.PermissionPublish,
+	"DELETE /api/sites/:siteId/pull-requests/:number/branch":  database.PermissionPublish,
+}
+
+// SiteMiddleware represents the configuration of the site access middleware
+type SiteMiddleware struct {
+	Database *gorm.DB
+}
+
+// Site is a middleware that checks the role of the user on the site of site-scoped routes
+// and sets the membership in the context
+func Site(input SiteMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		siteID := c.Param("siteId")
+		if siteID == "" || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		user, exists := GetUser(c)
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		member, err := database.GetSiteMember(input.Database, siteID, user.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "Site not found",
+			})
+			return
+		}
+
+		permission := requiredPermission(c)
+		if !member.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Your role on this site does not allow this action",
+			})
+			return
+		}
+
+		// authors may only change the posts they created
+		if permission == database.PermissionWrite && member.OwnPostsOnly() && c.Param("postId") != "" {
+			postPath, err := base62.DecodeString(c.Param("postId"))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": "Failed to decode post ID",
+				})
+				return
+			}
+
+			isAuthor, err := database.IsPostAuthor(input.Database, member.SiteID, string(postPath), user.ID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to fetch post author",
+				})
+				return
+			}
+			if !isAuthor {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "Authors can only edit posts they created",
+				})
+				return
+			}
+		}
+
+		c.Set("site_member", member)
+		c.Next()
+	}
+}
+
+// requiredPermission returns the permission needed for the matched route
+func requiredPermission(c *gin.Context) string {
+	if permission, ok := sitePermissions[c.Request.Method+" "+c.FullPath()]; ok {
+		return permission
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return database.PermissionRead
+	}
+	return database.PermissionManage
+}
+
+// GetSiteMember retrieves the membership of the user in the site of the route from gin context
+func GetSiteMember(c *gin.Context) (database.SiteMember, bool) {
+	member, exists := c.Get("site_member")
+	if !exists {
+		return database.SiteMember{}, false
+	}
+	siteMember, ok := member.(database.SiteMember)
+	return siteMember, ok
+}
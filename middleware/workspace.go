@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"static-admin/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceMiddleware represents the configuration of the workspace access middleware
+type WorkspaceMiddleware struct {
+	Database *gorm.DB
+}
+
+// Workspace is a middleware that checks the role of the user in the workspace of workspace-scoped routes
+// and sets the membership in the context. Every member can read a workspace, changing it requires
+// the owner or admin role.
+func Workspace(input WorkspaceMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaceID := c.Param("workspaceId")
+		if workspaceID == "" || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		user, exists := GetUser(c)
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+
+		member, err := database.GetWorkspaceMember(input.Database, workspaceID, user.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "Workspace not found",
+			})
+			return
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		if !readOnly && !member.CanManage() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Your role in this workspace does not allow this action",
+			})
+			return
+		}
+
+		c.Set("workspace_member", member)
+		c.Next()
+	}
+}
+
+// GetWorkspaceMember retrieves the membership of the user in the workspace of the route from gin context
+func GetWorkspaceMember(c *gin.Context) (database.WorkspaceMember, bool) {
+	member, exists := c.Get("workspace_member")
+	if !exists {
+		return database.WorkspaceMember{}, false
+	}
+	workspaceMember, ok := member.(database.WorkspaceMember)
+	return workspaceMember, ok
+}
@@ -0,0 +1,135 @@
+package orgsync
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"static-admin/database"
+	"static-admin/github"
+
+	"gorm.io/gorm"
+)
+
+// syncInterval is how often the members of linked GitHub organizations are synced
+const syncInterval = time.Hour
+
+// Result counts the changes made to the members of a workspace by a sync
+type Result struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Start starts a goroutine that periodically syncs the members of every workspace linked to a GitHub organization
+func Start(db *gorm.DB, quit chan struct{}) {
+	// in-flight GitHub requests are abandoned on shutdown, workspaces are synced again after a restart
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(syncInterval)
+	go func() {
+		syncAll(ctx, db)
+		for {
+			select {
+			case <-ticker.C:
+				syncAll(ctx, db)
+			case <-quit:
+				cancel()
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// syncAll syncs the members of every linked workspace, logging failures
+func syncAll(ctx context.Context, db *gorm.DB) {
+	var workspaces []database.Workspace
+	if err := db.Where("git_hub_organization != ''").Find(&workspaces).Error; err != nil {
+		log.Printf("Failed to fetch linked workspaces: %v", err)
+		return
+	}
+
+	for _, workspace := range workspaces {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := Sync(ctx, db, workspace); err != nil {
+			log.Printf("Failed to sync workspace %d with GitHub organization %s: %v", workspace.ID, workspace.GitHubOrganization, err)
+		}
+	}
+}
+
+// Sync makes the users whose GitHub account is a member of the organization linked to the workspace
+// members of the workspace, and removes the synced members who left the organization. Members added
+// by hand are never removed and keep their role.
+func Sync(ctx context.Context, db *gorm.DB, workspace database.Workspace) (Result, error) {
+	var result Result
+	if workspace.GitHubOrganization == "" {
+		return result, fmt.Errorf("workspace is not linked to a GitHub organization")
+	}
+
+	token, err := database.GetProviderToken(db, workspace.LinkedByID, "github")
+	if err != nil {
+		return result, err
+	}
+
+	orgMembers, err := github.ListOrganizationMembers(ctx, workspace.GitHubOrganization, token)
+	if err != nil {
+		return result, err
+	}
+	logins := make([]string, len(orgMembers))
+	for i, member := range orgMembers {
+		logins[i] = strings.ToLower(member.Login)
+	}
+
+	var accounts []database.GitHubAuth
+	if err := db.Where("LOWER(login) IN ?", logins).Find(&accounts).Error; err != nil {
+		return result, fmt.Errorf("failed to fetch GitHub accounts: %w", err)
+	}
+	inOrganization := make(map[uint]bool, len(accounts))
+	for _, account := range accounts {
+		inOrganization[account.UserID] = true
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var members []database.WorkspaceMember
+		if err := tx.Where("workspace_id = ?", workspace.ID).Find(&members).Error; err != nil {
+			return err
+		}
+
+		existing := make(map[uint]bool, len(members))
+		for _, member := range members {
+			existing[member.UserID] = true
+			if member.Synced && !inOrganization[member.UserID] {
+				if err := tx.Unscoped().Delete(&member).Error; err != nil {
+					return err
+				}
+				result.Removed++
+			}
+		}
+
+		for userID := range inOrganization {
+			if existing[userID] {
+				continue
+			}
+			member := database.WorkspaceMember{
+				WorkspaceID: workspace.ID,
+				UserID:      userID,
+				Role:        database.WorkspaceRoleMember,
+				Synced:      true,
+			}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+			result.Added++
+		}
+
+		return tx.Model(&workspace).Update("git_hub_synced_at", time.Now()).Error
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to update workspace members: %w", err)
+	}
+
+	return result, nil
+}
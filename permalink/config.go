@@ -0,0 +1,142 @@
+package permalink
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"static-admin/generator"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Config represents the permalink settings of a site, read from its generator configuration
+type Config struct {
+	Generator string
+
+	// BaseURL is the absolute URL the site is published under, empty if it is not configured
+	BaseURL string
+
+	// Pattern is the permalink pattern of posts, empty for the generator's default
+	Pattern string
+
+	// PreserveCase keeps the case of Hugo paths, which are lowercased by default
+	PreserveCase bool
+}
+
+// configFiles are the configuration files read for each generator, in order of precedence
+var configFiles = map[string][]string{
+	generator.Jekyll: {"_config.yml", "_config.yaml", "_config.toml"},
+	generator.Hugo:   {"hugo.toml", "hugo.yaml", "hugo.yml", "hugo.json", "config.toml", "config.yaml", "config.yml", "config.json"},
+}
+
+// ConfigFiles returns the configuration files permalink settings are read from, relative to the site root
+func ConfigFiles(generatorName string) []string {
+	return configFiles[generatorName]
+}
+
+// DefaultConfig returns the permalink settings of a site without a configuration file
+func DefaultConfig(generatorName string) Config {
+	config := Config{Generator: generatorName}
+	if generatorName == generator.Jekyll {
+		config.Pattern = "date"
+	}
+	return config
+}
+
+// ParseConfig reads the permalink settings from the contents of a generator configuration file
+func ParseConfig(generatorName, fileName, content string) (Config, error) {
+	config := DefaultConfig(generatorName)
+
+	settings, err := decode(fileName, content)
+	if err != nil {
+		return config, fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+
+	switch generatorName {
+	case generator.Jekyll:
+		config.BaseURL = strings.TrimSuffix(stringSetting(settings, "url"), "/")
+		if baseURL := strings.Trim(stringSetting(settings, "baseurl"), "/"); baseURL != "" && config.BaseURL != "" {
+			config.BaseURL += "/" + baseURL
+		}
+		if pattern := stringSetting(settings, "permalink"); pattern != "" {
+			config.Pattern = pattern
+		}
+		if pattern := stringSetting(setting(setting(settings, "collections"), "posts"), "permalink"); pattern != "" {
+			config.Pattern = pattern
+		}
+	case generator.Hugo:
+		config.BaseURL = strings.TrimSuffix(stringSetting(settings, "baseurl"), "/")
+		permalinks := setting(settings, "permalinks")
+		config.Pattern = stringSetting(permalinks, "posts")
+		if pattern := stringSetting(setting(permalinks, "page"), "posts"); pattern != "" {
+			config.Pattern = pattern
+		}
+		config.PreserveCase, _ = setting(settings, "disablepathtolower").(bool)
+	}
+	return config, nil
+}
+
+// decode parses a YAML, TOML or JSON configuration file into nested maps with string keys
+func decode(fileName, content string) (map[string]interface{}, error) {
+	var settings interface{}
+	var err error
+	switch path.Ext(fileName) {
+	case ".toml":
+		err = toml.Unmarshal([]byte(content), &settings)
+	case ".json":
+		err = json.Unmarshal([]byte(content), &settings)
+	default:
+		err = yaml.Unmarshal([]byte(content), &settings)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, _ := stringKeys(settings).(map[string]interface{})
+	return result, nil
+}
+
+// stringKeys converts the maps produced by the YAML decoder, which may have non-string keys
+func stringKeys(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return result
+	case map[string]interface{}:
+		for key, item := range typed {
+			typed[key] = stringKeys(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// setting looks up a key of a settings map ignoring case, as Hugo configuration keys are case-insensitive
+func setting(settings interface{}, key string) interface{} {
+	values, ok := settings.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if value, ok := values[key]; ok {
+		return value
+	}
+	for name, value := range values {
+		if strings.EqualFold(name, key) {
+			return value
+		}
+	}
+	return nil
+}
+
+// stringSetting looks up a string value of a settings map
+func stringSetting(settings interface{}, key string) string {
+	value, _ := setting(settings, key).(string)
+	return strings.TrimSpace(value)
+}
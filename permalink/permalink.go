@@ -0,0 +1,292 @@
+package permalink
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"static-admin/generator"
+	"static-admin/markdown"
+
+	"github.com/gosimple/slug"
+)
+
+// jekyllStyles are the built-in permalink styles of Jekyll
+var jekyllStyles = map[string]string{
+	"date":     "/:categories/:year/:month/:day/:title:output_ext",
+	"pretty":   "/:categories/:year/:month/:day/:title/",
+	"ordinal":  "/:categories/:year/:y_day/:title:output_ext",
+	"weekdate": "/:categories/:year/W:week/:short_day/:title:output_ext",
+	"none":     "/:categories/:title:output_ext",
+}
+
+// placeholderRegex matches the placeholders of a permalink pattern, such as :year or Hugo's :2006 date layouts
+var placeholderRegex = regexp.MustCompile(`:([a-z_]+|\d+)`)
+
+// datePrefixRegex matches the date prefix of post file names
+var datePrefixRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-`)
+
+// Post represents the data the permalink of a post is computed from
+type Post struct {
+	// Path is the path of the post relative to the site root
+	Path       string
+	Title      string
+	Slug       string
+	Date       time.Time
+	Categories []string
+
+	// Override is the permalink set in the frontmatter of the post, using the permalink field
+	// for Jekyll and Eleventy and the url field for Hugo
+	Override string
+}
+
+// OverrideField returns the frontmatter field a post sets its own permalink with
+func OverrideField(generatorName string) string {
+	if generatorName == generator.Hugo {
+		return "url"
+	}
+	return "permalink"
+}
+
+// FromFrontmatter builds the permalink data of a post from its path and frontmatter
+func FromFrontmatter(generatorName, postPath string, fields []markdown.FrontmatterField) Post {
+	post := Post{Path: postPath}
+	for _, field := range fields {
+		switch field.Name {
+		case "title":
+			post.Title = field.StringValue
+		case "slug":
+			post.Slug = field.StringValue
+		case "date":
+			post.Date = field.DateTimeValue
+		case "categories", "category":
+			if field.Type == "stringSlice" {
+				post.Categories = append(post.Categories, field.StringSliceValue...)
+			} else {
+				post.Categories = append(post.Categories, strings.Fields(field.StringValue)...)
+			}
+		case OverrideField(generatorName):
+			// eleventy uses `permalink: false` for templates that are not written to the site
+			if field.Type == "bool" {
+				post.Override = strconv.FormatBool(field.BoolValue)
+			} else {
+				post.Override = strings.TrimSpace(field.StringValue)
+			}
+		}
+	}
+	return post
+}
+
+// Path computes the path a post is published under, or an empty string when it cannot be determined,
+// such as for generators without permalink conventions or Eleventy permalinks using template syntax
+func (c Config) Path(post Post) string {
+	switch c.Generator {
+	case generator.Jekyll:
+		return c.jekyllPath(post)
+	case generator.Hugo:
+		return c.hugoPath(post)
+	case generator.Eleventy:
+		return eleventyPath(post)
+	default:
+		return ""
+	}
+}
+
+// URL returns the absolute URL of a post, or an empty string when the site URL or the path is unknown
+func (c Config) URL(post Post) string {
+	return Resolve(c.BaseURL, c.Path(post))
+}
+
+// Resolve joins a permalink with the URL a site is published under
+func Resolve(baseURL, permalink string) string {
+	if baseURL == "" || permalink == "" {
+		return ""
+	}
+	if strings.Contains(permalink, "://") {
+		return permalink
+	}
+	return strings.TrimSuffix(baseURL, "/") + permalink
+}
+
+// jekyllPath computes the permalink of a Jekyll post, whose date and title default to the ones in its file name
+func (c Config) jekyllPath(post Post) string {
+	pattern := post.Override
+	if pattern == "" {
+		pattern = c.Pattern
+	}
+	if style, ok := jekyllStyles[pattern]; ok {
+		pattern = style
+	}
+
+	name := strings.TrimSuffix(path.Base(post.Path), path.Ext(post.Path))
+	title := name
+	if match := datePrefixRegex.FindStringSubmatch(name); match != nil {
+		title = strings.TrimPrefix(name, match[0])
+		if post.Date.IsZero() {
+			post.Date, _ = time.Parse("2006-01-02", match[1])
+		}
+	}
+	if post.Slug != "" {
+		title = post.Slug
+	}
+
+	categories := make([]string, 0, len(post.Categories))
+	for _, category := range post.Categories {
+		categories = append(categories, url.PathEscape(strings.ToLower(category)))
+	}
+
+	year, week := post.Date.ISOWeek()
+	values := map[string]string{
+		"year":                 strconv.Itoa(post.Date.Year()),
+		"short_year":           post.Date.Format("06"),
+		"month":                post.Date.Format("01"),
+		"i_month":              strconv.Itoa(int(post.Date.Month())),
+		"short_month":          post.Date.Format("Jan"),
+		"long_month":           post.Date.Format("January"),
+		"day":                  post.Date.Format("02"),
+		"i_day":                strconv.Itoa(post.Date.Day()),
+		"y_day":                fmt.Sprintf("%03d", post.Date.YearDay()),
+		"w_year":               strconv.Itoa(year),
+		"week":                 fmt.Sprintf("%02d", week),
+		"w_day":                strconv.Itoa((int(post.Date.Weekday())+6)%7 + 1),
+		"short_day":            post.Date.Format("Mon"),
+		"long_day":             post.Date.Format("Monday"),
+		"hour":                 post.Date.Format("15"),
+		"minute":               post.Date.Format("04"),
+		"second":               post.Date.Format("05"),
+		"title":                url.PathEscape(title),
+		"slug":                 slug.Make(title),
+		"name":                 url.PathEscape(name),
+		"path":                 strings.TrimSuffix(post.Path, path.Ext(post.Path)),
+		"categories":           strings.Join(categories, "/"),
+		"slugified_categories": slugifiedCategories(post.Categories),
+		"collection":           "posts",
+		"output_ext":           ".html",
+	}
+	return cleanPath(expand(pattern, values, post.Date))
+}
+
+// slugifiedCategories joins the slugified categories of a Jekyll post as a path
+func slugifiedCategories(categories []string) string {
+	slugs := make([]string, 0, len(categories))
+	for _, category := range categories {
+		slugs = append(slugs, slug.Make(category))
+	}
+	return strings.Join(slugs, "/")
+}
+
+// hugoPath computes the permalink of a Hugo post, which follows its content path unless a pattern
+// is configured for its section. Hugo lowercases paths unless disablePathToLower is set.
+func (c Config) hugoPath(post Post) string {
+	contentPath := strings.TrimPrefix(post.Path, "content/")
+	directory := path.Dir(contentPath)
+	fileName := strings.TrimSuffix(path.Base(contentPath), path.Ext(contentPath))
+
+	// page bundles are named after their directory
+	if fileName == "index" {
+		fileName = path.Base(directory)
+		directory = path.Dir(directory)
+	}
+
+	section := strings.Split(contentPath, "/")[0]
+	title := slug.Make(post.Title)
+	slugValue := post.Slug
+	if slugValue == "" {
+		slugValue = title
+	}
+	slugOrFileName := post.Slug
+	if slugOrFileName == "" {
+		slugOrFileName = fileName
+	}
+
+	var result string
+	switch {
+	case post.Override != "":
+		result = post.Override
+	case c.Pattern != "":
+		_, week := post.Date.ISOWeek()
+		values := map[string]string{
+			"year":                  strconv.Itoa(post.Date.Year()),
+			"month":                 post.Date.Format("01"),
+			"monthname":             post.Date.Format("January"),
+			"day":                   post.Date.Format("02"),
+			"weekday":               strconv.Itoa(int(post.Date.Weekday())),
+			"weekdayname":           post.Date.Format("Monday"),
+			"yearday":               strconv.Itoa(post.Date.YearDay()),
+			"week":                  strconv.Itoa(week),
+			"section":               section,
+			"sections":              directory,
+			"title":                 title,
+			"slug":                  url.PathEscape(slugValue),
+			"filename":              url.PathEscape(fileName),
+			"contentbasename":       url.PathEscape(fileName),
+			"slugorfilename":        url.PathEscape(slugOrFileName),
+			"slugorcontentbasename": url.PathEscape(slugOrFileName),
+		}
+		result = expand(c.Pattern, values, post.Date)
+	default:
+		result = "/" + directory + "/" + url.PathEscape(slugOrFileName) + "/"
+	}
+
+	if !c.PreserveCase {
+		result = strings.ToLower(result)
+	}
+	return cleanPath(result)
+}
+
+// eleventyPath computes the permalink of an Eleventy post, which follows its path relative to the
+// input directory unless the post sets a permalink
+func eleventyPath(post Post) string {
+	switch {
+	case post.Override == "false":
+		return ""
+	case strings.Contains(post.Override, "{{") || strings.Contains(post.Override, "{%"):
+		return ""
+	case post.Override != "":
+		return cleanPath(strings.TrimSuffix(post.Override, "index.html"))
+	}
+
+	stem := strings.TrimSuffix(post.Path, path.Ext(post.Path))
+	stem = strings.TrimSuffix(strings.TrimSuffix(stem, "index"), "/")
+	return cleanPath("/" + stem + "/")
+}
+
+// expand replaces the placeholders of a pattern, formatting numeric placeholders as Go date layouts.
+// Unknown placeholders are left as they are.
+func expand(pattern string, values map[string]string, date time.Time) string {
+	return placeholderRegex.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		name := placeholder[1:]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if _, err := strconv.Atoi(name); err == nil {
+			return date.Format(name)
+		}
+		return placeholder
+	})
+}
+
+// cleanPath collapses the empty segments left by missing values and makes the path absolute
+func cleanPath(value string) string {
+	if strings.Contains(value, "://") {
+		return value
+	}
+
+	trailingSlash := strings.HasSuffix(value, "/")
+	segments := []string{}
+	for _, segment := range strings.Split(value, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	result := "/" + strings.Join(segments, "/")
+	if trailingSlash && len(segments) > 0 {
+		result += "/"
+	}
+	return result
+}
@@ -0,0 +1,293 @@
+package permalink
+
+import (
+	"testing"
+	"time"
+
+	"static-admin/generator"
+	"static-admin/markdown"
+)
+
+// postDate is a Tuesday in the 10th ISO week of 2024 and the 65th day of the year
+var postDate = time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+func TestJekyllPath(t *testing.T) {
+	post := Post{
+		Path:       "_posts/2024-03-05-hello-world.md",
+		Title:      "Hello World",
+		Date:       postDate,
+		Categories: []string{"Go", "Web Dev"},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		post    func(Post) Post
+		want    string
+	}{
+		{name: "date style", pattern: "date", want: "/go/web%20dev/2024/03/05/hello-world.html"},
+		{name: "pretty style", pattern: "pretty", want: "/go/web%20dev/2024/03/05/hello-world/"},
+		{name: "ordinal style", pattern: "ordinal", want: "/go/web%20dev/2024/065/hello-world.html"},
+		{name: "weekdate style", pattern: "weekdate", want: "/go/web%20dev/2024/W10/Tue/hello-world.html"},
+		{name: "none style", pattern: "none", want: "/go/web%20dev/hello-world.html"},
+		{
+			name:    "date placeholders",
+			pattern: "/:year/:short_year/:month/:i_month/:short_month/:long_month/:day/:i_day/:y_day/:hour:minute:second/",
+			want:    "/2024/24/03/3/Mar/March/05/5/065/140709/",
+		},
+		{
+			name:    "week placeholders",
+			pattern: "/:w_year/:week/:w_day/:short_day/:long_day/",
+			want:    "/2024/10/2/Tue/Tuesday/",
+		},
+		{
+			name:    "name placeholders",
+			pattern: "/:collection/:name/:title/:slug",
+			want:    "/posts/2024-03-05-hello-world/hello-world/hello-world",
+		},
+		{
+			name:    "path and slugified categories",
+			pattern: "/:slugified_categories/:path:output_ext",
+			want:    "/go/web-dev/_posts/2024-03-05-hello-world.html",
+		},
+		{
+			name:    "slug replaces the file name title",
+			pattern: "/:title/:slug/",
+			post:    func(p Post) Post { p.Slug = "Custom Slug"; return p },
+			want:    "/Custom%20Slug/custom-slug/",
+		},
+		{
+			name:    "date from the file name",
+			pattern: "date",
+			post:    func(p Post) Post { p.Date = time.Time{}; p.Categories = nil; return p },
+			want:    "/2024/03/05/hello-world.html",
+		},
+		{
+			name:    "missing categories leave no empty segments",
+			pattern: "/:categories/:year/:title/",
+			post:    func(p Post) Post { p.Categories = nil; return p },
+			want:    "/2024/hello-world/",
+		},
+		{
+			name:    "override pattern",
+			pattern: "date",
+			post:    func(p Post) Post { p.Override = "/blog/:title/"; return p },
+			want:    "/blog/hello-world/",
+		},
+		{
+			name:    "override style",
+			pattern: "date",
+			post:    func(p Post) Post { p.Override = "none"; return p },
+			want:    "/go/web%20dev/hello-world.html",
+		},
+		{
+			name:    "unknown placeholders are kept",
+			pattern: "/:unknown/:title",
+			want:    "/:unknown/hello-world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := post
+			if tt.post != nil {
+				p = tt.post(p)
+			}
+			config := Config{Generator: generator.Jekyll, Pattern: tt.pattern}
+			if got := config.Path(p); got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHugoPath(t *testing.T) {
+	post := Post{
+		Path:  "content/posts/My-Post.md",
+		Title: "My Great Post",
+		Date:  postDate,
+	}
+
+	tests := []struct {
+		name         string
+		pattern      string
+		preserveCase bool
+		post         func(Post) Post
+		want         string
+	}{
+		{name: "content path", want: "/posts/my-post/"},
+		{name: "content path keeping case", preserveCase: true, want: "/posts/My-Post/"},
+		{
+			name: "page bundle",
+			post: func(p Post) Post { p.Path = "content/posts/2024/bundle/index.md"; return p },
+			want: "/posts/2024/bundle/",
+		},
+		{
+			name: "slug replaces the file name",
+			post: func(p Post) Post { p.Slug = "Custom"; return p },
+			want: "/posts/custom/",
+		},
+		{
+			name:    "date placeholders",
+			pattern: "/:year/:month/:monthname/:day/:weekday/:weekdayname/:yearday/:week/",
+			want:    "/2024/03/march/05/2/tuesday/65/10/",
+		},
+		{
+			name:    "go date layouts",
+			pattern: "/:2006/:01/:02/:title/",
+			want:    "/2024/03/05/my-great-post/",
+		},
+		{
+			name:    "section placeholders",
+			pattern: "/:section/:sections/:filename/",
+			post:    func(p Post) Post { p.Path = "content/posts/2024/note.md"; return p },
+			want:    "/posts/posts/2024/note/",
+		},
+		{
+			name:    "slug defaults to the title",
+			pattern: "/:slug/",
+			want:    "/my-great-post/",
+		},
+		{
+			name:    "slug or file name",
+			pattern: "/:slugorfilename/:slugorcontentbasename/:contentbasename/",
+			post:    func(p Post) Post { p.Slug = "custom"; return p },
+			want:    "/custom/custom/my-post/",
+		},
+		{
+			name:         "slug or file name without slug",
+			pattern:      "/:slugorfilename/",
+			preserveCase: true,
+			want:         "/My-Post/",
+		},
+		{
+			name:    "url override",
+			pattern: "/:year/:slug/",
+			post:    func(p Post) Post { p.Override = "/About/"; return p },
+			want:    "/about/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := post
+			if tt.post != nil {
+				p = tt.post(p)
+			}
+			config := Config{Generator: generator.Hugo, Pattern: tt.pattern, PreserveCase: tt.preserveCase}
+			if got := config.Path(p); got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEleventyPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		override string
+		want     string
+	}{
+		{name: "file", path: "posts/hello.md", want: "/posts/hello/"},
+		{name: "index file", path: "posts/hello/index.md", want: "/posts/hello/"},
+		{name: "root index", path: "index.md", want: "/"},
+		{name: "override", path: "posts/hello.md", override: "/custom/", want: "/custom/"},
+		{name: "override index file", path: "posts/hello.md", override: "/custom/index.html", want: "/custom/"},
+		{name: "override file", path: "posts/hello.md", override: "/custom.html", want: "/custom.html"},
+		{name: "not written", path: "posts/hello.md", override: "false", want: ""},
+		{name: "template syntax", path: "posts/hello.md", override: "/{{ page.fileSlug }}/", want: ""},
+		{name: "template tags", path: "posts/hello.md", override: "{% if true %}/a/{% endif %}", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Generator: generator.Eleventy}
+			if got := config.Path(Post{Path: tt.path, Override: tt.override}); got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		post   Post
+		want   string
+	}{
+		{
+			name:   "base URL with a path",
+			config: Config{Generator: generator.Hugo, BaseURL: "https://example.com/blog/"},
+			post:   Post{Path: "content/posts/hello.md"},
+			want:   "https://example.com/blog/posts/hello/",
+		},
+		{
+			name:   "no base URL",
+			config: Config{Generator: generator.Hugo},
+			post:   Post{Path: "content/posts/hello.md"},
+			want:   "",
+		},
+		{
+			name:   "absolute override",
+			config: Config{Generator: generator.Eleventy, BaseURL: "https://example.com"},
+			post:   Post{Path: "posts/hello.md", Override: "https://other.example.com/hello/"},
+			want:   "https://other.example.com/hello/",
+		},
+		{
+			name:   "generator without permalinks",
+			config: Config{Generator: generator.Other, BaseURL: "https://example.com"},
+			post:   Post{Path: "posts/hello.md"},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.URL(tt.post); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFrontmatter(t *testing.T) {
+	fields := []markdown.FrontmatterField{
+		{Name: "title", Type: "string", StringValue: "Hello"},
+		{Name: "slug", Type: "string", StringValue: "hi"},
+		{Name: "date", Type: "dateTime", DateTimeValue: postDate},
+		{Name: "categories", Type: "stringSlice", StringSliceValue: []string{"go", "web"}},
+		{Name: "category", Type: "string", StringValue: "news notes"},
+		{Name: "permalink", Type: "string", StringValue: " /custom/ "},
+		{Name: "url", Type: "string", StringValue: "/hugo/"},
+	}
+
+	tests := []struct {
+		generator string
+		fields    []markdown.FrontmatterField
+		override  string
+	}{
+		{generator.Jekyll, fields, "/custom/"},
+		{generator.Eleventy, fields, "/custom/"},
+		{generator.Hugo, fields, "/hugo/"},
+		{generator.Eleventy, []markdown.FrontmatterField{{Name: "permalink", Type: "bool", BoolValue: false}}, "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.generator, func(t *testing.T) {
+			post := FromFrontmatter(tt.generator, "posts/hello.md", tt.fields)
+			if post.Override != tt.override {
+				t.Errorf("Override = %q, want %q", post.Override, tt.override)
+			}
+		})
+	}
+
+	post := FromFrontmatter(generator.Jekyll, "posts/hello.md", fields)
+	if post.Path != "posts/hello.md" || post.Title != "Hello" || post.Slug != "hi" || !post.Date.Equal(postDate) {
+		t.Errorf("FromFrontmatter() = %+v", post)
+	}
+	if got := len(post.Categories); got != 4 {
+		t.Errorf("Categories = %v, want the categories and category fields", post.Categories)
+	}
+}
@@ -0,0 +1,208 @@
+package preview
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"static-admin/generator"
+	"static-admin/markdown"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// LayoutPath is the path of the optional preview layout of a site, relative to the site root
+const LayoutPath = ".static-admin/preview.html"
+
+// DefaultLayout is used for sites without a preview layout
+const DefaultLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #1f2328; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; }
+img, video { max-width: 100%; height: auto; }
+pre { background: #f6f8fa; padding: 1rem; overflow: auto; }
+blockquote { border-left: 4px solid #d0d7de; margin: 0; padding: 0 1rem; color: #59636e; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.25rem 0.75rem; }
+</style>
+</head>
+<body>
+<article>
+<h1>{{ .Title }}</h1>
+{{ .Content }}
+</article>
+</body>
+</html>
+`
+
+// assetAttributes are the elements and attributes that load assets from the site
+var assetAttributes = []struct {
+	Selector  string
+	Attribute string
+}{
+	{"img[src]", "src"},
+	{"img[srcset]", "srcset"},
+	{"source[src]", "src"},
+	{"source[srcset]", "srcset"},
+	{"video[src]", "src"},
+	{"video[poster]", "poster"},
+	{"audio[src]", "src"},
+	{"script[src]", "src"},
+	{"link[rel=stylesheet][href]", "href"},
+	{"link[rel=icon][href]", "href"},
+}
+
+// Page is the data passed to preview layouts
+type Page struct {
+	// Title is the title field of the post
+	Title string
+
+	// Path is the path of the post relative to the site root
+	Path string
+
+	// Params holds every frontmatter field of the post by name
+	Params map[string]interface{}
+
+	// Content is the rendered body of the post
+	Content template.HTML
+}
+
+// NewPage renders the Markdown body of a post into a page
+func NewPage(postPath string, fields []markdown.FrontmatterField, body string) (Page, error) {
+	content, err := markdown.RenderHTML(body)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{
+		Path:    postPath,
+		Params:  make(map[string]interface{}, len(fields)),
+		Content: template.HTML(content),
+	}
+	for _, field := range fields {
+		page.Params[field.Name] = fieldValue(field)
+		if field.Name == "title" {
+			page.Title = field.StringValue
+		}
+	}
+	return page, nil
+}
+
+// Render executes a layout with a page, using the default layout when the layout is empty
+func Render(layout string, page Page) (string, error) {
+	if strings.TrimSpace(layout) == "" {
+		layout = DefaultLayout
+	}
+
+	tmpl, err := template.New("preview").Funcs(template.FuncMap{"date": formatDate}).Parse(layout)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, page); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RewriteAssets replaces relative asset URLs in a rendered page with the URLs returned by assetURL,
+// which receives the path of the asset relative to the site root
+func RewriteAssets(content, generatorName, postPath string, assetURL func(assetPath string) string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+
+	rewrite := func(value string) string {
+		assetPath, ok := AssetPath(generatorName, postPath, value)
+		if !ok {
+			return value
+		}
+		return assetURL(assetPath)
+	}
+
+	for _, asset := range assetAttributes {
+		doc.Find(asset.Selector).Each(func(_ int, selection *goquery.Selection) {
+			value, _ := selection.Attr(asset.Attribute)
+			if asset.Attribute == "srcset" {
+				selection.SetAttr(asset.Attribute, rewriteSrcset(value, rewrite))
+				return
+			}
+			selection.SetAttr(asset.Attribute, rewrite(value))
+		})
+	}
+
+	return doc.Html()
+}
+
+// AssetPath resolves an asset URL to a path relative to the site root. URLs starting with a slash are
+// served from the static directory of the generator, other relative URLs are relative to the post.
+// Absolute URLs, fragments and paths leaving the site return false.
+func AssetPath(generatorName, postPath, value string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" {
+		return "", false
+	}
+
+	var assetPath string
+	if strings.HasPrefix(parsed.Path, "/") {
+		assetPath = path.Join(generator.StaticDirectory(generatorName), parsed.Path)
+	} else {
+		assetPath = path.Join(path.Dir(postPath), parsed.Path)
+	}
+
+	assetPath = strings.TrimPrefix(assetPath, "/")
+	if assetPath == "" || assetPath == "." || assetPath == ".." || strings.HasPrefix(assetPath, "../") {
+		return "", false
+	}
+	return assetPath, true
+}
+
+// rewriteSrcset rewrites the URL of every candidate in a srcset attribute
+func rewriteSrcset(value string, rewrite func(string) string) string {
+	candidates := strings.Split(value, ",")
+	for i, candidate := range candidates {
+		parts := strings.Fields(candidate)
+		if len(parts) == 0 {
+			continue
+		}
+		parts[0] = rewrite(parts[0])
+		candidates[i] = strings.Join(parts, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// fieldValue returns the value of a frontmatter field for use in a layout
+func fieldValue(field markdown.FrontmatterField) interface{} {
+	switch field.Type {
+	case "bool":
+		return field.BoolValue
+	case "number":
+		return field.NumberValue
+	case "dateTime":
+		return field.DateTimeValue
+	case "stringSlice":
+		return field.StringSliceValue
+	default:
+		return field.StringValue
+	}
+}
+
+// formatDate is available to layouts as the date function, formatting a date field with a Go layout
+func formatDate(layout string, value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(layout)
+	case string:
+		return v
+	}
+	return ""
+}
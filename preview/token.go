@@ -0,0 +1,49 @@
+package preview
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AssetTokenTTL is how long the asset URLs of a rendered preview remain valid
+const AssetTokenTTL = time.Hour
+
+// AssetClaims grant read access to the files of a site to the asset URLs of a preview.
+// The user is stored under its own claim so asset tokens cannot be used to log in.
+type AssetClaims struct {
+	AssetUser uint   `json:"asset_user"`
+	SiteID    uint   `json:"site_id"`
+	PostPath  string `json:"post_path"`
+	jwt.RegisteredClaims
+}
+
+// NewAssetToken signs a token for the asset URLs of a preview of a post
+func NewAssetToken(secret []byte, userID, siteID uint, postPath string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, AssetClaims{
+		AssetUser: userID,
+		SiteID:    siteID,
+		PostPath:  postPath,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AssetTokenTTL)),
+		},
+	})
+	return token.SignedString(secret)
+}
+
+// ParseAssetToken validates an asset token and returns its claims
+func ParseAssetToken(secret []byte, tokenString string) (AssetClaims, error) {
+	var claims AssetClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return AssetClaims{}, err
+	}
+
+	if claims.AssetUser == 0 || claims.SiteID == 0 {
+		return AssetClaims{}, errors.New("not an asset token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,377 @@
+package previewbuild
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"static-admin/config"
+	"static-admin/database"
+	"static-admin/provider"
+
+	"gorm.io/gorm"
+)
+
+// keepBuilds is the number of finished builds kept for each branch, the latest successful build is always kept
+const keepBuilds = 3
+
+// ErrDisabled is returned when no directory is configured for preview builds
+var ErrDisabled = errors.New("preview builds are not enabled")
+
+// passthroughEnv are the environment variables of the server passed on to generators,
+// everything else is left out so that secrets are not exposed to the site's code
+var passthroughEnv = []string{"PATH", "GEM_HOME", "GEM_PATH", "NODE_PATH", "TZ"}
+
+// resourceLimits are applied to the generator process
+type resourceLimits struct {
+	CPU      time.Duration
+	FileSize int64
+	Memory   int64
+}
+
+// buildUser is the dedicated user and group the generator runs as
+type buildUser struct {
+	UID int
+	GID int
+}
+
+// Builder checks out branches of sites and runs their generator to produce preview sites
+type Builder struct {
+	db      *gorm.DB
+	dir     string
+	secret  []byte
+	timeout time.Duration
+	maxSize int64
+	limits  resourceLimits
+	user    buildUser
+	slots   chan struct{}
+	logs    sync.Map
+
+	// ctx is cancelled on shutdown, stopping running builds
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// RequestInput represents a request to build the head of a branch
+type RequestInput struct {
+	Site   database.Site
+	UserID uint
+	Token  string
+	Branch string
+
+	// Origin is the scheme and host the preview URL is served from
+	Origin string
+}
+
+var defaultBuilder atomic.Pointer[Builder]
+
+// Default returns the builder used by the handlers, or nil if preview builds are disabled
+func Default() *Builder {
+	return defaultBuilder.Load()
+}
+
+// SetDefault replaces the builder used by the handlers
+func SetDefault(b *Builder) {
+	defaultBuilder.Store(b)
+}
+
+// New creates a builder from the preview build configuration
+func New(config config.Config) *Builder {
+	ctx, cancel := context.WithCancel(context.Background())
+	maxSize := int64(config.PreviewBuildMaxSizeMB) << 20
+	return &Builder{
+		db:      config.Database,
+		dir:     config.PreviewBuildsDir,
+		secret:  []byte(config.JWTSecret),
+		timeout: config.PreviewBuildTimeout,
+		maxSize: maxSize,
+		limits: resourceLimits{
+			CPU:      config.PreviewBuildTimeout,
+			FileSize: maxSize,
+			Memory:   int64(config.PreviewBuildMaxMemoryMB) << 20,
+		},
+		user:   buildUser{UID: config.PreviewBuildUID, GID: config.PreviewBuildGID},
+		slots:  make(chan struct{}, config.PreviewBuildConcurrency),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start marks builds interrupted by a restart as failed and stops running builds when quit is closed
+func (b *Builder) Start(quit chan struct{}) {
+	err := b.db.Model(&database.PreviewBuild{}).
+		Where("status IN ?", []string{database.PreviewBuildStatusQueued, database.PreviewBuildStatusRunning}).
+		Updates(map[string]interface{}{
+			"status": database.PreviewBuildStatusFailed,
+			"error":  "Interrupted by a restart",
+		}).Error
+	if err != nil {
+		log.Printf("Failed to reset interrupted preview builds: %v", err)
+	}
+
+	go func() {
+		<-quit
+		b.cancel()
+	}()
+}
+
+// PreviewKey returns the unguessable identifier of the preview URL of a branch
+func (b *Builder) PreviewKey(siteID uint, branch string) string {
+	mac := hmac.New(sha256.New, b.secret)
+	fmt.Fprintf(mac, "%d:%s", siteID, branch)
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// PreviewURL returns the URL the latest successful build of a branch is served under
+func PreviewURL(origin, key string) string {
+	return origin + "/previews/" + key + "/"
+}
+
+// Request builds the head commit of a branch, reusing the build of the commit unless it failed
+func (b *Builder) Request(ctx context.Context, input RequestInput) (database.PreviewBuild, error) {
+	p, repo, err := provider.RepoForSite(input.Site, input.Token)
+	if err != nil {
+		return database.PreviewBuild{}, err
+	}
+
+	commitSHA, err := p.HeadCommit(ctx, repo, input.Branch)
+	if err != nil {
+		return database.PreviewBuild{}, fmt.Errorf("branch %s not found: %w", input.Branch, err)
+	}
+
+	var build database.PreviewBuild
+	err = b.db.Where("site_id = ? AND branch = ? AND commit_sha = ?", input.Site.ID, input.Branch, commitSHA).First(&build).Error
+	switch {
+	case err == nil && build.Status != database.PreviewBuildStatusFailed:
+		return build, nil
+	case err == nil:
+		build.UserID = input.UserID
+		build.Status = database.PreviewBuildStatusQueued
+		build.Log = ""
+		build.Error = ""
+		build.StartedAt = nil
+		build.FinishedAt = nil
+		if err := b.db.Save(&build).Error; err != nil {
+			return database.PreviewBuild{}, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		build = database.PreviewBuild{
+			SiteID:     input.Site.ID,
+			UserID:     input.UserID,
+			Branch:     input.Branch,
+			CommitSHA:  commitSHA,
+			PreviewKey: b.PreviewKey(input.Site.ID, input.Branch),
+			Status:     database.PreviewBuildStatusQueued,
+		}
+		if err := b.db.Create(&build).Error; err != nil {
+			// a concurrent request created the build first
+			if lookupErr := b.db.Where("site_id = ? AND branch = ? AND commit_sha = ?", input.Site.ID, input.Branch, commitSHA).First(&build).Error; lookupErr == nil {
+				return build, nil
+			}
+			return database.PreviewBuild{}, err
+		}
+	default:
+		return database.PreviewBuild{}, err
+	}
+
+	buildLog := newLog()
+	b.logs.Store(build.ID, buildLog)
+	go b.run(build, input, p, repo, buildLog)
+	return build, nil
+}
+
+// Log returns the live output of a queued or running build
+func (b *Builder) Log(buildID uint) (*Log, bool) {
+	value, ok := b.logs.Load(buildID)
+	if !ok {
+		return nil, false
+	}
+	return value.(*Log), true
+}
+
+// Latest returns the latest successful build served under a preview key
+func (b *Builder) Latest(key string) (database.PreviewBuild, error) {
+	var build database.PreviewBuild
+	err := b.db.Where("preview_key = ? AND status = ?", key, database.PreviewBuildStatusSucceeded).
+		Order("finished_at DESC").
+		First(&build).Error
+	return build, err
+}
+
+// OutputDir returns the directory holding the generated site of a build
+func (b *Builder) OutputDir(build database.PreviewBuild) string {
+	return filepath.Join(b.workDir(build), "public")
+}
+
+// run waits for a build slot and builds the site, recording the outcome on the build
+func (b *Builder) run(build database.PreviewBuild, input RequestInput, p provider.Provider, repo provider.Repo, buildLog *Log) {
+	defer b.logs.Delete(build.ID)
+	defer buildLog.Close()
+
+	fmt.Fprintf(buildLog, "Waiting for a build slot\n")
+	select {
+	case b.slots <- struct{}{}:
+		defer func() { <-b.slots }()
+	case <-b.ctx.Done():
+		b.finish(build, buildLog, errors.New("Interrupted by a shutdown"))
+		return
+	}
+
+	startedAt := time.Now()
+	err := b.db.Model(&build).Updates(map[string]interface{}{
+		"status":     database.PreviewBuildStatusRunning,
+		"started_at": startedAt,
+	}).Error
+	if err != nil {
+		log.Printf("Failed to start preview build %d: %v", build.ID, err)
+	}
+
+	err = b.build(build, input, p, repo, buildLog)
+	if err != nil {
+		fmt.Fprintf(buildLog, "Build failed: %v\n", err)
+		_ = os.RemoveAll(b.workDir(build))
+	} else {
+		fmt.Fprintf(buildLog, "Build succeeded in %s\n", time.Since(startedAt).Round(time.Second))
+	}
+	b.finish(build, buildLog, err)
+
+	if err == nil {
+		b.prune(build.SiteID, build.Branch)
+	}
+}
+
+// build checks out the commit of a build and runs the generator on it
+func (b *Builder) build(build database.PreviewBuild, input RequestInput, p provider.Provider, repo provider.Repo, buildLog *Log) error {
+	ctx, cancel := context.WithTimeout(b.ctx, b.timeout)
+	defer cancel()
+
+	workDir := b.workDir(build)
+	sourceDir := filepath.Join(workDir, "src")
+	if err := os.RemoveAll(workDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(sourceDir, 0o755); err != nil {
+		return err
+	}
+	defer os.RemoveAll(sourceDir)
+
+	fmt.Fprintf(buildLog, "Checking out %s at %s\n", build.Branch, build.CommitSHA)
+	count, err := checkout(ctx, p, repo, build.CommitSHA, sourceDir, b.maxSize)
+	if err != nil {
+		return timeoutError(ctx, b.timeout, err)
+	}
+	fmt.Fprintf(buildLog, "Checked out %d files\n", count)
+
+	args, err := command(input.Site.Generator, b.OutputDir(build), PreviewURL(input.Origin, build.PreviewKey))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buildLog, "$ %s\n", strings.Join(args, " "))
+
+	cmd, err := limitedCommand(ctx, args, b.limits, b.user)
+	if err != nil {
+		return err
+	}
+
+	// the generator writes its output and caches as the build user, which owns nothing else
+	if err := chownAll(workDir, b.user); err != nil {
+		return fmt.Errorf("failed to hand the checkout to the build user: %w", err)
+	}
+	cmd.Dir = sourceDir
+	cmd.Env = []string{"HOME=" + workDir, "LANG=C.UTF-8"}
+	for _, name := range passthroughEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+	cmd.Stdout = buildLog
+	cmd.Stderr = buildLog
+	if err := cmd.Run(); err != nil {
+		return timeoutError(ctx, b.timeout, err)
+	}
+	return nil
+}
+
+// finish records the outcome and output of a build
+func (b *Builder) finish(build database.PreviewBuild, buildLog *Log, buildErr error) {
+	status := database.PreviewBuildStatusSucceeded
+	message := ""
+	if buildErr != nil {
+		status = database.PreviewBuildStatusFailed
+		message = buildErr.Error()
+	}
+
+	err := b.db.Model(&build).Updates(map[string]interface{}{
+		"status":      status,
+		"error":       message,
+		"log":         buildLog.String(),
+		"finished_at": time.Now(),
+	}).Error
+	if err != nil {
+		log.Printf("Failed to save preview build %d: %v", build.ID, err)
+	}
+}
+
+// prune removes old builds of a branch together with their output
+func (b *Builder) prune(siteID uint, branch string) {
+	var builds []database.PreviewBuild
+	err := b.db.Where("site_id = ? AND branch = ? AND status IN ?", siteID, branch,
+		[]string{database.PreviewBuildStatusSucceeded, database.PreviewBuildStatusFailed}).
+		Order("finished_at DESC").
+		Find(&builds).Error
+	if err != nil {
+		log.Printf("Failed to fetch preview builds to prune: %v", err)
+		return
+	}
+
+	keptSuccess := false
+	for i, build := range builds {
+		latestSuccess := !keptSuccess && build.Status == database.PreviewBuildStatusSucceeded
+		keptSuccess = keptSuccess || latestSuccess
+		if i < keepBuilds || latestSuccess {
+			continue
+		}
+
+		if err := os.RemoveAll(b.workDir(build)); err != nil {
+			log.Printf("Failed to remove preview build %d: %v", build.ID, err)
+			continue
+		}
+		if err := b.db.Unscoped().Delete(&build).Error; err != nil {
+			log.Printf("Failed to delete preview build %d: %v", build.ID, err)
+		}
+	}
+}
+
+// chownAll gives a directory and everything in it to the build user
+func chownAll(dir string, user buildUser) error {
+	return filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, user.UID, user.GID)
+	})
+}
+
+// workDir returns the directory a build is checked out and generated in
+func (b *Builder) workDir(build database.PreviewBuild) string {
+	return filepath.Join(b.dir, strconv.FormatUint(uint64(build.ID), 10))
+}
+
+// timeoutError reports builds stopped by the timeout as such rather than by the error of the step that was cut short
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("build timed out after %s", timeout)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package previewbuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"static-admin/provider"
+)
+
+// checkoutWorkers is the number of files read from the provider at the same time
+const checkoutWorkers = 8
+
+// checkout writes the files of a site at a commit into dir, reading them through the site's provider
+// so that every provider and root path is supported without a git clone
+func checkout(ctx context.Context, p provider.Provider, repo provider.Repo, commitSHA, dir string, maxSize int64) (int, error) {
+	entries, err := p.Tree(ctx, repo, commitSHA)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []provider.TreeEntry
+	var size int64
+	for _, entry := range entries {
+		// symlinks and submodules are not checked out
+		if entry.Type != "blob" || entry.Mode == "120000" {
+			continue
+		}
+		size += entry.Size
+		files = append(files, entry)
+	}
+	if size > maxSize {
+		return 0, fmt.Errorf("checkout of %d bytes exceeds the limit of %d bytes", size, maxSize)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan provider.TreeEntry)
+	errs := make(chan error, checkoutWorkers)
+	var wg sync.WaitGroup
+	for i := 0; i < checkoutWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				if err := checkoutFile(ctx, p, repo, commitSHA, dir, entry); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	for _, entry := range files {
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return len(files), nil
+}
+
+// checkoutFile writes a single file of the checkout
+func checkoutFile(ctx context.Context, p provider.Provider, repo provider.Repo, commitSHA, dir string, entry provider.TreeEntry) error {
+	target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+	if !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+		return fmt.Errorf("path %s is outside of the checkout", entry.Path)
+	}
+
+	data, err := p.ReadRaw(ctx, repo, entry.Path, commitSHA)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", entry.Path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0o644)
+	if entry.Mode == "100755" {
+		mode = 0o755
+	}
+	return os.WriteFile(target, data, mode)
+}
@@ -0,0 +1,31 @@
+package previewbuild
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"static-admin/generator"
+)
+
+// command returns the generator command building the site in the current directory into outputDir.
+// Drafts and future posts are included, as the preview exists to review unpublished content.
+func command(generatorName, outputDir, baseURL string) ([]string, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	pathPrefix := strings.TrimSuffix(parsed.Path, "/")
+
+	switch generatorName {
+	case generator.Hugo:
+		return []string{"hugo", "--destination", outputDir, "--baseURL", baseURL, "--buildDrafts", "--buildFuture"}, nil
+	case generator.Jekyll:
+		// safe mode leaves out the plugins of the repository, only whitelisted gems are loaded
+		return []string{"jekyll", "build", "--safe", "--destination", outputDir, "--baseurl", pathPrefix, "--drafts", "--future"}, nil
+	case generator.Eleventy:
+		return []string{"eleventy", "--output=" + outputDir, "--pathprefix=" + pathPrefix + "/"}, nil
+	default:
+		return nil, fmt.Errorf("sites using the %s generator cannot be built", generatorName)
+	}
+}
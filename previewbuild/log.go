@@ -0,0 +1,71 @@
+package previewbuild
+
+import "sync"
+
+// maxLogSize limits the build output kept for a build, later output is dropped
+const maxLogSize = 1 << 20
+
+// Log collects the output of a running build and wakes up readers streaming it
+type Log struct {
+	mu      sync.Mutex
+	data    []byte
+	done    bool
+	changed chan struct{}
+}
+
+// newLog creates an empty log
+func newLog() *Log {
+	return &Log{changed: make(chan struct{})}
+}
+
+// Write appends build output to the log
+func (l *Log) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if remaining := maxLogSize - len(l.data); remaining > 0 {
+		if len(p) > remaining {
+			l.data = append(l.data, p[:remaining]...)
+			l.data = append(l.data, "\n[log truncated]\n"...)
+		} else {
+			l.data = append(l.data, p...)
+		}
+	}
+	l.notify()
+	return len(p), nil
+}
+
+// Close marks the log as complete
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.done = true
+	l.notify()
+}
+
+// Next returns the output after offset, whether the log is complete, and a channel closed on the next change
+func (l *Log) Next(offset int) ([]byte, bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var data []byte
+	if offset < len(l.data) {
+		data = append(data, l.data[offset:]...)
+	}
+	return data, l.done, l.changed
+}
+
+// String returns the output collected so far
+func (l *Log) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return string(l.data)
+}
+
+// notify wakes up readers waiting for a change, the caller must hold the lock
+func (l *Log) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package previewbuild
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// limitedCommand refuses to run generators, as they can only be isolated from the server on unix systems
+func limitedCommand(ctx context.Context, args []string, limits resourceLimits, user buildUser) (*exec.Cmd, error) {
+	return nil, errors.New("preview builds are only supported on unix systems")
+}
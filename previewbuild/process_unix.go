@@ -0,0 +1,41 @@
+//go:build unix
+
+package previewbuild
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// limitedCommand runs a command through the shell to apply resource limits, as the build user and in its own
+// process group so that the generator and every process it starts are killed when the build is cancelled
+func limitedCommand(ctx context.Context, args []string, limits resourceLimits, user buildUser) (*exec.Cmd, error) {
+	// POSIX shells count CPU time in whole seconds, file sizes in 512 byte blocks and memory in kilobytes,
+	// a CPU limit of zero seconds would stop the generator right away
+	cpuSeconds := max(int(math.Ceil(limits.CPU.Seconds())), 1)
+	script := []string{
+		fmt.Sprintf("ulimit -t %d", cpuSeconds),
+		fmt.Sprintf("ulimit -f %d", limits.FileSize/512),
+	}
+	if limits.Memory > 0 {
+		script = append(script, fmt.Sprintf("ulimit -v %d", limits.Memory/1024))
+	}
+	script = append(script, `exec "$@"`)
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", strings.Join(script, " && "), "sh"}, args...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+		// supplementary groups of the server are dropped so that the build user only has its own group
+		Credential: &syscall.Credential{Uid: uint32(user.UID), Gid: uint32(user.GID), Groups: []uint32{}},
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 10 * time.Second
+	return cmd, nil
+}
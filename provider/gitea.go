@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"static-admin/config"
+
+	"golang.org/x/oauth2"
+)
+
+// giteaTreePageSize is the number of entries requested per page of a recursive tree
+const giteaTreePageSize = 1000
+
+// GiteaProvider publishes sites through the Gitea and Forgejo contents and pulls APIs
+type GiteaProvider struct {
+	api    apiClient
+	oauth  *oauth2.Config
+	webURL string
+}
+
+// NewGitea creates the Gitea provider from the application config
+func NewGitea(config config.Config) *GiteaProvider {
+	webURL := strings.TrimSuffix(config.GiteaURL, "/")
+	return &GiteaProvider{
+		api: newAPIClient(webURL+"/api/v1", config.GithubTimeout),
+		oauth: &oauth2.Config{
+			ClientID:     config.GiteaClientID,
+			ClientSecret: config.GiteaClientSecret,
+			RedirectURL:  config.GiteaRedirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  webURL + "/login/oauth/authorize",
+				TokenURL: webURL + "/login/oauth/access_token",
+			},
+		},
+		webURL: webURL,
+	}
+}
+
+// giteaRepository represents a repository from the Gitea API
+type giteaRepository struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	HtmlURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// giteaPullRequest represents a pull request from the Gitea API
+type giteaPullRequest struct {
+	Number  int64  `json:"number"`
+	HtmlURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// Name returns the identifier stored on sites using the provider
+func (p *GiteaProvider) Name() string {
+	return Gitea
+}
+
+// WebURL returns the root of the provider's web interface
+func (p *GiteaProvider) WebURL() string {
+	return p.webURL
+}
+
+// AuthCodeURL returns the URL of the OAuth consent page
+func (p *GiteaProvider) AuthCodeURL(state string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+// Exchange trades an OAuth code for a token and looks up the account it belongs to
+func (p *GiteaProvider) Exchange(ctx context.Context, code string) (Account, error) {
+	tok, err := p.oauth.Exchange(ctx, code)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to do exchange: %w", err)
+	}
+
+	var user struct {
+		Login    string `json:"login"`
+		FullName string `json:"full_name"`
+		HtmlURL  string `json:"html_url"`
+	}
+	if err := p.api.doJSON(ctx, "GET", "/user", tok.AccessToken, nil, &user); err != nil {
+		return Account{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return Account{
+		Login:       user.Login,
+		Name:        user.FullName,
+		URL:         user.HtmlURL,
+		AccessToken: tok.AccessToken,
+	}, nil
+}
+
+// ListRepositories lists the repositories the token can access
+func (p *GiteaProvider) ListRepositories(ctx context.Context, token string) ([]Repository, error) {
+	var result []Repository
+	err := p.api.pages(ctx, "/user/repos?limit=50", token, func(body []byte) error {
+		var repos []giteaRepository
+		if err := json.Unmarshal(body, &repos); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		for _, repo := range repos {
+			result = append(result, repo.repository())
+		}
+		return nil
+	})
+	return result, err
+}
+
+// FetchRepository reads the settings of a single repository
+func (p *GiteaProvider) FetchRepository(ctx context.Context, repo Repo) (Repository, error) {
+	var result giteaRepository
+	if err := p.api.doJSON(ctx, "GET", repoPath(repo), repo.Token, nil, &result); err != nil {
+		return Repository{}, err
+	}
+	return result.repository(), nil
+}
+
+// HeadCommit returns the commit a branch points to
+func (p *GiteaProvider) HeadCommit(ctx context.Context, repo Repo, branch string) (string, error) {
+	var result struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	if err := p.api.doJSON(ctx, "GET", repoPath(repo)+"/branches/"+url.PathEscape(branch), repo.Token, nil, &result); err != nil {
+		return "", err
+	}
+	if result.Commit.ID == "" {
+		return "", fmt.Errorf("branch %s not found", branch)
+	}
+	return result.Commit.ID, nil
+}
+
+// CommitTree returns the SHA of the tree of a commit
+func (p *GiteaProvider) CommitTree(ctx context.Context, repo Repo, commitSHA string) (string, error) {
+	var result struct {
+		Commit struct {
+			Tree struct {
+				SHA string `json:"sha"`
+			} `json:"tree"`
+		} `json:"commit"`
+	}
+	if err := p.api.doJSON(ctx, "GET", repoPath(repo)+"/git/commits/"+commitSHA, repo.Token, nil, &result); err != nil {
+		return "", err
+	}
+	return result.Commit.Tree.SHA, nil
+}
+
+// Tree lists every file in the repository at a commit, reading the recursive tree a page at a time
+func (p *GiteaProvider) Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	for page := 1; ; page++ {
+		var result struct {
+			Tree      []TreeEntry `json:"tree"`
+			Truncated bool        `json:"truncated"`
+		}
+		path := fmt.Sprintf("%s/git/trees/%s?recursive=true&per_page=%d&page=%d", repoPath(repo), commitSHA, giteaTreePageSize, page)
+		if err := p.api.doJSON(ctx, "GET", path, repo.Token, nil, &result); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, result.Tree...)
+		if !result.Truncated || len(result.Tree) == 0 {
+			return entries, nil
+		}
+	}
+}
+
+// ReadBlobs reads the text of many files one blob at a time, the last commit of each file is not reported
+func (p *GiteaProvider) ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error) {
+	result := make(map[string]BlobContent, len(entries))
+	for _, entry := range entries {
+		var blob struct {
+			Content  string `json:"content"`
+			Encoding string `json:"encoding"`
+		}
+		if err := p.api.doJSON(ctx, "GET", repoPath(repo)+"/git/blobs/"+entry.SHA, repo.Token, nil, &blob); err != nil {
+			return nil, err
+		}
+
+		data, err := base64.StdEncoding.DecodeString(blob.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode blob %s: %v", entry.Path, err)
+		}
+		text, ok := textContent(data)
+		if !ok {
+			continue
+		}
+		result[entry.Path] = BlobContent{Path: entry.Path, SHA: entry.SHA, Text: text}
+	}
+	return result, nil
+}
+
+// ReadFile reads the text of a file on a branch or commit
+func (p *GiteaProvider) ReadFile(ctx context.Context, repo Repo, path, ref string) (string, error) {
+	data, err := p.ReadRaw(ctx, repo, path, ref)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := textContent(data)
+	if !ok {
+		return "", fmt.Errorf("file is not a valid text file")
+	}
+	return text, nil
+}
+
+// ReadRaw reads the bytes of a file on a branch or commit
+func (p *GiteaProvider) ReadRaw(ctx context.Context, repo Repo, path, ref string) ([]byte, error) {
+	data, _, err := p.api.do(ctx, "GET", repoPath(repo)+"/raw/"+escapePath(path)+"?ref="+url.QueryEscape(ref), repo.Token, nil)
+	return data, err
+}
+
+// CommitFiles writes and deletes a set of files in a single commit, creating the branch from the base branch if necessary
+func (p *GiteaProvider) CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error {
+	if len(input.Changes) == 0 {
+		return fmt.Errorf("at least one file change is required")
+	}
+
+	ref := input.Branch
+	newBranch := ""
+	if _, err := p.HeadCommit(ctx, repo, input.Branch); err != nil {
+		if !isStatus(err, http.StatusNotFound) {
+			return err
+		}
+		ref = input.BaseBranch
+		newBranch = input.Branch
+	}
+
+	type fileOperation struct {
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content,omitempty"`
+		SHA       string `json:"sha,omitempty"`
+	}
+
+	// updates and deletions must name the blob they replace
+	files := make([]fileOperation, 0, len(input.Changes))
+	for _, change := range input.Changes {
+		sha, err := p.fileSHA(ctx, repo, change.Path, ref)
+		if err != nil {
+			return err
+		}
+
+		operation := fileOperation{Path: change.Path, SHA: sha}
+		switch {
+		case change.Delete && sha == "":
+			continue
+		case change.Delete:
+			operation.Operation = "delete"
+		case sha == "":
+			operation.Operation = "create"
+			operation.Content = base64.StdEncoding.EncodeToString([]byte(change.Content))
+		default:
+			operation.Operation = "update"
+			operation.Content = base64.StdEncoding.EncodeToString([]byte(change.Content))
+		}
+		files = append(files, operation)
+	}
+
+	payload := map[string]interface{}{
+		"branch":  ref,
+		"message": input.CommitMsg,
+		"files":   files,
+	}
+	if newBranch != "" {
+		payload["new_branch"] = newBranch
+	}
+
+	err := p.api.doJSON(ctx, "POST", repoPath(repo)+"/contents", repo.Token, payload, nil)
+	if isStatus(err, http.StatusForbidden) {
+		return fmt.Errorf("%w: %v", ErrProtectedBranch, err)
+	}
+	return err
+}
+
+// fileSHA returns the blob SHA of a file on a ref, or an empty string if the file does not exist
+func (p *GiteaProvider) fileSHA(ctx context.Context, repo Repo, path, ref string) (string, error) {
+	var content struct {
+		SHA string `json:"sha"`
+	}
+	err := p.api.doJSON(ctx, "GET", repoPath(repo)+"/contents/"+escapePath(path)+"?ref="+url.QueryEscape(ref), repo.Token, nil, &content)
+	if isStatus(err, http.StatusNotFound) {
+		return "", nil
+	}
+	return content.SHA, err
+}
+
+// CreateReviewRequest opens a pull request for a branch, reusing and updating an open one if it exists
+func (p *GiteaProvider) CreateReviewRequest(ctx context.Context, repo Repo, input ReviewRequestInput) (ReviewRequest, error) {
+	var found *giteaPullRequest
+	err := p.api.pages(ctx, repoPath(repo)+"/pulls?state=open&limit=50", repo.Token, func(body []byte) error {
+		var pulls []giteaPullRequest
+		if err := json.Unmarshal(body, &pulls); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		for i := range pulls {
+			if found == nil && pulls[i].Head.Ref == input.Branch && pulls[i].Base.Ref == input.BaseBranch {
+				found = &pulls[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ReviewRequest{}, err
+	}
+	if found != nil {
+		if input.Body != "" {
+			payload := map[string]string{"body": input.Body}
+			if err := p.api.doJSON(ctx, "PATCH", fmt.Sprintf("%s/pulls/%d", repoPath(repo), found.Number), repo.Token, payload, nil); err != nil {
+				return ReviewRequest{}, err
+			}
+		}
+		return ReviewRequest{Number: found.Number, URL: found.HtmlURL}, nil
+	}
+
+	var created giteaPullRequest
+	payload := map[string]string{
+		"head":  input.Branch,
+		"base":  input.BaseBranch,
+		"title": input.Title,
+		"body":  input.Body,
+	}
+	if err := p.api.doJSON(ctx, "POST", repoPath(repo)+"/pulls", repo.Token, payload, &created); err != nil {
+		return ReviewRequest{}, err
+	}
+	return ReviewRequest{Number: created.Number, URL: created.HtmlURL}, nil
+}
+
+// FileHistory lists the commits that changed a file, Gitea does not report renames so they are not followed
+func (p *GiteaProvider) FileHistory(ctx context.Context, repo Repo, path, ref string) ([]Revision, error) {
+	var revisions []Revision
+	query := url.Values{"sha": {ref}, "path": {path}, "limit": {"50"}, "stat": {"false"}, "files": {"false"}}
+	err := p.api.pages(ctx, repoPath(repo)+"/commits?"+query.Encode(), repo.Token, func(body []byte) error {
+		var commits []struct {
+			SHA    string `json:"sha"`
+			Commit struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+		}
+		if err := json.Unmarshal(body, &commits); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		for _, commit := range commits {
+			revisions = append(revisions, Revision{
+				SHA:     commit.SHA,
+				Path:    path,
+				Author:  commit.Commit.Author.Name,
+				Date:    commit.Commit.Author.Date,
+				Message: commit.Commit.Message,
+			})
+		}
+		return nil
+	})
+	return revisions, err
+}
+
+// repository converts a repository from the Gitea API
+func (repo giteaRepository) repository() Repository {
+	return Repository{
+		Owner:         repo.Owner.Login,
+		Name:          repo.Name,
+		Description:   repo.Description,
+		Private:       repo.Private,
+		HtmlURL:       repo.HtmlURL,
+		DefaultBranch: repo.DefaultBranch,
+	}
+}
+
+// repoPath returns the API path of a repository
+func repoPath(repo Repo) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(repo.Owner), url.PathEscape(repo.Name))
+}
+
+// escapePath escapes each segment of a file path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
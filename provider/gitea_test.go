@@ -0,0 +1,450 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"static-admin/config"
+)
+
+// giteaTestFile is a file in the repository served by a fake Gitea instance
+type giteaTestFile struct {
+	sha     string
+	content string
+}
+
+// giteaTestServer mimics the parts of the Gitea API used by the provider for a single repository
+type giteaTestServer struct {
+	*httptest.Server
+
+	// maxPageSize caps the page size of listings like the MAX_RESPONSE_ITEMS setting of Gitea
+	maxPageSize int
+
+	mu        sync.Mutex
+	branches  map[string]string
+	files     map[string]giteaTestFile
+	protected bool
+	commits   []map[string]interface{}
+}
+
+func newGiteaTestServer(t *testing.T) *giteaTestServer {
+	t.Helper()
+	s := &giteaTestServer{
+		maxPageSize: 2,
+		branches:    map[string]string{"main": "c0ffee"},
+		files: map[string]giteaTestFile{
+			"_config.yml":           {sha: "b1", content: "title: Site\n"},
+			"_posts/2024-01-01.md":  {sha: "b2", content: "---\ntitle: First\n---\nHello\n"},
+			"_posts/2024-02-01.md":  {sha: "b3", content: "---\ntitle: Second\n---\nWorld\n"},
+			"assets/logo.png":       {sha: "b4", content: "\x89PNG\r\n\x1a\n\xff\xfe"},
+			"assets/css/style.scss": {sha: "b5", content: "body {}\n"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	repo := "/api/v1/repos/owner/site"
+	mux.HandleFunc("GET "+repo+"/branches/{branch}", s.branch)
+	mux.HandleFunc("GET "+repo+"/git/commits/{sha}", s.commit)
+	mux.HandleFunc("GET "+repo+"/git/trees/{sha}", s.tree)
+	mux.HandleFunc("GET "+repo+"/git/blobs/{sha}", s.blob)
+	mux.HandleFunc("GET "+repo+"/raw/{path...}", s.raw)
+	mux.HandleFunc("GET "+repo+"/contents/{path...}", s.contents)
+	mux.HandleFunc("POST "+repo+"/contents", s.changeFiles)
+	mux.HandleFunc("GET "+repo+"/commits", s.history)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"message":"token is required"}`, http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *giteaTestServer) provider() (*GiteaProvider, Repo) {
+	return NewGitea(config.Config{GiteaURL: s.URL + "/"}), Repo{Owner: "owner", Name: "site", Token: "token"}
+}
+
+func (s *giteaTestServer) branch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sha, ok := s.branches[r.PathValue("branch")]
+	if !ok {
+		http.Error(w, `{"message":"branch not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"name": r.PathValue("branch"), "commit": map[string]string{"id": sha}})
+}
+
+func (s *giteaTestServer) commit(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != "c0ffee" {
+		http.Error(w, `{"message":"commit not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"sha": "c0ffee", "commit": map[string]interface{}{"tree": map[string]string{"sha": "7ree"}}})
+}
+
+func (s *giteaTestServer) tree(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sha") != "c0ffee" || r.URL.Query().Get("recursive") != "true" {
+		http.Error(w, `{"message":"tree not found"}`, http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	var entries []TreeEntry
+	for path, file := range s.files {
+		entries = append(entries, TreeEntry{Path: path, Mode: "100644", Type: "blob", SHA: file.sha, Size: int64(len(file.content))})
+	}
+	s.mu.Unlock()
+	entries = append(entries, TreeEntry{Path: "_posts", Mode: "040000", Type: "tree", SHA: "7ree2"})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	perPage = min(max(perPage, 1), s.maxPageSize)
+	start := min(max(page-1, 0)*perPage, len(entries))
+	end := min(start+perPage, len(entries))
+
+	writeJSON(w, map[string]interface{}{
+		"sha":       "c0ffee",
+		"tree":      entries[start:end],
+		"truncated": end < len(entries),
+		"page":      page,
+	})
+}
+
+func (s *giteaTestServer) blob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, file := range s.files {
+		if file.sha == r.PathValue("sha") {
+			writeJSON(w, map[string]interface{}{
+				"sha":      file.sha,
+				"size":     len(file.content),
+				"encoding": "base64",
+				"content":  base64.StdEncoding.EncodeToString([]byte(file.content)),
+			})
+			return
+		}
+	}
+	http.Error(w, `{"message":"blob not found"}`, http.StatusNotFound)
+}
+
+func (s *giteaTestServer) raw(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.file(r.PathValue("path"), r.URL.Query().Get("ref"))
+	if !ok {
+		http.Error(w, `{"message":"file not found"}`, http.StatusNotFound)
+		return
+	}
+	w.Write([]byte(file.content))
+}
+
+func (s *giteaTestServer) contents(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.file(r.PathValue("path"), r.URL.Query().Get("ref"))
+	if !ok {
+		http.Error(w, `{"message":"file not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"path": r.PathValue("path"), "sha": file.sha, "type": "file"})
+}
+
+func (s *giteaTestServer) changeFiles(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, `{"message":"invalid payload"}`, http.StatusUnprocessableEntity)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.protected {
+		http.Error(w, `{"message":"branch is protected"}`, http.StatusForbidden)
+		return
+	}
+	s.commits = append(s.commits, payload)
+	if branch, ok := payload["new_branch"].(string); ok {
+		s.branches[branch] = "c0ffee2"
+	}
+	writeJSON(w, map[string]interface{}{"commit": map[string]string{"sha": "c0ffee2"}})
+}
+
+func (s *giteaTestServer) history(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("sha") != "main" || query.Get("path") != "_posts/2024-01-01.md" {
+		writeJSON(w, []interface{}{})
+		return
+	}
+
+	commits := []map[string]interface{}{
+		{"sha": "c2", "commit": map[string]interface{}{"message": "Update post", "author": map[string]string{"name": "Jane", "date": "2024-01-02T10:00:00Z"}}},
+		{"sha": "c1", "commit": map[string]interface{}{"message": "Add post", "author": map[string]string{"name": "John", "date": "2024-01-01T10:00:00Z"}}},
+	}
+	if query.Get("page") == "" {
+		next := *r.URL
+		next.Scheme, next.Host = "http", r.Host
+		values := next.Query()
+		values.Set("page", "2")
+		next.RawQuery = values.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+		writeJSON(w, commits[:1])
+		return
+	}
+	writeJSON(w, commits[1:])
+}
+
+// file returns a file of the repository, which only exists on branches and the head commit
+func (s *giteaTestServer) file(path, ref string) (giteaTestFile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.branches[ref]; !ok && ref != "c0ffee" {
+		return giteaTestFile{}, false
+	}
+	file, ok := s.files[path]
+	return file, ok
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func TestGiteaProviderCommits(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p, repo := s.provider()
+	ctx := context.Background()
+
+	commit, err := p.HeadCommit(ctx, repo, "main")
+	if err != nil || commit != "c0ffee" {
+		t.Fatalf("HeadCommit() = %q, %v, want c0ffee", commit, err)
+	}
+	if _, err := p.HeadCommit(ctx, repo, "missing"); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("HeadCommit() of a missing branch error = %v, want not found", err)
+	}
+
+	tree, err := p.CommitTree(ctx, repo, commit)
+	if err != nil || tree != "7ree" {
+		t.Fatalf("CommitTree() = %q, %v, want 7ree", tree, err)
+	}
+
+	repo.Token = ""
+	if _, err := p.HeadCommit(ctx, repo, "main"); err == nil {
+		t.Error("HeadCommit() without a token succeeded")
+	}
+}
+
+func TestGiteaProviderTree(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p, repo := s.provider()
+
+	entries, err := p.Tree(context.Background(), repo, "c0ffee")
+	if err != nil {
+		t.Fatalf("Tree() error = %v", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	want := []string{"_config.yml", "_posts", "_posts/2024-01-01.md", "_posts/2024-02-01.md", "assets/css/style.scss", "assets/logo.png"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Tree() paths = %v, want %v", paths, want)
+	}
+	if entries[1].Type != "tree" || entries[2].SHA != "b2" {
+		t.Errorf("Tree() entries = %+v", entries)
+	}
+
+	if _, err := p.Tree(context.Background(), repo, "missing"); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("Tree() of a missing commit error = %v, want not found", err)
+	}
+}
+
+func TestGiteaProviderReadBlobs(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p, repo := s.provider()
+	ctx := context.Background()
+
+	entries := []TreeEntry{
+		{Path: "_posts/2024-01-01.md", SHA: "b2"},
+		{Path: "_posts/2024-02-01.md", SHA: "b3"},
+		{Path: "assets/logo.png", SHA: "b4"},
+	}
+	blobs, err := p.ReadBlobs(ctx, repo, "c0ffee", entries)
+	if err != nil {
+		t.Fatalf("ReadBlobs() error = %v", err)
+	}
+
+	want := map[string]BlobContent{
+		"_posts/2024-01-01.md": {Path: "_posts/2024-01-01.md", SHA: "b2", Text: "---\ntitle: First\n---\nHello\n"},
+		"_posts/2024-02-01.md": {Path: "_posts/2024-02-01.md", SHA: "b3", Text: "---\ntitle: Second\n---\nWorld\n"},
+	}
+	if !reflect.DeepEqual(blobs, want) {
+		t.Errorf("ReadBlobs() = %+v, want %+v", blobs, want)
+	}
+
+	if _, err := p.ReadBlobs(ctx, repo, "c0ffee", []TreeEntry{{Path: "missing.md", SHA: "b9"}}); !isStatus(err, http.StatusNotFound) {
+		t.Errorf("ReadBlobs() of a missing blob error = %v, want not found", err)
+	}
+}
+
+func TestGiteaProviderReadFile(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p, repo := s.provider()
+	ctx := context.Background()
+
+	tests := []struct {
+		path    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"_config.yml", "main", "title: Site\n", false},
+		{"assets/css/style.scss", "c0ffee", "body {}\n", false},
+		{"assets/logo.png", "main", "", true},
+		{"missing.md", "main", "", true},
+		{"_config.yml", "missing", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"@"+tt.ref, func(t *testing.T) {
+			got, err := p.ReadFile(ctx, repo, tt.path, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiteaProviderCommitFiles(t *testing.T) {
+	encode := func(content string) string {
+		return base64.StdEncoding.EncodeToString([]byte(content))
+	}
+
+	tests := []struct {
+		name   string
+		input  CommitFilesInput
+		branch string
+		// newBranch is the branch the commit creates, if any
+		newBranch string
+		files     []interface{}
+	}{
+		{
+			name: "existing branch",
+			input: CommitFilesInput{
+				Branch:     "main",
+				BaseBranch: "main",
+				CommitMsg:  "Update posts",
+				Changes: []FileChange{
+					{Path: "_posts/2024-01-01.md", Content: "updated"},
+					{Path: "_posts/2024-03-01.md", Content: "created"},
+					{Path: "_posts/2024-02-01.md", Delete: true},
+					{Path: "_posts/missing.md", Delete: true},
+				},
+			},
+			branch: "main",
+			files: []interface{}{
+				map[string]interface{}{"operation": "update", "path": "_posts/2024-01-01.md", "sha": "b2", "content": encode("updated")},
+				map[string]interface{}{"operation": "create", "path": "_posts/2024-03-01.md", "content": encode("created")},
+				map[string]interface{}{"operation": "delete", "path": "_posts/2024-02-01.md", "sha": "b3"},
+			},
+		},
+		{
+			name: "new branch",
+			input: CommitFilesInput{
+				Branch:     "static-admin/post",
+				BaseBranch: "main",
+				CommitMsg:  "Update post",
+				Changes:    []FileChange{{Path: "_config.yml", Content: "title: New\n"}},
+			},
+			branch:    "main",
+			newBranch: "static-admin/post",
+			files: []interface{}{
+				map[string]interface{}{"operation": "update", "path": "_config.yml", "sha": "b1", "content": encode("title: New\n")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGiteaTestServer(t)
+			p, repo := s.provider()
+
+			if err := p.CommitFiles(context.Background(), repo, tt.input); err != nil {
+				t.Fatalf("CommitFiles() error = %v", err)
+			}
+			if len(s.commits) != 1 {
+				t.Fatalf("commits = %d, want 1", len(s.commits))
+			}
+
+			commit := s.commits[0]
+			if commit["branch"] != tt.branch || commit["message"] != tt.input.CommitMsg {
+				t.Errorf("commit = %+v, want branch %s", commit, tt.branch)
+			}
+			if newBranch, _ := commit["new_branch"].(string); newBranch != tt.newBranch {
+				t.Errorf("new branch = %q, want %q", newBranch, tt.newBranch)
+			}
+			if !reflect.DeepEqual(commit["files"], tt.files) {
+				t.Errorf("files = %+v, want %+v", commit["files"], tt.files)
+			}
+		})
+	}
+}
+
+func TestGiteaProviderCommitFilesProtectedBranch(t *testing.T) {
+	s := newGiteaTestServer(t)
+	s.protected = true
+	p, repo := s.provider()
+
+	err := p.CommitFiles(context.Background(), repo, CommitFilesInput{
+		Branch:     "main",
+		BaseBranch: "main",
+		CommitMsg:  "Update config",
+		Changes:    []FileChange{{Path: "_config.yml", Content: "title: New\n"}},
+	})
+	if !errors.Is(err, ErrProtectedBranch) {
+		t.Errorf("CommitFiles() error = %v, want %v", err, ErrProtectedBranch)
+	}
+
+	if err := p.CommitFiles(context.Background(), repo, CommitFilesInput{Branch: "main", BaseBranch: "main"}); err == nil {
+		t.Error("CommitFiles() without changes succeeded")
+	}
+}
+
+func TestGiteaProviderFileHistory(t *testing.T) {
+	s := newGiteaTestServer(t)
+	p, repo := s.provider()
+
+	revisions, err := p.FileHistory(context.Background(), repo, "_posts/2024-01-01.md", "main")
+	if err != nil {
+		t.Fatalf("FileHistory() error = %v", err)
+	}
+
+	var got []string
+	for _, revision := range revisions {
+		got = append(got, fmt.Sprintf("%s %s %s %s %s", revision.SHA, revision.Path, revision.Author, revision.Date.Format("2006-01-02"), revision.Message))
+	}
+	want := []string{
+		"c2 _posts/2024-01-01.md Jane 2024-01-02 Update post",
+		"c1 _posts/2024-01-01.md John 2024-01-01 Add post",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory() = %v, want %v", got, want)
+	}
+}
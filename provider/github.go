@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"static-admin/config"
+	"static-admin/github"
+
+	"golang.org/x/oauth2"
+	oauth2gh "golang.org/x/oauth2/github"
+)
+
+// GitHubProvider publishes sites through the GitHub git data and pulls APIs
+type GitHubProvider struct {
+	oauth  *oauth2.Config
+	webURL string
+}
+
+// NewGitHub creates the GitHub provider from the application config
+func NewGitHub(config config.Config) *GitHubProvider {
+	endpoint := oauth2gh.Endpoint
+	webURL := githubWebURL(config.GithubAPIURL)
+	if webURL != "https://github.com" {
+		endpoint = oauth2.Endpoint{
+			AuthURL:  webURL + "/login/oauth/authorize",
+			TokenURL: webURL + "/login/oauth/access_token",
+		}
+	}
+
+	return &GitHubProvider{
+		oauth: &oauth2.Config{
+			ClientID:     config.GithubClientID,
+			ClientSecret: config.GithubClientSecret,
+			RedirectURL:  config.GithubRedirectURL,
+			Scopes:       config.GithubScopes,
+			Endpoint:     endpoint,
+		},
+		webURL: webURL,
+	}
+}
+
+// githubWebURL derives the web interface of a GitHub or GitHub Enterprise API URL
+func githubWebURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if apiURL == "" || apiURL == github.DefaultBaseURL {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(apiURL, "/api/v3")
+}
+
+// Name returns the identifier stored on sites using the provider
+func (p *GitHubProvider) Name() string {
+	return GitHub
+}
+
+// WebURL returns the root of the provider's web interface
+func (p *GitHubProvider) WebURL() string {
+	return p.webURL
+}
+
+// AuthCodeURL returns the URL of the OAuth consent page
+func (p *GitHubProvider) AuthCodeURL(state string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+// Exchange trades an OAuth code for a token and looks up the account it belongs to
+func (p *GitHubProvider) Exchange(ctx context.Context, code string) (Account, error) {
+	tok, err := p.oauth.Exchange(github.DefaultClient().OAuth2Context(ctx), code)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to do exchange: %w", err)
+	}
+
+	var user struct {
+		Login   string `json:"login"`
+		Name    string `json:"name"`
+		HtmlURL string `json:"html_url"`
+	}
+	if err := github.DefaultClient().DoJSON(ctx, "GET", "/user", tok.AccessToken, nil, &user); err != nil {
+		return Account{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return Account{
+		Login:       user.Login,
+		Name:        user.Name,
+		URL:         user.HtmlURL,
+		AccessToken: tok.AccessToken,
+	}, nil
+}
+
+// ListRepositories lists the repositories the token can access
+func (p *GitHubProvider) ListRepositories(ctx context.Context, token string) ([]Repository, error) {
+	repos, err := github.FetchAuthenticatedRepositories(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Repository, len(repos))
+	for i, repo := range repos {
+		result[i] = fromGitHubRepository(repo)
+	}
+	return result, nil
+}
+
+// FetchRepository reads the settings of a single repository
+func (p *GitHubProvider) FetchRepository(ctx context.Context, repo Repo) (Repository, error) {
+	body, _, err := github.DefaultClient().GetCached(ctx, fmt.Sprintf("/repos/%s/%s", repo.Owner, repo.Name), repo.Token)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	var result github.Repository
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Repository{}, fmt.Errorf("failed to parse response: %v", err)
+	}
+	return fromGitHubRepository(result), nil
+}
+
+// HeadCommit returns the commit a branch points to
+func (p *GitHubProvider) HeadCommit(ctx context.Context, repo Repo, branch string) (string, error) {
+	return github.FetchHeadCommit(ctx, repo.Owner, repo.Name, branch, repo.Token)
+}
+
+// CommitTree returns the SHA of the tree of a commit
+func (p *GitHubProvider) CommitTree(ctx context.Context, repo Repo, commitSHA string) (string, error) {
+	return github.FetchCommitTree(ctx, repo.Owner, repo.Name, commitSHA, repo.Token)
+}
+
+// Tree lists every file in the repository at a commit
+func (p *GitHubProvider) Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error) {
+	tree, err := github.FetchTreeAtCommit(ctx, repo.Owner, repo.Name, commitSHA, repo.Token)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Tree, nil
+}
+
+// ReadBlobs reads the text and last commit of many files using batched GraphQL queries
+func (p *GitHubProvider) ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error) {
+	return github.FetchBlobs(ctx, github.FetchBlobsInput{
+		Owner:     repo.Owner,
+		Repo:      repo.Name,
+		CommitSHA: commitSHA,
+		Entries:   entries,
+		Token:     repo.Token,
+	})
+}
+
+// ReadFile reads the text of a file on a branch or commit
+func (p *GitHubProvider) ReadFile(ctx context.Context, repo Repo, path, ref string) (string, error) {
+	return github.FetchFileFromGitHub(ctx, github.GitHubFileRequest{
+		RepoOwner: repo.Owner,
+		RepoName:  repo.Name,
+		FilePath:  path,
+		Branch:    ref,
+		Token:     repo.Token,
+	})
+}
+
+// ReadRaw reads the bytes of a file on a branch or commit
+func (p *GitHubProvider) ReadRaw(ctx context.Context, repo Repo, path, ref string) ([]byte, error) {
+	return github.FetchRawFile(ctx, github.GitHubFileRequest{
+		RepoOwner: repo.Owner,
+		RepoName:  repo.Name,
+		FilePath:  path,
+		Branch:    ref,
+		Token:     repo.Token,
+	})
+}
+
+// FileHistory lists the commits that changed a file, following renames
+func (p *GitHubProvider) FileHistory(ctx context.Context, repo Repo, path, ref string) ([]Revision, error) {
+	return github.FetchFileHistory(ctx, github.FetchFileHistoryInput{
+		Owner: repo.Owner,
+		Repo:  repo.Name,
+		Path:  path,
+		Ref:   ref,
+		Token: repo.Token,
+	})
+}
+
+// CommitFiles writes and deletes a set of files in a single commit
+func (p *GitHubProvider) CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error {
+	return github.CommitFiles(ctx, github.CommitFilesInput{
+		Owner:      repo.Owner,
+		Repo:       repo.Name,
+		Branch:     input.Branch,
+		BaseBranch: input.BaseBranch,
+		Changes:    input.Changes,
+		CommitMsg:  input.CommitMsg,
+		Token:      repo.Token,
+	})
+}
+
+// CreateReviewRequest opens a pull request for a branch, reusing and updating an open one if it exists
+func (p *GitHubProvider) CreateReviewRequest(ctx context.Context, repo Repo, input ReviewRequestInput) (ReviewRequest, error) {
+	number, err := github.CreatePullRequestIfNecessary(ctx, github.CreatePullRequestIfNecessaryInput{
+		Owner:      repo.Owner,
+		Repo:       repo.Name,
+		Branch:     input.Branch,
+		BaseBranch: input.BaseBranch,
+		Title:      input.Title,
+		Body:       input.Body,
+		Token:      repo.Token,
+	})
+	if err != nil {
+		return ReviewRequest{}, err
+	}
+
+	return ReviewRequest{
+		Number: number,
+		URL:    fmt.Sprintf("%s/%s/%s/pull/%d", p.webURL, repo.Owner, repo.Name, number),
+	}, nil
+}
+
+// fromGitHubRepository converts a repository from the GitHub API
+func fromGitHubRepository(repo github.Repository) Repository {
+	owner, _, _ := strings.Cut(repo.FullName, "/")
+	return Repository{
+		Owner:         owner,
+		Name:          repo.Name,
+		Description:   repo.Description,
+		Private:       repo.Private,
+		HtmlURL:       repo.HtmlURL,
+		DefaultBranch: repo.DefaultBranch,
+	}
+}
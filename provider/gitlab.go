@@ -0,0 +1,393 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"static-admin/config"
+
+	"golang.org/x/oauth2"
+)
+
+// GitLabProvider publishes sites through the GitLab commits and merge requests APIs
+type GitLabProvider struct {
+	api    apiClient
+	oauth  *oauth2.Config
+	webURL string
+}
+
+// NewGitLab creates the GitLab provider from the application config
+func NewGitLab(config config.Config) *GitLabProvider {
+	webURL := strings.TrimSuffix(config.GitLabURL, "/")
+	return &GitLabProvider{
+		api: newAPIClient(webURL+"/api/v4", config.GithubTimeout),
+		oauth: &oauth2.Config{
+			ClientID:     config.GitLabClientID,
+			ClientSecret: config.GitLabClientSecret,
+			RedirectURL:  config.GitLabRedirectURL,
+			Scopes:       []string{"api", "read_user"},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  webURL + "/oauth/authorize",
+				TokenURL: webURL + "/oauth/token",
+			},
+		},
+		webURL: webURL,
+	}
+}
+
+// gitlabProject represents a project from the GitLab API
+type gitlabProject struct {
+	Path          string `json:"path"`
+	Description   string `json:"description"`
+	Visibility    string `json:"visibility"`
+	WebURL        string `json:"web_url"`
+	DefaultBranch string `json:"default_branch"`
+	Namespace     struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+}
+
+// gitlabBranch represents a branch from the GitLab API
+type gitlabBranch struct {
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// gitlabCommit represents a commit from the GitLab API
+type gitlabCommit struct {
+	ID           string    `json:"id"`
+	Message      string    `json:"message"`
+	AuthorName   string    `json:"author_name"`
+	AuthoredDate time.Time `json:"authored_date"`
+	ParentIDs    []string  `json:"parent_ids"`
+}
+
+// gitlabMergeRequest represents a merge request from the GitLab API
+type gitlabMergeRequest struct {
+	IID    int64  `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+// Name returns the identifier stored on sites using the provider
+func (p *GitLabProvider) Name() string {
+	return GitLab
+}
+
+// WebURL returns the root of the provider's web interface
+func (p *GitLabProvider) WebURL() string {
+	return p.webURL
+}
+
+// AuthCodeURL returns the URL of the OAuth consent page
+func (p *GitLabProvider) AuthCodeURL(state string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+// Exchange trades an OAuth code for a token and looks up the account it belongs to
+func (p *GitLabProvider) Exchange(ctx context.Context, code string) (Account, error) {
+	tok, err := p.oauth.Exchange(ctx, code)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to do exchange: %w", err)
+	}
+
+	var user struct {
+		Username string `json:"username"`
+		Name     string `json:"name"`
+		WebURL   string `json:"web_url"`
+	}
+	if err := p.api.doJSON(ctx, "GET", "/user", tok.AccessToken, nil, &user); err != nil {
+		return Account{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return Account{
+		Login:       user.Username,
+		Name:        user.Name,
+		URL:         user.WebURL,
+		AccessToken: tok.AccessToken,
+	}, nil
+}
+
+// ListRepositories lists the projects the token is a member of
+func (p *GitLabProvider) ListRepositories(ctx context.Context, token string) ([]Repository, error) {
+	var result []Repository
+	err := p.api.pages(ctx, "/projects?membership=true&order_by=path&sort=asc&per_page=100", token, func(body []byte) error {
+		var projects []gitlabProject
+		if err := json.Unmarshal(body, &projects); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		for _, project := range projects {
+			result = append(result, project.repository())
+		}
+		return nil
+	})
+	return result, err
+}
+
+// FetchRepository reads the settings of a single project
+func (p *GitLabProvider) FetchRepository(ctx context.Context, repo Repo) (Repository, error) {
+	var project gitlabProject
+	if err := p.api.doJSON(ctx, "GET", projectPath(repo), repo.Token, nil, &project); err != nil {
+		return Repository{}, err
+	}
+	return project.repository(), nil
+}
+
+// HeadCommit returns the commit a branch points to
+func (p *GitLabProvider) HeadCommit(ctx context.Context, repo Repo, branch string) (string, error) {
+	var result gitlabBranch
+	path := fmt.Sprintf("%s/repository/branches/%s", projectPath(repo), url.PathEscape(branch))
+	if err := p.api.doJSON(ctx, "GET", path, repo.Token, nil, &result); err != nil {
+		return "", err
+	}
+	if result.Commit.ID == "" {
+		return "", fmt.Errorf("branch %s not found", branch)
+	}
+	return result.Commit.ID, nil
+}
+
+// CommitTree returns the commit itself, as GitLab does not expose tree SHAs
+func (p *GitLabProvider) CommitTree(ctx context.Context, repo Repo, commitSHA string) (string, error) {
+	return commitSHA, nil
+}
+
+// Tree lists every file in the repository at a commit, GitLab does not report file sizes
+func (p *GitLabProvider) Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error) {
+	var entries []TreeEntry
+	path := fmt.Sprintf("%s/repository/tree?recursive=true&pagination=keyset&per_page=100&ref=%s", projectPath(repo), url.QueryEscape(commitSHA))
+	err := p.api.pages(ctx, path, repo.Token, func(body []byte) error {
+		var page []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+			Path string `json:"path"`
+			Mode string `json:"mode"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return fmt.Errorf("failed to parse response: %v", err)
+		}
+		for _, entry := range page {
+			entries = append(entries, TreeEntry{
+				Path: entry.Path,
+				Mode: entry.Mode,
+				Type: entry.Type,
+				SHA:  entry.ID,
+			})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// ReadBlobs reads the text of many files one blob at a time, the last commit of each file is not reported
+func (p *GitLabProvider) ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error) {
+	result := make(map[string]BlobContent, len(entries))
+	for _, entry := range entries {
+		data, _, err := p.api.do(ctx, "GET", fmt.Sprintf("%s/repository/blobs/%s/raw", projectPath(repo), entry.SHA), repo.Token, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		text, ok := textContent(data)
+		if !ok {
+			continue
+		}
+		result[entry.Path] = BlobContent{Path: entry.Path, SHA: entry.SHA, Text: text}
+	}
+	return result, nil
+}
+
+// ReadFile reads the text of a file on a branch or commit
+func (p *GitLabProvider) ReadFile(ctx context.Context, repo Repo, path, ref string) (string, error) {
+	data, err := p.ReadRaw(ctx, repo, path, ref)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := textContent(data)
+	if !ok {
+		return "", fmt.Errorf("file is not a valid text file")
+	}
+	return text, nil
+}
+
+// ReadRaw reads the bytes of a file on a branch or commit
+func (p *GitLabProvider) ReadRaw(ctx context.Context, repo Repo, path, ref string) ([]byte, error) {
+	data, _, err := p.api.do(ctx, "GET", filePath(repo, path)+"/raw?ref="+url.QueryEscape(ref), repo.Token, nil)
+	return data, err
+}
+
+// FileHistory lists the commits that changed a file, following renames reported by the diff of the commit adding the file
+func (p *GitLabProvider) FileHistory(ctx context.Context, repo Repo, path, ref string) ([]Revision, error) {
+	var revisions []Revision
+	for hop := 0; hop <= maxRenameHops; hop++ {
+		var oldest gitlabCommit
+		query := url.Values{"ref_name": {ref}, "path": {path}, "per_page": {"100"}}
+		err := p.api.pages(ctx, projectPath(repo)+"/repository/commits?"+query.Encode(), repo.Token, func(body []byte) error {
+			var commits []gitlabCommit
+			if err := json.Unmarshal(body, &commits); err != nil {
+				return fmt.Errorf("failed to parse response: %v", err)
+			}
+			for _, commit := range commits {
+				revisions = append(revisions, Revision{
+					SHA:     commit.ID,
+					Path:    path,
+					Author:  commit.AuthorName,
+					Date:    commit.AuthoredDate,
+					Message: commit.Message,
+				})
+				oldest = commit
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if oldest.ID == "" || len(oldest.ParentIDs) == 0 {
+			return revisions, nil
+		}
+
+		var diffs []struct {
+			OldPath     string `json:"old_path"`
+			NewPath     string `json:"new_path"`
+			RenamedFile bool   `json:"renamed_file"`
+		}
+		if err := p.api.doJSON(ctx, "GET", fmt.Sprintf("%s/repository/commits/%s/diff?per_page=100", projectPath(repo), oldest.ID), repo.Token, nil, &diffs); err != nil {
+			return nil, err
+		}
+
+		previous := ""
+		for _, diff := range diffs {
+			if diff.NewPath == path && diff.RenamedFile {
+				previous = diff.OldPath
+			}
+		}
+		if previous == "" {
+			return revisions, nil
+		}
+		path, ref = previous, oldest.ParentIDs[0]
+	}
+	return revisions, nil
+}
+
+// CommitFiles writes and deletes a set of files in a single commit, creating the branch from the base branch if necessary
+func (p *GitLabProvider) CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error {
+	if len(input.Changes) == 0 {
+		return fmt.Errorf("at least one file change is required")
+	}
+
+	ref := input.Branch
+	startBranch := ""
+	if _, err := p.HeadCommit(ctx, repo, input.Branch); err != nil {
+		if !isStatus(err, http.StatusNotFound) {
+			return err
+		}
+		ref = input.BaseBranch
+		startBranch = input.BaseBranch
+	}
+
+	type action struct {
+		Action   string `json:"action"`
+		FilePath string `json:"file_path"`
+		Content  string `json:"content,omitempty"`
+	}
+
+	// GitLab requires writes to say whether the file is created or updated
+	actions := make([]action, 0, len(input.Changes))
+	for _, change := range input.Changes {
+		if change.Delete {
+			actions = append(actions, action{Action: "delete", FilePath: change.Path})
+			continue
+		}
+
+		exists, err := p.api.exists(ctx, filePath(repo, change.Path)+"?ref="+url.QueryEscape(ref), repo.Token)
+		if err != nil {
+			return err
+		}
+		verb := "create"
+		if exists {
+			verb = "update"
+		}
+		actions = append(actions, action{Action: verb, FilePath: change.Path, Content: change.Content})
+	}
+
+	payload := map[string]interface{}{
+		"branch":         input.Branch,
+		"commit_message": input.CommitMsg,
+		"actions":        actions,
+	}
+	if startBranch != "" {
+		payload["start_branch"] = startBranch
+	}
+
+	err := p.api.doJSON(ctx, "POST", projectPath(repo)+"/repository/commits", repo.Token, payload, nil)
+	if isStatus(err, http.StatusForbidden) {
+		return fmt.Errorf("%w: %v", ErrProtectedBranch, err)
+	}
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && strings.Contains(apiErr.Body, "not allowed to push") {
+		return fmt.Errorf("%w: %v", ErrProtectedBranch, err)
+	}
+	return err
+}
+
+// CreateReviewRequest opens a merge request for a branch, reusing and updating an open one if it exists
+func (p *GitLabProvider) CreateReviewRequest(ctx context.Context, repo Repo, input ReviewRequestInput) (ReviewRequest, error) {
+	var existing []gitlabMergeRequest
+	query := url.Values{
+		"state":         {"opened"},
+		"source_branch": {input.Branch},
+		"target_branch": {input.BaseBranch},
+	}
+	if err := p.api.doJSON(ctx, "GET", projectPath(repo)+"/merge_requests?"+query.Encode(), repo.Token, nil, &existing); err != nil {
+		return ReviewRequest{}, err
+	}
+	if len(existing) > 0 {
+		if input.Body != "" {
+			payload := map[string]string{"description": input.Body}
+			if err := p.api.doJSON(ctx, "PUT", fmt.Sprintf("%s/merge_requests/%d", projectPath(repo), existing[0].IID), repo.Token, payload, nil); err != nil {
+				return ReviewRequest{}, err
+			}
+		}
+		return ReviewRequest{Number: existing[0].IID, URL: existing[0].WebURL}, nil
+	}
+
+	var created gitlabMergeRequest
+	payload := map[string]string{
+		"source_branch": input.Branch,
+		"target_branch": input.BaseBranch,
+		"title":         input.Title,
+		"description":   input.Body,
+	}
+	if err := p.api.doJSON(ctx, "POST", projectPath(repo)+"/merge_requests", repo.Token, payload, &created); err != nil {
+		return ReviewRequest{}, err
+	}
+	return ReviewRequest{Number: created.IID, URL: created.WebURL}, nil
+}
+
+// repository converts a project from the GitLab API
+func (project gitlabProject) repository() Repository {
+	return Repository{
+		Owner:         project.Namespace.FullPath,
+		Name:          project.Path,
+		Description:   project.Description,
+		Private:       project.Visibility != "public",
+		HtmlURL:       project.WebURL,
+		DefaultBranch: project.DefaultBranch,
+	}
+}
+
+// projectPath returns the API path of a project, addressed by its URL-encoded full path
+func projectPath(repo Repo) string {
+	return "/projects/" + url.PathEscape(repo.Owner+"/"+repo.Name)
+}
+
+// filePath returns the API path of a file in a project
+func filePath(repo Repo, path string) string {
+	return projectPath(repo) + "/repository/files/" + url.PathEscape(path)
+}
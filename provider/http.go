@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// apiError is returned for responses with an unexpected status code
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("API request failed: %s (status: %d)", e.Body, e.StatusCode)
+}
+
+// apiClient sends authenticated JSON requests to a provider's REST API
+type apiClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// newAPIClient creates a client for the REST API rooted at baseURL
+func newAPIClient(baseURL string, timeout time.Duration) apiClient {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return apiClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request and returns the response body and headers of a successful response
+func (c apiClient) do(ctx context.Context, method, path, token string, payload interface{}) ([]byte, http.Header, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode request: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		target = c.baseURL + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	if token == "" {
+		return nil, nil, fmt.Errorf("authentication token is required")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &apiError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return data, resp.Header, nil
+}
+
+// doJSON sends a request and decodes the JSON response into result, if given
+func (c apiClient) doJSON(ctx context.Context, method, path, token string, payload interface{}, result interface{}) error {
+	data, _, err := c.do(ctx, method, path, token, payload)
+	if err != nil {
+		return err
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
+
+// pages reads every page of a listing by following the Link headers of the responses
+func (c apiClient) pages(ctx context.Context, path, token string, page func(body []byte) error) error {
+	for path != "" {
+		data, header, err := c.do(ctx, "GET", path, token, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := page(data); err != nil {
+			return err
+		}
+
+		path = nextPageURL(header.Get("Link"))
+	}
+
+	return nil
+}
+
+// exists returns true if a GET request for the path succeeds and false if it is not found
+func (c apiClient) exists(ctx context.Context, path, token string) (bool, error) {
+	_, _, err := c.do(ctx, "GET", path, token, nil)
+	if err == nil {
+		return true, nil
+	}
+
+	if isStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// isStatus returns true if err is an API error with the given status code
+func isStatus(err error, statusCode int) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// nextPageURL extracts the "next" URL from a Link header
+func nextPageURL(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(strings.TrimSpace(link), ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// textContent returns the content as text, or false for binary files
+func textContent(content []byte) (string, bool) {
+	if len(content) == 0 || !utf8.Valid(content) {
+		return "", false
+	}
+	return string(content), true
+}
@@ -0,0 +1,824 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"static-admin/config"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// errBranchNotFound is returned when a branch does not exist in a local repository
+var errBranchNotFound = errors.New("branch not found")
+
+// LocalProvider publishes sites to git working copies on disk using go-git.
+// Commits to the checked out branch are written to the working copy, other branches are
+// committed without touching it.
+type LocalProvider struct {
+	root        string
+	pushRemote  string
+	authorName  string
+	authorEmail string
+
+	// locks serializes git operations on the same repository
+	locks sync.Map
+}
+
+// NewLocal creates the local provider from the application config
+func NewLocal(config config.Config) *LocalProvider {
+	return &LocalProvider{
+		root:        filepath.Clean(config.LocalSitesRoot),
+		pushRemote:  config.LocalPushRemote,
+		authorName:  config.LocalAuthorName,
+		authorEmail: config.LocalAuthorEmail,
+	}
+}
+
+// Name returns the identifier stored on sites using the provider
+func (p *LocalProvider) Name() string {
+	return Local
+}
+
+// WebURL returns the file URL of the directory local repositories live in
+func (p *LocalProvider) WebURL() string {
+	return "file://" + filepath.ToSlash(p.root)
+}
+
+// AuthCodeURL returns an empty URL, local repositories need no login
+func (p *LocalProvider) AuthCodeURL(state string) string {
+	return ""
+}
+
+// Exchange is not supported, local repositories need no login
+func (p *LocalProvider) Exchange(ctx context.Context, code string) (Account, error) {
+	return Account{}, errors.New("local repositories do not use OAuth")
+}
+
+// ListRepositories lists the git repositories up to two levels below the root directory
+func (p *LocalProvider) ListRepositories(ctx context.Context, token string) ([]Repository, error) {
+	var result []Repository
+	err := filepath.WalkDir(p.root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(p.root, path)
+		if depth := strings.Count(filepath.ToSlash(rel), "/"); rel != "." && depth > 1 {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+			return nil
+		}
+
+		repo, err := p.repository(path)
+		if err != nil {
+			return nil
+		}
+		result = append(result, repo)
+		return filepath.SkipDir
+	})
+	return result, err
+}
+
+// FetchRepository reads the settings of a local repository, its default branch is the checked out branch
+func (p *LocalProvider) FetchRepository(ctx context.Context, repo Repo) (Repository, error) {
+	dir, err := p.dir(repo)
+	if err != nil {
+		return Repository{}, err
+	}
+	return p.repository(dir)
+}
+
+// repository describes the repository in a directory
+func (p *LocalProvider) repository(dir string) (Repository, error) {
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return Repository{}, err
+	}
+	branch, err := checkedOutBranch(r)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	// the owner is the parent directory, matching how site repository URLs are split
+	path := filepath.ToSlash(dir)
+	i := strings.LastIndex(path, "/")
+	owner, name := strings.TrimPrefix(path[:i], "/"), path[i+1:]
+
+	return Repository{
+		Owner:         owner,
+		Name:          name,
+		Private:       true,
+		HtmlURL:       "file://" + path,
+		DefaultBranch: branch,
+	}, nil
+}
+
+// HeadCommit returns the commit a branch points to
+func (p *LocalProvider) HeadCommit(ctx context.Context, repo Repo, branch string) (string, error) {
+	r, _, err := p.open(repo)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := branchCommit(r, branch)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// CommitTree returns the SHA of the tree of a commit
+func (p *LocalProvider) CommitTree(ctx context.Context, repo Repo, commitSHA string) (string, error) {
+	r, _, err := p.open(repo)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := resolveCommit(r, commitSHA)
+	if err != nil {
+		return "", err
+	}
+	return commit.TreeHash.String(), nil
+}
+
+// Tree lists every file in the repository at a commit
+func (p *LocalProvider) Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error) {
+	r, _, err := p.open(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(r, commitSHA)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TreeEntry
+	err = tree.Files().ForEach(func(file *object.File) error {
+		entries = append(entries, TreeEntry{
+			Path: file.Name,
+			Mode: strconv.FormatUint(uint64(file.Mode), 8),
+			Type: "blob",
+			SHA:  file.Hash.String(),
+			Size: file.Size,
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// ReadBlobs reads the text and last commit of many files
+func (p *LocalProvider) ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error) {
+	r, _, err := p.open(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(r, commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]BlobContent, len(entries))
+	for _, entry := range entries {
+		if !plumbing.IsHash(entry.SHA) {
+			return nil, fmt.Errorf("invalid blob %q", entry.SHA)
+		}
+		blob, err := r.BlobObject(plumbing.NewHash(entry.SHA))
+		if err != nil {
+			return nil, err
+		}
+		data, err := readBlob(blob)
+		if err != nil {
+			return nil, err
+		}
+
+		text, ok := textContent(data)
+		if !ok {
+			continue
+		}
+		result[entry.Path] = BlobContent{Path: entry.Path, SHA: entry.SHA, Text: text}
+	}
+
+	last, err := lastCommits(ctx, r, commit, result)
+	if err != nil {
+		return nil, err
+	}
+	for path, lastCommit := range last {
+		content := result[path]
+		content.LastCommitAuthor = lastCommit.Author.Name
+		content.LastCommitDate = lastCommit.Committer.When.Format(time.RFC3339)
+		result[path] = content
+	}
+	return result, nil
+}
+
+// lastCommits finds the last commit that changed each of the files in a single walk of the history
+func lastCommits(ctx context.Context, r *git.Repository, commit *object.Commit, files map[string]BlobContent) (map[string]*object.Commit, error) {
+	result := make(map[string]*object.Commit, len(files))
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	commits, err := r.Log(&git.LogOptions{From: commit.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	err = commits.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		changed, err := changedPaths(ctx, c)
+		if err != nil {
+			return err
+		}
+		for path := range changed {
+			if _, ok := files[path]; ok && result[path] == nil {
+				result[path] = c
+			}
+		}
+		if len(result) == len(files) {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return result, err
+}
+
+// ReadFile reads the text of a file on a branch or commit
+func (p *LocalProvider) ReadFile(ctx context.Context, repo Repo, path, ref string) (string, error) {
+	data, err := p.ReadRaw(ctx, repo, path, ref)
+	if err != nil {
+		return "", err
+	}
+
+	text, ok := textContent(data)
+	if !ok {
+		return "", fmt.Errorf("file is not a valid text file")
+	}
+	return text, nil
+}
+
+// ReadRaw reads a file from the working copy for the checked out branch, and from git for any other ref
+func (p *LocalProvider) ReadRaw(ctx context.Context, repo Repo, path, ref string) ([]byte, error) {
+	r, dir, err := p.open(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if branch, _ := checkedOutBranch(r); branch == ref {
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer root.Close()
+
+		name, err := workingCopyPath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		return root.ReadFile(name)
+	}
+
+	commit, err := resolveCommit(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return readBlob(&file.Blob)
+}
+
+// FileHistory lists the commits that changed a file, following renames
+func (p *LocalProvider) FileHistory(ctx context.Context, repo Repo, path, ref string) ([]Revision, error) {
+	r, _, err := p.open(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(r, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := r.Log(&git.LogOptions{From: commit.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	err = commits.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		changed, err := changedPaths(ctx, c)
+		if err != nil || !changed[path] {
+			return err
+		}
+		revisions = append(revisions, Revision{
+			SHA:     c.Hash.String(),
+			Path:    path,
+			Author:  c.Author.Name,
+			Date:    c.Author.When,
+			Message: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+		})
+
+		// a file added by the commit may have been renamed from another path, which older commits changed
+		previous, err := renamedFrom(ctx, c, path)
+		if err != nil || previous == "" {
+			return err
+		}
+		path = previous
+		return nil
+	})
+	return revisions, err
+}
+
+// changedPaths returns the paths of the files a commit changed compared to each of its parents, so that merges
+// only count the changes they made themselves
+func changedPaths(ctx context.Context, commit *object.Commit) (map[string]bool, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]bool
+	for i := 0; i == 0 || i < commit.NumParents(); i++ {
+		var parentTree *object.Tree
+		if commit.NumParents() > 0 {
+			parent, err := commit.Parent(i)
+			if err != nil {
+				return nil, err
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return nil, err
+			}
+		}
+
+		changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, nil)
+		if err != nil {
+			return nil, err
+		}
+		paths := make(map[string]bool, len(changes))
+		for _, change := range changes {
+			if change.From.Name != "" && (result == nil || result[change.From.Name]) {
+				paths[change.From.Name] = true
+			}
+			if change.To.Name != "" && (result == nil || result[change.To.Name]) {
+				paths[change.To.Name] = true
+			}
+		}
+		result = paths
+	}
+	return result, nil
+}
+
+// renamedFrom returns the path a file added by a commit was renamed from, or an empty path if it is a new file
+func renamedFrom(ctx context.Context, commit *object.Commit, path string) (string, error) {
+	if commit.NumParents() == 0 {
+		return "", nil
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return "", err
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return "", err
+	}
+	if _, err := parentTree.FindEntry(path); err == nil {
+		return "", nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return "", err
+	}
+	for _, change := range changes {
+		if change.To.Name == path && change.From.Name != "" {
+			return change.From.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// CommitFiles writes and deletes a set of files in a single commit, creating the branch from the
+// base branch if necessary, and pushes the branch when a remote is configured
+func (p *LocalProvider) CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error {
+	if len(input.Changes) == 0 {
+		return fmt.Errorf("at least one file change is required")
+	}
+
+	r, dir, err := p.open(repo)
+	if err != nil {
+		return err
+	}
+
+	lock, _ := p.locks.LoadOrStore(dir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	checkedOut, _ := checkedOutBranch(r)
+	if input.Branch == checkedOut {
+		err = p.commitWorkingCopy(r, dir, input)
+	} else {
+		_, err = p.commitBranch(r, input)
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.pushRemote != "" {
+		if err := p.push(ctx, r, input.Branch); err != nil {
+			// the commit is kept locally and goes out with the next successful push
+			log.Printf("Failed to push %s to %s: %v", input.Branch, p.pushRemote, err)
+		}
+	}
+	return nil
+}
+
+// commitWorkingCopy commits the changes to the checked out branch, then writes them to the working copy
+// and stages them, so that only the changed paths are committed and the working copy matches the branch
+func (p *LocalProvider) commitWorkingCopy(r *git.Repository, dir string, input CommitFilesInput) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	// every path is checked before anything is committed or written
+	names := make([]string, len(input.Changes))
+	for i, change := range input.Changes {
+		if names[i], err = workingCopyPath(root, change.Path); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.commitBranch(r, input); err != nil {
+		return err
+	}
+
+	worktree, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	for i, change := range input.Changes {
+		if change.Delete {
+			if err := root.Remove(names[i]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		} else {
+			if err := root.MkdirAll(filepath.Dir(names[i]), 0o755); err != nil {
+				return err
+			}
+			if err := root.WriteFile(names[i], []byte(change.Content), 0o644); err != nil {
+				return err
+			}
+		}
+		if err := worktree.AddWithOptions(&git.AddOptions{Path: change.Path, SkipStatus: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitBranch commits the changes on top of a branch, or of the base branch when the branch does not exist yet,
+// without touching the working copy. Saving unchanged content does not create an empty commit.
+func (p *LocalProvider) commitBranch(r *git.Repository, input CommitFilesInput) (bool, error) {
+	branch := plumbing.NewBranchReferenceName(input.Branch)
+	parentHash, err := branchCommit(r, input.Branch)
+	created := false
+	if errors.Is(err, errBranchNotFound) {
+		parentHash, err = branchCommit(r, input.BaseBranch)
+		created = true
+	}
+	if err != nil {
+		return false, err
+	}
+
+	parent, err := r.CommitObject(parentHash)
+	if err != nil {
+		return false, err
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	changes := make(map[string]*plumbing.Hash, len(input.Changes))
+	for _, change := range input.Changes {
+		path, err := repositoryPath(change.Path)
+		if err != nil {
+			return false, err
+		}
+		if change.Delete {
+			changes[path] = nil
+			continue
+		}
+		blob, err := writeBlob(r.Storer, []byte(change.Content))
+		if err != nil {
+			return false, err
+		}
+		changes[path] = &blob
+	}
+
+	treeHash, err := updateTree(r.Storer, parentTree, changes)
+	if err != nil {
+		return false, err
+	}
+	if treeHash == parent.TreeHash && !created {
+		return false, nil
+	}
+
+	signature := object.Signature{Name: p.authorName, Email: p.authorEmail, When: time.Now()}
+	commitHash, err := writeObject(r.Storer, &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      input.CommitMsg,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{parentHash},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// the expected old value guards against concurrent updates of the branch
+	var old *plumbing.Reference
+	if !created {
+		old = plumbing.NewHashReference(branch, parentHash)
+	}
+	if err := r.Storer.CheckAndSetReference(plumbing.NewHashReference(branch, commitHash), old); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateTree writes a copy of a tree with files replaced by new blobs or deleted for nil blobs, and returns
+// its hash. Emptied directories are removed.
+func updateTree(s storer.EncodedObjectStorer, base *object.Tree, changes map[string]*plumbing.Hash) (plumbing.Hash, error) {
+	entries := make(map[string]object.TreeEntry)
+	if base != nil {
+		for _, entry := range base.Entries {
+			entries[entry.Name] = entry
+		}
+	}
+
+	// changes below a directory are applied to its tree
+	subtrees := make(map[string]map[string]*plumbing.Hash)
+	for path, blob := range changes {
+		name, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			if blob == nil {
+				delete(entries, name)
+				continue
+			}
+			mode := filemode.Regular
+			if entry, ok := entries[name]; ok && entry.Mode == filemode.Executable {
+				mode = filemode.Executable
+			}
+			entries[name] = object.TreeEntry{Name: name, Mode: mode, Hash: *blob}
+			continue
+		}
+		if subtrees[name] == nil {
+			subtrees[name] = make(map[string]*plumbing.Hash)
+		}
+		subtrees[name][rest] = blob
+	}
+
+	for name, subtreeChanges := range subtrees {
+		var subtree *object.Tree
+		if entry, ok := entries[name]; ok {
+			if entry.Mode != filemode.Dir {
+				return plumbing.ZeroHash, fmt.Errorf("%s is not a directory", name)
+			}
+			var err error
+			if subtree, err = object.GetTree(s, entry.Hash); err != nil {
+				return plumbing.ZeroHash, err
+			}
+		}
+
+		hash, err := updateTree(s, subtree, subtreeChanges)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if hash == emptyTreeHash {
+			delete(entries, name)
+		} else {
+			entries[name] = object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash}
+		}
+	}
+
+	tree := &object.Tree{}
+	for _, entry := range entries {
+		tree.Entries = append(tree.Entries, entry)
+	}
+	// git sorts directories as if their name ended with a slash
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+	return writeObject(s, tree)
+}
+
+// emptyTreeHash is the hash of a tree without entries
+var emptyTreeHash = plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+
+// writeObject stores a tree or commit and returns its hash
+func writeObject(s storer.EncodedObjectStorer, value interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	if err := value.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.SetEncodedObject(obj)
+}
+
+// writeBlob stores the content of a file and returns its hash
+func writeBlob(s storer.EncodedObjectStorer, content []byte) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.SetEncodedObject(obj)
+}
+
+// readBlob reads the content of a blob
+func readBlob(blob *object.Blob) ([]byte, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// push pushes a branch to the configured remote, which is either the name of a remote of the repository or a URL
+func (p *LocalProvider) push(ctx context.Context, r *git.Repository, branch string) error {
+	remote, err := r.Remote(p.pushRemote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		remote = git.NewRemote(r.Storer, &gitconfig.RemoteConfig{Name: p.pushRemote, URLs: []string{p.pushRemote}})
+	} else if err != nil {
+		return err
+	}
+
+	ref := plumbing.NewBranchReferenceName(branch)
+	err = remote.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote.Config().Name,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(ref + ":" + ref)},
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// CreateReviewRequest returns an empty review request, changes to local repositories are reviewed on their branch
+func (p *LocalProvider) CreateReviewRequest(ctx context.Context, repo Repo, input ReviewRequestInput) (ReviewRequest, error) {
+	return ReviewRequest{}, nil
+}
+
+// dir returns the directory of a repository, which must be inside the root directory
+func (p *LocalProvider) dir(repo Repo) (string, error) {
+	dir := filepath.Clean("/" + repo.Owner + "/" + repo.Name)
+	rel, err := filepath.Rel(p.root, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("repository %s is outside of %s", dir, p.root)
+	}
+	return dir, nil
+}
+
+// open opens the git repository of a site and returns it with its directory
+func (p *LocalProvider) open(repo Repo) (*git.Repository, string, error) {
+	dir, err := p.dir(repo)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open repository %s: %w", dir, err)
+	}
+	return r, dir, nil
+}
+
+// checkedOutBranch returns the branch checked out in the working copy
+func checkedOutBranch(r *git.Repository) (string, error) {
+	head, err := r.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", errors.New("HEAD is not on a branch")
+	}
+	return head.Target().Short(), nil
+}
+
+// branchCommit returns the commit a branch points to
+func branchCommit(r *git.Repository, branch string) (plumbing.Hash, error) {
+	name := plumbing.NewBranchReferenceName(branch)
+	if branch == "" || name.Validate() != nil {
+		return plumbing.ZeroHash, fmt.Errorf("invalid branch %q", branch)
+	}
+
+	ref, err := r.Reference(name, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return plumbing.ZeroHash, fmt.Errorf("%s: %w", branch, errBranchNotFound)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return ref.Hash(), nil
+}
+
+// resolveCommit resolves a branch, tag, commit SHA or revision expression to its commit
+func resolveCommit(r *git.Repository, ref string) (*object.Commit, error) {
+	if ref == "" {
+		return nil, errors.New("invalid ref")
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("ref %q not found", ref)
+	}
+	return r.CommitObject(*hash)
+}
+
+// repositoryPath cleans a path of a file in the repository, which must not leave it or point into the git directory
+func repositoryPath(path string) (string, error) {
+	name := filepath.ToSlash(filepath.Clean(filepath.FromSlash(path)))
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("invalid path %s", path)
+	}
+	if first, _, _ := strings.Cut(name, "/"); strings.EqualFold(first, ".git") {
+		return "", fmt.Errorf("invalid path %s", path)
+	}
+	return name, nil
+}
+
+// workingCopyPath resolves a repository path inside the working copy. Paths going through a symbolic link are
+// rejected, so that files are never read or written in another place of the working copy than the one requested.
+func workingCopyPath(root *os.Root, path string) (string, error) {
+	name, err := repositoryPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		info, err := root.Lstat(filepath.Join(parts[:i+1]...))
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("invalid path %s: %s is a symbolic link", path, strings.Join(parts[:i+1], "/"))
+		}
+	}
+	return filepath.FromSlash(name), nil
+}
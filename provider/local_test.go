@@ -0,0 +1,339 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// newLocalTestRepo creates a repository with one commit on main and returns the provider and repository
+func newLocalTestRepo(t *testing.T) (*LocalProvider, Repo, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "site")
+	r, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.Main},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{
+		Author:            &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &LocalProvider{root: root, authorName: "Test", authorEmail: "test@example.com"}
+	repo := Repo{Owner: strings.TrimPrefix(filepath.ToSlash(root), "/"), Name: "site"}
+	return p, repo, dir
+}
+
+// commitLocal commits changes to a branch of a test repository
+func commitLocal(t *testing.T, p *LocalProvider, repo Repo, branch, message string, changes ...FileChange) {
+	t.Helper()
+	err := p.CommitFiles(context.Background(), repo, CommitFilesInput{
+		Branch:     branch,
+		BaseBranch: "main",
+		CommitMsg:  message,
+		Changes:    changes,
+	})
+	if err != nil {
+		t.Fatalf("CommitFiles(%s) error = %v", message, err)
+	}
+}
+
+// treePaths lists the files of a branch with their modes
+func treePaths(t *testing.T, p *LocalProvider, repo Repo, ref string) map[string]string {
+	t.Helper()
+	entries, err := p.Tree(context.Background(), repo, ref)
+	if err != nil {
+		t.Fatalf("Tree(%s) error = %v", ref, err)
+	}
+	paths := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		paths[entry.Path] = entry.Mode
+	}
+	return paths
+}
+
+func TestLocalProviderRejectsOptionRefs(t *testing.T) {
+	p, repo, dir := newLocalTestRepo(t)
+	ctx := context.Background()
+	output := filepath.Join(t.TempDir(), "pwned")
+
+	refs := []string{"--output=" + output, "-p", "--output=viewerfile", "main..other", ""}
+	for _, ref := range refs {
+		if _, err := p.ReadRaw(ctx, repo, "y", ref); err == nil {
+			t.Errorf("ReadRaw(%q) succeeded, want error", ref)
+		}
+		if _, err := p.FileHistory(ctx, repo, "y", ref); err == nil {
+			t.Errorf("FileHistory(%q) succeeded, want error", ref)
+		}
+		if _, err := p.CommitTree(ctx, repo, ref); err == nil {
+			t.Errorf("CommitTree(%q) succeeded, want error", ref)
+		}
+		if _, err := p.Tree(ctx, repo, ref); err == nil {
+			t.Errorf("Tree(%q) succeeded, want error", ref)
+		}
+	}
+
+	if _, err := os.Stat(output); err == nil {
+		t.Errorf("%s was written", output)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.Name() != ".git" {
+			t.Errorf("unexpected file %s in the working copy", entry.Name())
+		}
+	}
+}
+
+func TestLocalProviderResolvesRefs(t *testing.T) {
+	p, repo, _ := newLocalTestRepo(t)
+	ctx := context.Background()
+
+	head, err := p.HeadCommit(ctx, repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{"main", head, head[:7], "HEAD"} {
+		if _, err := p.CommitTree(ctx, repo, ref); err != nil {
+			t.Errorf("CommitTree(%q): %v", ref, err)
+		}
+		if revisions, err := p.FileHistory(ctx, repo, "missing.md", ref); err != nil || len(revisions) != 0 {
+			t.Errorf("FileHistory(%q) = %v, %v", ref, revisions, err)
+		}
+	}
+
+	for _, branch := range []string{"missing", "../main", ""} {
+		if _, err := p.HeadCommit(ctx, repo, branch); err == nil {
+			t.Errorf("HeadCommit(%q) succeeded, want error", branch)
+		}
+	}
+}
+
+func TestLocalProviderCommitWorkingCopy(t *testing.T) {
+	p, repo, dir := newLocalTestRepo(t)
+	ctx := context.Background()
+
+	commitLocal(t, p, repo, "main", "Add posts",
+		FileChange{Path: "content/posts/a.md", Content: "# A\n"},
+		FileChange{Path: "content/posts/b.md", Content: "# B\n"},
+	)
+	head, err := p.HeadCommit(ctx, repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "content/posts/a.md")); err != nil || string(data) != "# A\n" {
+		t.Errorf("working copy file = %q, %v", data, err)
+	}
+	want := map[string]string{"content/posts/a.md": "100644", "content/posts/b.md": "100644"}
+	if got := treePaths(t, p, repo, "main"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %v, want %v", got, want)
+	}
+
+	// saving unchanged content does not create a commit
+	commitLocal(t, p, repo, "main", "Save again", FileChange{Path: "content/posts/a.md", Content: "# A\n"})
+	if again, _ := p.HeadCommit(ctx, repo, "main"); again != head {
+		t.Errorf("saving unchanged content moved main from %s to %s", head, again)
+	}
+
+	commitLocal(t, p, repo, "main", "Delete post", FileChange{Path: "content/posts/b.md", Delete: true})
+	if _, err := os.Stat(filepath.Join(dir, "content/posts/b.md")); !os.IsNotExist(err) {
+		t.Errorf("deleted file is still in the working copy: %v", err)
+	}
+	want = map[string]string{"content/posts/a.md": "100644"}
+	if got := treePaths(t, p, repo, "main"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %v, want %v", got, want)
+	}
+
+	// the index matches the commits, so the working copy is clean
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := r.Worktree()
+	if status, err := worktree.Status(); err != nil || !status.IsClean() {
+		t.Errorf("working copy status = %v, %v, want clean", status, err)
+	}
+
+	entries, err := p.Tree(ctx, repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := p.ReadBlobs(ctx, repo, "main", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blob := blobs["content/posts/a.md"]; blob.Text != "# A\n" || blob.LastCommitAuthor != "Test" || blob.LastCommitDate == "" {
+		t.Errorf("ReadBlobs() = %+v", blob)
+	}
+}
+
+func TestLocalProviderCommitBranch(t *testing.T) {
+	p, repo, dir := newLocalTestRepo(t)
+	ctx := context.Background()
+
+	commitLocal(t, p, repo, "main", "Add post", FileChange{Path: "content/a.md", Content: "original"})
+	commitLocal(t, p, repo, "update-a", "Update post",
+		FileChange{Path: "content/a.md", Content: "updated"},
+		FileChange{Path: "static/new.txt", Content: "new"},
+	)
+
+	// the working copy and checked out branch are untouched
+	if data, err := os.ReadFile(filepath.Join(dir, "content/a.md")); err != nil || string(data) != "original" {
+		t.Errorf("working copy file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "static")); !os.IsNotExist(err) {
+		t.Errorf("branch file was written to the working copy: %v", err)
+	}
+	if text, err := p.ReadFile(ctx, repo, "content/a.md", "main"); err != nil || text != "original" {
+		t.Errorf("ReadFile(main) = %q, %v", text, err)
+	}
+
+	if text, err := p.ReadFile(ctx, repo, "content/a.md", "update-a"); err != nil || text != "updated" {
+		t.Errorf("ReadFile(update-a) = %q, %v", text, err)
+	}
+	want := map[string]string{"content/a.md": "100644", "static/new.txt": "100644"}
+	if got := treePaths(t, p, repo, "update-a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %v, want %v", got, want)
+	}
+
+	// deleting the last file of a directory removes it
+	commitLocal(t, p, repo, "update-a", "Delete file", FileChange{Path: "static/new.txt", Delete: true})
+	want = map[string]string{"content/a.md": "100644"}
+	if got := treePaths(t, p, repo, "update-a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tree() = %v, want %v", got, want)
+	}
+
+	err := p.CommitFiles(ctx, repo, CommitFilesInput{
+		Branch:     "update-b",
+		BaseBranch: "missing",
+		CommitMsg:  "Update",
+		Changes:    []FileChange{{Path: "content/b.md", Content: "b"}},
+	})
+	if err == nil {
+		t.Error("CommitFiles() from a missing base branch succeeded, want error")
+	}
+}
+
+func TestLocalProviderFileHistory(t *testing.T) {
+	p, repo, _ := newLocalTestRepo(t)
+	ctx := context.Background()
+	content := strings.Repeat("A line of the post that stays the same.\n", 20)
+
+	commitLocal(t, p, repo, "main", "Add post", FileChange{Path: "content/old.md", Content: content})
+	commitLocal(t, p, repo, "main", "Add other post", FileChange{Path: "content/other.md", Content: "other"})
+	commitLocal(t, p, repo, "main", "Rename post",
+		FileChange{Path: "content/old.md", Delete: true},
+		FileChange{Path: "content/new.md", Content: content},
+	)
+	commitLocal(t, p, repo, "main", "Edit post", FileChange{Path: "content/new.md", Content: content + "More.\n"})
+
+	revisions, err := p.FileHistory(ctx, repo, "content/new.md", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, revision := range revisions {
+		got = append(got, revision.Message+" "+revision.Path)
+		if revision.Author != "Test" || revision.SHA == "" || revision.Date.IsZero() {
+			t.Errorf("revision = %+v", revision)
+		}
+	}
+	want := []string{"Edit post content/new.md", "Rename post content/new.md", "Add post content/old.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FileHistory() = %v, want %v", got, want)
+	}
+}
+
+func TestLocalProviderRejectsPaths(t *testing.T) {
+	p, repo, dir := newLocalTestRepo(t)
+	ctx := context.Background()
+
+	for _, path := range []string{"../outside.md", ".git/config", ".GIT/hooks/pre-commit", "/etc/passwd", "."} {
+		err := p.CommitFiles(ctx, repo, CommitFilesInput{
+			Branch:    "main",
+			CommitMsg: "Escape",
+			Changes:   []FileChange{{Path: path, Content: "x"}},
+		})
+		if err == nil {
+			t.Errorf("CommitFiles(%q) succeeded, want error", path)
+		}
+	}
+
+	config, err := os.ReadFile(filepath.Join(dir, ".git/config"))
+	if err != nil || string(config) == "x" {
+		t.Errorf(".git/config = %q, %v", config, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "outside.md")); !os.IsNotExist(err) {
+		t.Errorf("file was written outside of the working copy: %v", err)
+	}
+}
+
+func TestLocalProviderSymlinks(t *testing.T) {
+	p, repo, dir := newLocalTestRepo(t)
+	ctx := context.Background()
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "content"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"content/secret.md": secret,
+		"linked":            outside,
+		"content/config.md": filepath.Join(dir, ".git", "config"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+	}
+
+	for _, path := range []string{"content/secret.md", "linked/secret.txt", "content/config.md"} {
+		if data, err := p.ReadRaw(ctx, repo, path, "main"); err == nil {
+			t.Errorf("ReadRaw(%q) = %q, want error", path, data)
+		}
+
+		err := p.CommitFiles(ctx, repo, CommitFilesInput{
+			Branch:    "main",
+			CommitMsg: "Overwrite",
+			Changes:   []FileChange{{Path: path, Content: "overwritten"}},
+		})
+		if err == nil {
+			t.Errorf("CommitFiles(%q) succeeded, want error", path)
+		}
+	}
+
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file outside of the working copy = %q, %v", data, err)
+	}
+	if config, err := os.ReadFile(filepath.Join(dir, ".git", "config")); err != nil || strings.Contains(string(config), "overwritten") {
+		t.Errorf(".git/config = %q, %v", config, err)
+	}
+	if got := treePaths(t, p, repo, "main"); len(got) != 0 {
+		t.Errorf("Tree() = %v, want no files", got)
+	}
+}
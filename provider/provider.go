@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"static-admin/database"
+	"static-admin/github"
+)
+
+const (
+	// GitHub hosts sites on github.com or GitHub Enterprise
+	GitHub = "github"
+
+	// GitLab hosts sites on gitlab.com or a self-managed GitLab instance
+	GitLab = "gitlab"
+
+	// Gitea hosts sites on a Gitea or Forgejo instance
+	Gitea = "gitea"
+
+	// Local stores sites in git working copies on the same machine
+	Local = "local"
+)
+
+// maxRenameHops limits how many renames are followed when listing the history of a file
+const maxRenameHops = 10
+
+var (
+	// ErrProtectedBranch is returned when a commit is rejected because the branch is protected
+	ErrProtectedBranch = github.ErrProtectedBranch
+
+	// ErrNotConfigured is returned for providers that have no OAuth application configured
+	ErrNotConfigured = errors.New("provider is not configured")
+)
+
+type (
+	// TreeEntry is a single file in a repository tree
+	TreeEntry = github.TreeEntry
+
+	// BlobContent is the text of a file together with its last commit
+	BlobContent = github.BlobContent
+
+	// FileChange is a single file write or deletion within a commit
+	FileChange = github.FileChange
+
+	// Revision is a commit that changed a file, together with the path of the file in that commit
+	Revision = github.FileRevision
+)
+
+// Repo identifies a repository and the token used to access it
+type Repo struct {
+	// Owner is the user, organization or group owning the repository, GitLab subgroups are separated by slashes
+	Owner string
+	Name  string
+	Token string
+}
+
+// Repository represents a repository on a git hosting provider
+type Repository struct {
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	HtmlURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// Account represents the user a provider OAuth token belongs to
+type Account struct {
+	Login       string
+	Name        string
+	URL         string
+	AccessToken string
+}
+
+// CommitFilesInput represents a set of file changes to commit to a branch
+type CommitFilesInput struct {
+	// Branch is created from BaseBranch if it does not exist yet
+	Branch     string
+	BaseBranch string
+	Changes    []FileChange
+	CommitMsg  string
+}
+
+// ReviewRequestInput represents the pull or merge request to open for a branch
+type ReviewRequestInput struct {
+	Branch     string
+	BaseBranch string
+	Title      string
+	Body       string
+}
+
+// ReviewRequest represents an open pull request or merge request
+type ReviewRequest struct {
+	Number int64
+	URL    string
+}
+
+// Provider is a git hosting service that sites can be published to
+type Provider interface {
+	// Name returns the identifier stored on sites using the provider
+	Name() string
+
+	// WebURL returns the root of the provider's web interface, which repository URLs start with
+	WebURL() string
+
+	// AuthCodeURL returns the URL of the OAuth consent page
+	AuthCodeURL(state string) string
+
+	// Exchange trades an OAuth code for a token and looks up the account it belongs to
+	Exchange(ctx context.Context, code string) (Account, error)
+
+	// ListRepositories lists the repositories the token can access
+	ListRepositories(ctx context.Context, token string) ([]Repository, error)
+
+	// FetchRepository reads the settings of a single repository
+	FetchRepository(ctx context.Context, repo Repo) (Repository, error)
+
+	// HeadCommit returns the commit a branch points to
+	HeadCommit(ctx context.Context, repo Repo, branch string) (string, error)
+
+	// CommitTree returns an identifier of the tree of a commit, which is unchanged by commits that change no files
+	CommitTree(ctx context.Context, repo Repo, commitSHA string) (string, error)
+
+	// Tree lists every file in the repository at a commit
+	Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error)
+
+	// ReadBlobs reads the text of many files at a commit, skipping binary files
+	ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error)
+
+	// ReadFile reads the text of a file on a branch or commit
+	ReadFile(ctx context.Context, repo Repo, path, ref string) (string, error)
+
+	// ReadRaw reads the bytes of a file on a branch or commit, including binary files
+	ReadRaw(ctx context.Context, repo Repo, path, ref string) ([]byte, error)
+
+	// FileHistory lists the commits that changed a file on a branch or commit, newest first,
+	// following renames where the provider reports them
+	FileHistory(ctx context.Context, repo Repo, path, ref string) ([]Revision, error)
+
+	// CommitFiles writes and deletes a set of files in a single commit
+	CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error
+
+	// CreateReviewRequest opens a pull or merge request for a branch, reusing an open one if it exists
+	// and replacing its description with the given body
+	CreateReviewRequest(ctx context.Context, repo Repo, input ReviewRequestInput) (ReviewRequest, error)
+}
+
+var (
+	providers     = map[string]Provider{}
+	providersLock sync.RWMutex
+)
+
+// Register makes a provider available to sites
+func Register(p Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	providers[p.Name()] = p
+}
+
+// Get returns a registered provider by name
+func Get(name string) (Provider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotConfigured)
+	}
+	return p, nil
+}
+
+// Names returns the names of the registered providers
+func Names() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Valid returns true if the given name is a known provider
+func Valid(name string) bool {
+	return name == GitHub || name == GitLab || name == Gitea || name == Local
+}
+
+// ForSite returns the provider of a site, limited to the site's root path.
+// Sites created before providers were selectable use GitHub.
+func ForSite(site database.Site) (Provider, error) {
+	name := site.Provider
+	if name == "" {
+		name = GitHub
+	}
+
+	p, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return Scoped(p, site.RootPath), nil
+}
+
+// RepoForSite returns the provider and repository of a site
+func RepoForSite(site database.Site, token string) (Provider, Repo, error) {
+	p, err := ForSite(site)
+	if err != nil {
+		return nil, Repo{}, err
+	}
+
+	owner, name, ok := site.OwnerAndRepo()
+	if !ok {
+		return nil, Repo{}, errors.New("invalid repository URL")
+	}
+
+	return p, Repo{Owner: owner, Name: name, Token: token}, nil
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// scopedProvider restricts a provider to the files below the root path of a site in a monorepo.
+// Paths passed to and returned from the provider are relative to the root path.
+type scopedProvider struct {
+	Provider
+	root string
+}
+
+// Scoped returns a provider limited to the files below root, or the provider itself for an empty root
+func Scoped(p Provider, root string) Provider {
+	if root == "" {
+		return p
+	}
+	return scopedProvider{Provider: p, root: root}
+}
+
+// Tree lists the files below the root path at a commit
+func (p scopedProvider) Tree(ctx context.Context, repo Repo, commitSHA string) ([]TreeEntry, error) {
+	entries, err := p.Provider.Tree(ctx, repo, commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := p.root + "/"
+	result := make([]TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Path, prefix) {
+			continue
+		}
+		entry.Path = strings.TrimPrefix(entry.Path, prefix)
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// ReadBlobs reads the text of many files below the root path
+func (p scopedProvider) ReadBlobs(ctx context.Context, repo Repo, commitSHA string, entries []TreeEntry) (map[string]BlobContent, error) {
+	full := make([]TreeEntry, len(entries))
+	for i, entry := range entries {
+		entry.Path = path.Join(p.root, entry.Path)
+		full[i] = entry
+	}
+
+	blobs, err := p.Provider.ReadBlobs(ctx, repo, commitSHA, full)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]BlobContent, len(blobs))
+	for fullPath, blob := range blobs {
+		blob.Path = strings.TrimPrefix(fullPath, p.root+"/")
+		result[blob.Path] = blob
+	}
+	return result, nil
+}
+
+// ReadFile reads the text of a file below the root path
+func (p scopedProvider) ReadFile(ctx context.Context, repo Repo, filePath, ref string) (string, error) {
+	fullPath, err := p.fullPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	return p.Provider.ReadFile(ctx, repo, fullPath, ref)
+}
+
+// ReadRaw reads the bytes of a file below the root path
+func (p scopedProvider) ReadRaw(ctx context.Context, repo Repo, filePath, ref string) ([]byte, error) {
+	fullPath, err := p.fullPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return p.Provider.ReadRaw(ctx, repo, fullPath, ref)
+}
+
+// FileHistory lists the commits that changed a file below the root path
+func (p scopedProvider) FileHistory(ctx context.Context, repo Repo, filePath, ref string) ([]Revision, error) {
+	fullPath, err := p.fullPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := p.Provider.FileHistory(ctx, repo, fullPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		revisions[i].Path = strings.TrimPrefix(revisions[i].Path, p.root+"/")
+	}
+	return revisions, nil
+}
+
+// CommitFiles writes and deletes a set of files below the root path in a single commit
+func (p scopedProvider) CommitFiles(ctx context.Context, repo Repo, input CommitFilesInput) error {
+	changes := make([]FileChange, len(input.Changes))
+	for i, change := range input.Changes {
+		fullPath, err := p.fullPath(change.Path)
+		if err != nil {
+			return err
+		}
+		change.Path = fullPath
+		changes[i] = change
+	}
+	input.Changes = changes
+
+	return p.Provider.CommitFiles(ctx, repo, input)
+}
+
+// fullPath returns the repository path of a path relative to the root path, which must not leave the root path
+func (p scopedProvider) fullPath(filePath string) (string, error) {
+	fullPath := path.Join(p.root, filePath)
+	if !strings.HasPrefix(fullPath, p.root+"/") {
+		return "", fmt.Errorf("path %s is outside of the site root %s", filePath, p.root)
+	}
+	return fullPath, nil
+}
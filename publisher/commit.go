@@ -0,0 +1,193 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"static-admin/contentdiff"
+	"static-admin/database"
+	"static-admin/provider"
+)
+
+// CommitInput represents a set of file changes to publish to a site's repository
+type CommitInput struct {
+	Site  database.Site
+	Owner string
+	Repo  string
+	Token string
+
+	// ReviewBranch is the branch used when the changes go through a pull request
+	ReviewBranch string
+
+	// Title and Body describe the pull request, if one is created
+	Title string
+	Body  string
+
+	CommitMsg string
+	Changes   []provider.FileChange
+}
+
+// CommitResult describes where a set of changes ended up
+type CommitResult struct {
+	Message  string
+	Mode     string
+	Branch   string
+	PRNumber int64
+	PRURL    string
+}
+
+// Commit commits file changes according to the site's publishing mode,
+// falling back to a pull request when the target branch is protected
+func Commit(ctx context.Context, input CommitInput) (CommitResult, error) {
+	p, err := provider.ForSite(input.Site)
+	if err != nil {
+		return CommitResult{}, err
+	}
+	repo := provider.Repo{Owner: input.Owner, Name: input.Repo, Token: input.Token}
+
+	mode := input.Site.PublishingMode
+	if mode == "" {
+		mode = database.PublishingModePullRequest
+	}
+
+	message := ""
+	if mode != database.PublishingModePullRequest {
+		targetBranch := input.Site.Branch()
+		if mode == database.PublishingModeStaging {
+			targetBranch = input.Site.StagingBranch
+		}
+
+		err := p.CommitFiles(ctx, repo, provider.CommitFilesInput{
+			Branch:     targetBranch,
+			BaseBranch: input.Site.Branch(),
+			Changes:    input.Changes,
+			CommitMsg:  input.CommitMsg,
+		})
+		if err == nil {
+			return CommitResult{
+				Message: fmt.Sprintf("Committed changes to %s", targetBranch),
+				Mode:    mode,
+				Branch:  targetBranch,
+			}, nil
+		}
+
+		if !errors.Is(err, provider.ErrProtectedBranch) {
+			return CommitResult{}, fmt.Errorf("Failed to commit to %s: %v", targetBranch, err)
+		}
+
+		// fall back to a review branch when the target branch cannot be pushed to
+		message = fmt.Sprintf("Branch %s is protected, created pull request for changes instead", targetBranch)
+		mode = database.PublishingModePullRequest
+	}
+
+	err = p.CommitFiles(ctx, repo, provider.CommitFilesInput{
+		Branch:     input.ReviewBranch,
+		BaseBranch: input.Site.Branch(),
+		Changes:    input.Changes,
+		CommitMsg:  input.CommitMsg,
+	})
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("Failed to create branch and update file: %v", err)
+	}
+
+	// reviewers of repositories holding several sites need to know which site a change belongs to
+	title := input.Title
+	if input.Site.RootPath != "" {
+		title = fmt.Sprintf("[%s] %s", input.Site.RootPath, title)
+	}
+
+	review, err := p.CreateReviewRequest(ctx, repo, provider.ReviewRequestInput{
+		Branch:     input.ReviewBranch,
+		BaseBranch: input.Site.Branch(),
+		Title:      title,
+		Body:       input.Body,
+	})
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("Failed to create pull request: %v", err)
+	}
+
+	if message == "" {
+		message = "Created pull request for changes"
+		if review.URL == "" {
+			// local sites have no pull requests, changes are reviewed on their branch
+			message = fmt.Sprintf("Committed changes to review branch %s", input.ReviewBranch)
+		}
+	}
+
+	return CommitResult{
+		Message:  message,
+		Mode:     mode,
+		Branch:   input.ReviewBranch,
+		PRNumber: review.Number,
+		PRURL:    review.URL,
+	}, nil
+}
+
+// FetchFile reads a file from the first branch it can be found on, which lets
+// pending changes on a review branch take precedence over the default branch
+func FetchFile(ctx context.Context, site database.Site, owner, repo, filePath, token string, branches ...string) (string, error) {
+	p, err := provider.ForSite(site)
+	if err != nil {
+		return "", err
+	}
+
+	var lastErr error
+	for _, branch := range branches {
+		if branch == "" {
+			continue
+		}
+
+		content, err := p.ReadFile(ctx, provider.Repo{Owner: owner, Name: repo, Token: token}, filePath, branch)
+		if err == nil {
+			return content, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no branch to fetch %s from", filePath)
+	}
+	return "", lastErr
+}
+
+// FetchRaw reads the bytes of a file, including binary files, from the first branch it can be found on
+func FetchRaw(ctx context.Context, site database.Site, owner, repo, filePath, token string, branches ...string) ([]byte, error) {
+	p, err := provider.ForSite(site)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, branch := range branches {
+		if branch == "" {
+			continue
+		}
+
+		data, err := p.ReadRaw(ctx, provider.Repo{Owner: owner, Name: repo, Token: token}, filePath, branch)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no branch to fetch %s from", filePath)
+	}
+	return nil, lastErr
+}
+
+// ReviewBody describes the changes to a post for the body of its pull request. The post is compared
+// with its version at basePath on the site branch, so the body covers every change under review.
+func ReviewBody(ctx context.Context, site database.Site, owner, repo, token, basePath, summary, content string) string {
+	// new posts do not exist on the site branch yet
+	base, err := FetchFile(ctx, site, owner, repo, basePath, token, site.Branch())
+	if err != nil {
+		base = ""
+	}
+
+	diff, err := contentdiff.CompareContent(base, content)
+	if err != nil {
+		return summary
+	}
+	return summary + "\n\n" + diff.Markdown()
+}
@@ -0,0 +1,114 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"static-admin/database"
+	"static-admin/provider"
+)
+
+// Revision represents a commit that changed a post, on the site branch or on an unmerged review branch
+type Revision struct {
+	provider.Revision
+	Branch string
+	Merged bool
+}
+
+// History lists the revisions of a post, newest first. Commits on the review branches of the post
+// that are not on the site branch are included as unmerged revisions.
+func History(ctx context.Context, site database.Site, owner, repo, token, postPath string) ([]Revision, error) {
+	p, err := provider.ForSite(site)
+	if err != nil {
+		return nil, err
+	}
+	providerRepo := provider.Repo{Owner: owner, Name: repo, Token: token}
+
+	merged, err := p.FileHistory(ctx, providerRepo, postPath, site.Branch())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(merged))
+	revisions := make([]Revision, 0, len(merged))
+	for _, revision := range merged {
+		seen[revision.SHA] = true
+		revisions = append(revisions, Revision{Revision: revision, Branch: site.Branch(), Merged: true})
+	}
+
+	for _, branch := range []string{ReviewBranch(site, postPath), CreateBranch(site, postPath)} {
+		// posts without pending changes have no review branch
+		if _, err := p.HeadCommit(ctx, providerRepo, branch); err != nil {
+			continue
+		}
+
+		pending, err := p.FileHistory(ctx, providerRepo, postPath, branch)
+		if err != nil {
+			return nil, err
+		}
+		for _, revision := range pending {
+			if seen[revision.SHA] {
+				continue
+			}
+			seen[revision.SHA] = true
+			revisions = append(revisions, Revision{Revision: revision, Branch: branch})
+		}
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Date.After(revisions[j].Date)
+	})
+	return revisions, nil
+}
+
+// RestoreInput represents a request to restore a past revision of a post
+type RestoreInput struct {
+	Site  database.Site
+	Owner string
+	Repo  string
+	Token string
+
+	// Path is the current path of the post, which the revision is written to
+	Path string
+
+	// SHA and RevisionPath identify the revision, the path differs from Path when the post was renamed since
+	SHA          string
+	RevisionPath string
+}
+
+// Restore writes the content of a past revision to the post on a new review branch and opens a pull request,
+// regardless of the site's publishing mode
+func Restore(ctx context.Context, input RestoreInput) (CommitResult, error) {
+	content, err := FetchFile(ctx, input.Site, input.Owner, input.Repo, input.RevisionPath, input.Token, input.SHA)
+	if err != nil {
+		return CommitResult{}, fmt.Errorf("Failed to fetch revision: %w", err)
+	}
+
+	shortSHA := input.SHA
+	if len(shortSHA) > 7 {
+		shortSHA = shortSHA[:7]
+	}
+
+	// restores always go through review, as they can undo any number of later changes
+	site := input.Site
+	site.PublishingMode = database.PublishingModePullRequest
+
+	fileName := filepath.Base(input.Path)
+	return Commit(ctx, CommitInput{
+		Site:         site,
+		Owner:        input.Owner,
+		Repo:         input.Repo,
+		Token:        input.Token,
+		ReviewBranch: fmt.Sprintf("%s-%s", branchName(site, "restore", input.Path), shortSHA),
+		Title:        fmt.Sprintf("Restore %s to %s", fileName, shortSHA),
+		Body:         ReviewBody(ctx, site, input.Owner, input.Repo, input.Token, input.Path, fmt.Sprintf("Restores `%s` to the revision of commit %s.", site.RepositoryPath(input.Path), input.SHA), content),
+		CommitMsg:    fmt.Sprintf("Restore %s to %s", input.Path, shortSHA),
+		Changes: []provider.FileChange{
+			{
+				Path:    input.Path,
+				Content: content,
+			},
+		},
+	})
+}
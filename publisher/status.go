@@ -0,0 +1,175 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/github"
+	"static-admin/markdown"
+	"static-admin/provider"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+var (
+	// ErrAlreadyDraft is returned when unpublishing a post that is already a draft
+	ErrAlreadyDraft = errors.New("post is already a draft")
+
+	// ErrAlreadyPublished is returned when publishing a post that is not a draft
+	ErrAlreadyPublished = errors.New("post is already published")
+)
+
+// SetPostStatusInput represents a request to publish or unpublish a post
+type SetPostStatusInput struct {
+	Site  database.Site
+	Token string
+	Path  string
+	Draft bool
+	Now   time.Time
+}
+
+// SetPostStatusResult describes the outcome of publishing or unpublishing a post
+type SetPostStatusResult struct {
+	CommitResult
+	Path   string
+	Status string
+}
+
+// ReviewBranch returns the review branch used for edits to a post
+func ReviewBranch(site database.Site, postPath string) string {
+	return branchName(site, "update", postPath)
+}
+
+// CreateBranch returns the review branch used for a new post
+func CreateBranch(site database.Site, postPath string) string {
+	return branchName(site, "create", postPath)
+}
+
+// branchName names a review branch after a post. Sites in a subdirectory include their root path,
+// separated by a double dash which slugs never contain, so sites sharing a repository can tell their branches apart.
+func branchName(site database.Site, prefix, postPath string) string {
+	if site.RootPath == "" {
+		return fmt.Sprintf("%s-%s", prefix, slug.Make(filepath.Base(postPath)))
+	}
+	return fmt.Sprintf("%s-%s--%s", prefix, slug.Make(site.RootPath), slug.Make(filepath.Base(postPath)))
+}
+
+// OwnsBranch returns true if a review branch belongs to the site rather than another site in the same repository
+func OwnsBranch(site database.Site, branch string) bool {
+	for _, prefix := range github.PullRequestBranchPrefixes {
+		if !strings.HasPrefix(branch, prefix) {
+			continue
+		}
+		if site.RootPath == "" {
+			return !strings.Contains(branch, "--")
+		}
+		return strings.HasPrefix(branch, prefix+slug.Make(site.RootPath)+"--")
+	}
+	return false
+}
+
+// SetPostStatus moves a post and rewrites its frontmatter in a single commit
+func SetPostStatus(ctx context.Context, input SetPostStatusInput) (SetPostStatusResult, error) {
+	owner, repo, ok := input.Site.OwnerAndRepo()
+	if !ok {
+		return SetPostStatusResult{}, errors.New("Invalid repository URL")
+	}
+
+	now := input.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	fileName := filepath.Base(input.Path)
+	branchName := ReviewBranch(input.Site, input.Path)
+
+	// pending edits on the review branch take precedence over the default branch
+	content, err := FetchFile(ctx, input.Site, owner, repo, input.Path, input.Token, branchName, input.Site.Branch())
+	if err != nil {
+		return SetPostStatusResult{}, fmt.Errorf("Failed to fetch file content: %w", err)
+	}
+
+	fields, body, err := markdown.ExtractFrontMatter([]byte(content))
+	if err != nil {
+		return SetPostStatusResult{}, fmt.Errorf("Failed to extract frontmatter: %w", err)
+	}
+
+	currentStatus := generator.PostStatus(input.Site.Generator, input.Path, fields, now)
+	if input.Draft && currentStatus == generator.StatusDraft {
+		return SetPostStatusResult{}, ErrAlreadyDraft
+	}
+	if !input.Draft && currentStatus != generator.StatusDraft {
+		return SetPostStatusResult{}, ErrAlreadyPublished
+	}
+
+	date := now
+	hasDate := false
+	for _, field := range fields {
+		if field.Name == "date" && field.Type == "dateTime" {
+			date = field.DateTimeValue
+			hasDate = true
+			break
+		}
+	}
+
+	fields = generator.SetDraft(input.Site.Generator, fields, input.Draft)
+	if !input.Draft && !hasDate {
+		fields = append(fields, markdown.FrontmatterField{
+			Name:             "date",
+			DateTimeValue:    date,
+			StringSliceValue: []string{},
+			Type:             "dateTime",
+		})
+	}
+
+	newPath := generator.DraftPath(input.Site.Generator, input.Path)
+	verb := "Unpublish"
+	if !input.Draft {
+		newPath = generator.PublishedPath(input.Site.Generator, input.Path, date)
+		verb = "Publish"
+	}
+
+	frontmatterYaml, err := markdown.FrontmatterFieldToYaml(fields)
+	if err != nil {
+		return SetPostStatusResult{}, fmt.Errorf("Failed to generate frontmatter: %w", err)
+	}
+
+	changes := []provider.FileChange{
+		{
+			Path:    newPath,
+			Content: frontmatterYaml + body,
+		},
+	}
+	if newPath != input.Path {
+		changes = append(changes, provider.FileChange{
+			Path:   input.Path,
+			Delete: true,
+		})
+	}
+
+	result, err := Commit(ctx, CommitInput{
+		Site:         input.Site,
+		Owner:        owner,
+		Repo:         repo,
+		Token:        input.Token,
+		ReviewBranch: branchName,
+		Title:        fmt.Sprintf("%s %s", verb, fileName),
+		Body:         ReviewBody(ctx, input.Site, owner, repo, input.Token, input.Path, fmt.Sprintf("%ss `%s`.", verb, input.Site.RepositoryPath(input.Path)), frontmatterYaml+body),
+		CommitMsg:    fmt.Sprintf("%s %s", verb, input.Path),
+		Changes:      changes,
+	})
+	if err != nil {
+		return SetPostStatusResult{}, err
+	}
+
+	return SetPostStatusResult{
+		CommitResult: result,
+		Path:         newPath,
+		Status:       generator.PostStatus(input.Site.Generator, newPath, fields, now),
+	}, nil
+}
@@ -0,0 +1,401 @@
+package repoindex
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/markdown"
+	"static-admin/permalink"
+	"static-admin/provider"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Post represents the metadata of a single post in the index
+type Post struct {
+	Path             string
+	SHA              string
+	Title            string
+	Date             time.Time
+	Tags             []string
+	Categories       []string
+	Draft            bool
+	LastCommitAuthor string
+	LastCommitDate   time.Time
+
+	// Slug and Permalink are the slug and permalink override set in the frontmatter
+	Slug      string
+	Permalink string
+}
+
+// Status computes the publication status of the post at the given time
+func (p Post) Status(now time.Time) string {
+	if p.Draft {
+		return generator.StatusDraft
+	}
+	if p.Date.After(now) {
+		return generator.StatusScheduled
+	}
+	return generator.StatusPublished
+}
+
+// PermalinkPost returns the data the permalink of the post is computed from
+func (p Post) PermalinkPost() permalink.Post {
+	return permalink.Post{
+		Path:       p.Path,
+		Title:      p.Title,
+		Slug:       p.Slug,
+		Date:       p.Date,
+		Categories: p.Categories,
+		Override:   p.Permalink,
+	}
+}
+
+// Index represents the posts of a site at a specific commit
+type Index struct {
+	CommitSHA string
+	TreeSHA   string
+	Posts     []Post
+}
+
+// RefreshInput represents the input parameters for the Refresh function
+type RefreshInput struct {
+	Site  database.Site
+	Owner string
+	Repo  string
+	Token string
+}
+
+// provider returns the provider and repository of the site being indexed
+func (input RefreshInput) provider() (provider.Provider, provider.Repo, error) {
+	p, err := provider.ForSite(input.Site)
+	if err != nil {
+		return nil, provider.Repo{}, err
+	}
+	return p, provider.Repo{Owner: input.Owner, Name: input.Repo, Token: input.Token}, nil
+}
+
+// postExtensions are the file extensions treated as posts
+var postExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+}
+
+// mediaExtensions are the file extensions listed when browsing media
+var mediaExtensions = map[string]bool{
+	".avif": true,
+	".gif":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".mp3":  true,
+	".mp4":  true,
+	".pdf":  true,
+	".png":  true,
+	".svg":  true,
+	".webm": true,
+	".webp": true,
+}
+
+// indexVersion is bumped whenever the indexed metadata changes, so that existing indexes are rebuilt
+const indexVersion = 1
+
+// webhookTrustInterval is how long an index is trusted without checking the head commit
+// when the site receives webhook deliveries
+const webhookTrustInterval = 5 * time.Minute
+
+// siteLocks serializes refreshes of the same site
+var siteLocks sync.Map
+
+// Refresh brings the stored index of a site up to date with the head of its default branch.
+// Only files whose blob changed since the indexed commit are read from the site's provider.
+func Refresh(ctx context.Context, db *gorm.DB, input RefreshInput) (database.RepositoryIndex, error) {
+	lock, _ := siteLocks.LoadOrStore(input.Site.ID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	var index database.RepositoryIndex
+	err := db.Where("site_id = ?", input.Site.ID).First(&index).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return database.RepositoryIndex{}, err
+	}
+
+	// files are classified by generator, so switching generators requires a full rebuild, as does
+	// a change to the indexed metadata
+	if index.ID != 0 && (index.Generator != input.Site.Generator || index.Version != indexVersion) {
+		if err := database.DeleteRepositoryIndex(db, input.Site.ID); err != nil {
+			return database.RepositoryIndex{}, err
+		}
+		index = database.RepositoryIndex{}
+	}
+
+	// sites receiving webhooks are told when the branch moves, so the head only needs
+	// to be checked occasionally in case a delivery was missed
+	if index.ID != 0 && !index.Stale && input.Site.WebhookReceivedAt != nil && time.Since(index.RefreshedAt) < webhookTrustInterval {
+		return index, nil
+	}
+
+	p, repo, err := input.provider()
+	if err != nil {
+		return database.RepositoryIndex{}, err
+	}
+
+	head, err := p.HeadCommit(ctx, repo, input.Site.Branch())
+	if err != nil {
+		return database.RepositoryIndex{}, err
+	}
+
+	if index.ID != 0 && index.CommitSHA == head {
+		index.Stale = false
+		index.RefreshedAt = time.Now()
+		if err := db.Model(&index).Updates(map[string]interface{}{"stale": false, "refreshed_at": index.RefreshedAt}).Error; err != nil {
+			return database.RepositoryIndex{}, err
+		}
+		return index, nil
+	}
+
+	treeSHA, err := p.CommitTree(ctx, repo, head)
+	if err != nil {
+		return database.RepositoryIndex{}, err
+	}
+
+	// commits that do not change any file, such as empty merges, keep the indexed files
+	if index.ID == 0 || index.TreeSHA != treeSHA {
+		if err := refreshFiles(ctx, db, input, p, repo, head); err != nil {
+			return database.RepositoryIndex{}, err
+		}
+	}
+
+	index.SiteID = input.Site.ID
+	index.CommitSHA = head
+	index.TreeSHA = treeSHA
+	index.Generator = input.Site.Generator
+	index.Version = indexVersion
+	index.RefreshedAt = time.Now()
+	index.Stale = false
+	if err := db.Save(&index).Error; err != nil {
+		return database.RepositoryIndex{}, err
+	}
+
+	return index, nil
+}
+
+// refreshFiles diffs the tree at a commit against the stored files, reading only new or changed blobs
+func refreshFiles(ctx context.Context, db *gorm.DB, input RefreshInput, p provider.Provider, repo provider.Repo, commitSHA string) error {
+	tree, err := p.Tree(ctx, repo, commitSHA)
+	if err != nil {
+		return err
+	}
+
+	var stored []database.RepositoryFile
+	if err := db.Select("id", "path", "sha").Where("site_id = ?", input.Site.ID).Find(&stored).Error; err != nil {
+		return err
+	}
+	existing := make(map[string]database.RepositoryFile, len(stored))
+	for _, file := range stored {
+		existing[file.Path] = file
+	}
+
+	var changed []database.RepositoryFile
+	var changedPosts []provider.TreeEntry
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+
+		file, ok := existing[entry.Path]
+		delete(existing, entry.Path)
+		if ok && file.SHA == entry.SHA {
+			continue
+		}
+
+		file = database.RepositoryFile{
+			ID:     file.ID,
+			SiteID: input.Site.ID,
+			Path:   entry.Path,
+			SHA:    entry.SHA,
+			Size:   entry.Size,
+			Kind:   fileKind(input.Site.Generator, entry.Path),
+
+			Tags:       database.StringSliceValue{},
+			Categories: database.StringSliceValue{},
+		}
+		if file.Kind == database.RepositoryFileKindPost {
+			changedPosts = append(changedPosts, entry)
+		}
+		changed = append(changed, file)
+	}
+
+	blobs, err := p.ReadBlobs(ctx, repo, commitSHA, changedPosts)
+	if err != nil {
+		return err
+	}
+
+	for i, file := range changed {
+		if file.Kind == database.RepositoryFileKindPost {
+			changed[i] = withPostMetadata(input.Site.Generator, file, blobs[file.Path])
+		}
+	}
+
+	// anything left in existing is no longer part of the tree
+	var removed []uint
+	for _, file := range existing {
+		removed = append(removed, file.ID)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(removed) > 0 {
+			if err := tx.Where("id IN ?", removed).Delete(&database.RepositoryFile{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, file := range changed {
+			if err := tx.Save(&file).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Invalidate marks the index of a site as stale so the next read checks the head commit
+func Invalidate(db *gorm.DB, siteID uint) {
+	if err := db.Model(&database.RepositoryIndex{}).Where("site_id = ?", siteID).Update("stale", true).Error; err != nil {
+		log.Printf("Failed to invalidate repository index for site %d: %v", siteID, err)
+	}
+}
+
+// Get refreshes the index of a site and returns its posts
+func Get(ctx context.Context, db *gorm.DB, input RefreshInput) (*Index, error) {
+	index, err := Refresh(ctx, db, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []database.RepositoryFile
+	if err := db.Where("site_id = ? AND kind = ?", input.Site.ID, database.RepositoryFileKindPost).Find(&files).Error; err != nil {
+		return nil, err
+	}
+
+	result := &Index{
+		CommitSHA: index.CommitSHA,
+		TreeSHA:   index.TreeSHA,
+		Posts:     make([]Post, len(files)),
+	}
+	for i, file := range files {
+		result.Posts[i] = Post{
+			Path:             file.Path,
+			SHA:              file.SHA,
+			Title:            file.Title,
+			Date:             file.Date,
+			Tags:             file.Tags,
+			Categories:       file.Categories,
+			Draft:            file.Draft,
+			LastCommitAuthor: file.LastCommitAuthor,
+			LastCommitDate:   file.LastCommitDate,
+			Slug:             file.Slug,
+			Permalink:        file.Permalink,
+		}
+	}
+
+	return result, nil
+}
+
+// Media refreshes the index of a site and returns its media files, optionally limited to a
+// directory and filtered by a case-insensitive search over paths
+func Media(ctx context.Context, db *gorm.DB, input RefreshInput, directory, search string) ([]database.RepositoryFile, error) {
+	if _, err := Refresh(ctx, db, input); err != nil {
+		return nil, err
+	}
+
+	query := db.Select("path", "sha", "size").
+		Where("site_id = ? AND kind = ?", input.Site.ID, database.RepositoryFileKindMedia)
+	if directory = strings.Trim(directory, "/"); directory != "" {
+		query = query.Where("path LIKE ? ESCAPE '\\'", escapeLike(directory)+"/%")
+	}
+	if search != "" {
+		query = query.Where("LOWER(path) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(search))+"%")
+	}
+
+	var files []database.RepositoryFile
+	if err := query.Order("path").Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
+// fileKind classifies a path as a post, media or other file
+func fileKind(generatorName, path string) string {
+	extension := strings.ToLower(filepath.Ext(path))
+	if mediaExtensions[extension] {
+		return database.RepositoryFileKindMedia
+	}
+	if !postExtensions[extension] {
+		return database.RepositoryFileKindOther
+	}
+
+	for _, directory := range generator.PostDirectories(generatorName) {
+		if strings.HasPrefix(path, directory+"/") {
+			return database.RepositoryFileKindPost
+		}
+	}
+	return database.RepositoryFileKindOther
+}
+
+// withPostMetadata extracts the indexed metadata of a post from its contents
+func withPostMetadata(generatorName string, file database.RepositoryFile, blob provider.BlobContent) database.RepositoryFile {
+	file.Title = strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path))
+	file.LastCommitAuthor = blob.LastCommitAuthor
+	if date, err := time.Parse(time.RFC3339, blob.LastCommitDate); err == nil {
+		file.LastCommitDate = date
+	}
+
+	// posts with invalid frontmatter are still listed, using their file name as the title
+	fields, _, err := markdown.ExtractFrontMatter([]byte(blob.Text))
+	if err != nil {
+		fields = nil
+	}
+
+	for _, field := range fields {
+		switch field.Name {
+		case "title":
+			if field.StringValue != "" {
+				file.Title = field.StringValue
+			}
+		case "date":
+			file.Date = field.DateTimeValue
+		case "tags":
+			file.Tags = valueList(field)
+		case "categories", "category":
+			file.Categories = append(file.Categories, valueList(field)...)
+		}
+	}
+
+	post := permalink.FromFrontmatter(generatorName, file.Path, fields)
+	file.Slug = post.Slug
+	file.Permalink = post.Override
+
+	// the status is evaluated against the end of time so that only drafts are detected here,
+	// scheduled posts are derived from the date when the index is queried
+	file.Draft = generator.PostStatus(generatorName, file.Path, fields, time.Unix(1<<62, 0)) == generator.StatusDraft
+
+	return file
+}
+
+// valueList returns the values of a field that may be either a list or a space separated string
+func valueList(field markdown.FrontmatterField) []string {
+	if field.Type == "stringSlice" {
+		return field.StringSliceValue
+	}
+	return strings.Fields(field.StringValue)
+}
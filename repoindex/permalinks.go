@@ -0,0 +1,90 @@
+package repoindex
+
+import (
+	"context"
+	"static-admin/database"
+	"static-admin/permalink"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// parsedConfig is the permalink configuration of a site together with the file it was parsed from
+type parsedConfig struct {
+	key    string
+	config permalink.Config
+}
+
+// permalinkConfigs caches the parsed permalink configuration of each site
+var permalinkConfigs sync.Map
+
+// Permalinks refreshes the index of a site and returns its permalink configuration, read from the
+// generator configuration file at the indexed commit. The parsed file is cached until its blob changes.
+func Permalinks(ctx context.Context, db *gorm.DB, input RefreshInput) (permalink.Config, error) {
+	index, err := Refresh(ctx, db, input)
+	if err != nil {
+		return permalink.Config{}, err
+	}
+
+	names := permalink.ConfigFiles(input.Site.Generator)
+	if len(names) == 0 {
+		return permalink.DefaultConfig(input.Site.Generator), nil
+	}
+
+	var files []database.RepositoryFile
+	if err := db.Select("path", "sha").Where("site_id = ? AND path IN ?", input.Site.ID, names).Find(&files).Error; err != nil {
+		return permalink.Config{}, err
+	}
+
+	// the first configuration file in order of precedence wins
+	var file *database.RepositoryFile
+	for _, name := range names {
+		for i := range files {
+			if files[i].Path == name && file == nil {
+				file = &files[i]
+			}
+		}
+	}
+	if file == nil {
+		return permalink.DefaultConfig(input.Site.Generator), nil
+	}
+
+	key := input.Site.Generator + ":" + file.Path + ":" + file.SHA
+	if cached, ok := permalinkConfigs.Load(input.Site.ID); ok && cached.(parsedConfig).key == key {
+		return cached.(parsedConfig).config, nil
+	}
+
+	p, repo, err := input.provider()
+	if err != nil {
+		return permalink.Config{}, err
+	}
+
+	content, err := p.ReadFile(ctx, repo, file.Path, index.CommitSHA)
+	if err != nil {
+		return permalink.Config{}, err
+	}
+
+	config, err := permalink.ParseConfig(input.Site.Generator, file.Path, content)
+	if err != nil {
+		return permalink.Config{}, err
+	}
+
+	permalinkConfigs.Store(input.Site.ID, parsedConfig{key: key, config: config})
+	return config, nil
+}
+
+// FindPermalink returns the post other than the one at exceptPath that is published under a permalink.
+// Trailing slashes are ignored, as servers commonly answer both forms with the same page.
+func (idx *Index) FindPermalink(permalinks permalink.Config, value, exceptPath string) (Post, bool) {
+	value = strings.TrimSuffix(value, "/")
+	for _, post := range idx.Posts {
+		if post.Path == exceptPath {
+			continue
+		}
+		if other := permalinks.Path(post.PermalinkPost()); other != "" && strings.TrimSuffix(other, "/") == value {
+			return post, true
+		}
+	}
+	return Post{}, false
+}
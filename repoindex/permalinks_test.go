@@ -0,0 +1,129 @@
+package repoindex
+
+import (
+	"testing"
+
+	"static-admin/generator"
+	"static-admin/permalink"
+)
+
+func TestFindPermalink(t *testing.T) {
+	jekyll := permalink.DefaultConfig(generator.Jekyll)
+	jekyllIndex := &Index{Posts: []Post{
+		{Path: "_posts/2024-03-05-hello.md"},
+		{Path: "_posts/2024-03-06-about.md", Permalink: "/about/"},
+		{Path: "_posts/2024-03-07-hello.md", Slug: "other"},
+	}}
+
+	hugo := permalink.Config{Generator: generator.Hugo, Pattern: "/:slug/"}
+	hugoIndex := &Index{Posts: []Post{
+		{Path: "content/posts/first.md", Title: "Same Title"},
+		{Path: "content/posts/second.md", Title: "Same title"},
+		{Path: "content/posts/third.md", Title: "Same Title", Slug: "third"},
+	}}
+
+	eleventy := permalink.Config{Generator: generator.Eleventy}
+	eleventyIndex := &Index{Posts: []Post{
+		{Path: "posts/hidden.md", Permalink: "false"},
+		{Path: "posts/dynamic.md", Permalink: "/{{ page.fileSlug }}/"},
+		{Path: "posts/hello.md"},
+	}}
+
+	tests := []struct {
+		name       string
+		config     permalink.Config
+		index      *Index
+		value      string
+		exceptPath string
+		want       string
+	}{
+		{
+			name:       "permalink of another post",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/2024/03/05/hello.html",
+			exceptPath: "_posts/2024-03-05-new.md",
+			want:       "_posts/2024-03-05-hello.md",
+		},
+		{
+			name:       "own permalink",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/2024/03/05/hello.html",
+			exceptPath: "_posts/2024-03-05-hello.md",
+		},
+		{
+			name:       "unused permalink",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/2024/03/07/hello.html",
+			exceptPath: "_posts/2024-03-08-new.md",
+		},
+		{
+			name:       "permalink set in the frontmatter",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/about/",
+			exceptPath: "_posts/2024-03-08-new.md",
+			want:       "_posts/2024-03-06-about.md",
+		},
+		{
+			name:       "trailing slash is ignored",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/about",
+			exceptPath: "_posts/2024-03-08-new.md",
+			want:       "_posts/2024-03-06-about.md",
+		},
+		{
+			name:       "slug of another post",
+			config:     jekyll,
+			index:      jekyllIndex,
+			value:      "/2024/03/07/other.html",
+			exceptPath: "_posts/2024-03-08-new.md",
+			want:       "_posts/2024-03-07-hello.md",
+		},
+		{
+			name:       "titles slugified to the same permalink",
+			config:     hugo,
+			index:      hugoIndex,
+			value:      "/same-title/",
+			exceptPath: "content/posts/first.md",
+			want:       "content/posts/second.md",
+		},
+		{
+			name:       "slug avoids the collision",
+			config:     hugo,
+			index:      hugoIndex,
+			value:      "/third/",
+			exceptPath: "content/posts/third.md",
+		},
+		{
+			name:       "posts without a permalink never collide",
+			config:     eleventy,
+			index:      eleventyIndex,
+			value:      "",
+			exceptPath: "posts/new.md",
+		},
+		{
+			name:       "eleventy path",
+			config:     eleventy,
+			index:      eleventyIndex,
+			value:      "/posts/hello/",
+			exceptPath: "posts/new.md",
+			want:       "posts/hello.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, found := tt.index.FindPermalink(tt.config, tt.value, tt.exceptPath)
+			if found != (tt.want != "") {
+				t.Fatalf("FindPermalink() found = %v (%s), want %q", found, post.Path, tt.want)
+			}
+			if post.Path != tt.want {
+				t.Errorf("FindPermalink() = %s, want %s", post.Path, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package repoindex
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// sortKeyLayout is a fixed width time layout so that formatted dates sort lexicographically
+const sortKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Query represents the filters, ordering and pagination applied to an index
+type Query struct {
+	Status   string
+	Tag      string
+	Category string
+	Search   string
+	Sort     string // "date", "title", "path" or "updated"
+	Order    string // "asc" or "desc"
+	Cursor   string
+	Limit    int
+	Now      time.Time
+}
+
+// ValidSort returns true if the sort field is supported
+func ValidSort(sort string) bool {
+	return sort == "" || sort == "date" || sort == "title" || sort == "path" || sort == "updated"
+}
+
+// Find returns the posts matching the query along with a cursor for the next page,
+// which is empty when there are no more results
+func (idx *Index) Find(q Query) ([]Post, string, error) {
+	if q.Sort == "" {
+		q.Sort = "date"
+	}
+	descending := q.Order != "asc"
+
+	terms := strings.Fields(strings.ToLower(q.Search))
+	posts := make([]Post, 0, len(idx.Posts))
+	for _, post := range idx.Posts {
+		if q.Status != "" && post.Status(q.Now) != q.Status {
+			continue
+		}
+		if q.Tag != "" && !containsFold(post.Tags, q.Tag) {
+			continue
+		}
+		if q.Category != "" && !containsFold(post.Categories, q.Category) {
+			continue
+		}
+		if !matchesTerms(post.Title, terms) {
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return before(sortKey(posts[i], q.Sort), posts[i].Path, sortKey(posts[j], q.Sort), posts[j].Path, descending)
+	})
+
+	// the cursor holds the sort key and path of the last returned post, so pages stay
+	// stable when posts are added or removed between requests
+	if q.Cursor != "" {
+		key, path, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(posts), func(i int) bool {
+			return before(key, path, sortKey(posts[i], q.Sort), posts[i].Path, descending)
+		})
+		posts = posts[start:]
+	}
+
+	if q.Limit <= 0 || len(posts) <= q.Limit {
+		return posts, "", nil
+	}
+
+	posts = posts[:q.Limit]
+	last := posts[len(posts)-1]
+	return posts, encodeCursor(sortKey(last, q.Sort), last.Path), nil
+}
+
+// before returns true if the first post is ordered strictly before the second,
+// using the path to break ties between equal sort keys
+func before(keyA, pathA, keyB, pathB string, descending bool) bool {
+	if keyA == keyB {
+		keyA, keyB = pathA, pathB
+	}
+	if descending {
+		return keyA > keyB
+	}
+	return keyA < keyB
+}
+
+// sortKey returns the value a post is ordered by
+func sortKey(post Post, field string) string {
+	switch field {
+	case "title":
+		return strings.ToLower(post.Title)
+	case "path":
+		return post.Path
+	case "updated":
+		return post.LastCommitDate.UTC().Format(sortKeyLayout)
+	default:
+		return post.Date.UTC().Format(sortKeyLayout)
+	}
+}
+
+// encodeCursor encodes a sort key and path as an opaque cursor
+func encodeCursor(key, path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "\n" + path))
+}
+
+// decodeCursor decodes a cursor created by encodeCursor
+func decodeCursor(cursor string) (string, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+
+	key, path, ok := strings.Cut(string(data), "\n")
+	if !ok {
+		return "", "", ErrInvalidCursor
+	}
+	return key, path, nil
+}
+
+// containsFold returns true if the values contain the value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTerms returns true if the title contains every search term
+func matchesTerms(title string, terms []string) bool {
+	title = strings.ToLower(title)
+	for _, term := range terms {
+		if !strings.Contains(title, term) {
+			return false
+		}
+	}
+	return true
+}
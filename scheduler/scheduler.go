@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"static-admin/database"
+	"static-admin/github"
+	"static-admin/provider"
+	"static-admin/publisher"
+	"static-admin/repoindex"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// pollInterval is how often the scheduler looks for due jobs
+	pollInterval = time.Minute
+
+	// maxAttempts is the number of times a job is tried before it is marked as failed
+	maxAttempts = 8
+
+	// baseBackoff is the delay before the first retry, doubled on each subsequent attempt
+	baseBackoff = time.Minute
+
+	// maxBackoff caps the delay between retries
+	maxBackoff = time.Hour
+)
+
+// Start starts a goroutine that periodically runs scheduled publications that are due
+func Start(db *gorm.DB, quit chan struct{}) {
+	// jobs interrupted by a restart are picked back up on the next run
+	err := db.Model(&database.ScheduledPublication{}).
+		Where("status = ?", database.ScheduleStatusRunning).
+		Update("status", database.ScheduleStatusPending).Error
+	if err != nil {
+		log.Printf("Failed to reset interrupted scheduled publications: %v", err)
+	}
+
+	// in-flight GitHub requests are abandoned on shutdown, the job is retried after a restart
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(pollInterval)
+	go func() {
+		runDue(ctx, db, time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				runDue(ctx, db, time.Now())
+			case <-quit:
+				cancel()
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Backoff returns the delay before retrying a job that has failed the given number of times
+func Backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// runDue executes every pending job whose next attempt is due
+func runDue(ctx context.Context, db *gorm.DB, now time.Time) {
+	var jobs []database.ScheduledPublication
+	err := db.Where("status = ? AND next_attempt_at <= ?", database.ScheduleStatusPending, now).
+		Order("next_attempt_at").
+		Find(&jobs).Error
+	if err != nil {
+		log.Printf("Failed to fetch scheduled publications: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		// claim the job so it is never executed twice
+		result := db.Model(&database.ScheduledPublication{}).
+			Where("id = ? AND status = ?", job.ID, database.ScheduleStatusPending).
+			Update("status", database.ScheduleStatusRunning)
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		runErr := execute(ctx, db, &job)
+		repoindex.Invalidate(db, job.SiteID)
+		job.Attempts++
+		job.Status = database.ScheduleStatusCompleted
+		job.LastError = ""
+		if runErr != nil {
+			log.Printf("Scheduled publication %d failed (attempt %d): %v", job.ID, job.Attempts, runErr)
+			job.LastError = runErr.Error()
+			job.Status = database.ScheduleStatusPending
+			job.NextAttemptAt = now.Add(Backoff(job.Attempts))
+			if job.Attempts >= maxAttempts {
+				job.Status = database.ScheduleStatusFailed
+			}
+		}
+
+		// only the claimed job is updated, so that changes made while it ran are not overwritten
+		result = db.Model(&database.ScheduledPublication{}).
+			Where("id = ? AND status = ?", job.ID, database.ScheduleStatusRunning).
+			Updates(map[string]interface{}{
+				"status":              job.Status,
+				"attempts":            job.Attempts,
+				"last_error":          job.LastError,
+				"next_attempt_at":     job.NextAttemptAt,
+				"action":              job.Action,
+				"pull_request_number": job.PullRequestNumber,
+			})
+		if result.Error != nil {
+			log.Printf("Failed to save scheduled publication %d: %v", job.ID, result.Error)
+		} else if result.RowsAffected == 0 {
+			log.Printf("Scheduled publication %d changed while it was running, keeping its current state", job.ID)
+		}
+	}
+}
+
+// execute runs a single scheduled publication, recording any partial progress on the job
+func execute(ctx context.Context, db *gorm.DB, job *database.ScheduledPublication) error {
+	var site database.Site
+	if err := db.First(&site, job.SiteID).Error; err != nil {
+		return fmt.Errorf("failed to fetch site: %w", err)
+	}
+
+	token, err := database.GetProviderToken(db, job.UserID, site.Provider)
+	if err != nil {
+		return fmt.Errorf("failed to fetch authentication: %w", err)
+	}
+
+	switch job.Action {
+	case database.ScheduleActionMergePullRequest:
+		return mergePullRequest(ctx, site, token, *job)
+	case database.ScheduleActionPublishDraft:
+		result, err := publisher.SetPostStatus(ctx, publisher.SetPostStatusInput{
+			Site:  site,
+			Token: token,
+			Path:  job.PostPath,
+			Draft: false,
+		})
+		if errors.Is(err, publisher.ErrAlreadyPublished) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := database.MovePostAuthor(db, site.ID, job.PostPath, result.Path); err != nil {
+			log.Printf("Failed to move author of %s for site %d: %v", job.PostPath, site.ID, err)
+		}
+
+		if result.PRNumber == 0 {
+			return nil
+		}
+
+		// sites that review changes get the publish commit merged right away, as the schedule is the approval.
+		// the job becomes a merge so that a retry does not try to publish the draft a second time
+		job.Action = database.ScheduleActionMergePullRequest
+		job.PullRequestNumber = result.PRNumber
+		return mergePullRequest(ctx, site, token, *job)
+	default:
+		return fmt.Errorf("unknown action: %s", job.Action)
+	}
+}
+
+// mergePullRequest merges the pull request attached to a job
+func mergePullRequest(ctx context.Context, site database.Site, token string, job database.ScheduledPublication) error {
+	if site.Provider != "" && site.Provider != provider.GitHub {
+		return fmt.Errorf("merging review requests is not supported on %s", site.Provider)
+	}
+
+	owner, repo, ok := site.OwnerAndRepo()
+	if !ok {
+		return errors.New("invalid repository URL")
+	}
+
+	input := github.PullRequestInput{
+		Owner:  owner,
+		Repo:   repo,
+		Number: job.PullRequestNumber,
+		Token:  token,
+	}
+
+	// a retry after a partial failure may find the pull request already merged
+	pr, err := github.GetPullRequest(ctx, input)
+	if err != nil {
+		return err
+	}
+	if pr.Merged {
+		return nil
+	}
+
+	return github.MergePullRequest(ctx, github.MergePullRequestInput{
+		Owner:        owner,
+		Repo:         repo,
+		Number:       job.PullRequestNumber,
+		Method:       job.MergeMethod,
+		DeleteBranch: true,
+		Token:        token,
+	})
+}
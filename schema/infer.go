@@ -0,0 +1,230 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"static-admin/database"
+	"static-admin/markdown"
+)
+
+const (
+	// maxCommonValues is the number of most common values reported for a field
+	maxCommonValues = 10
+
+	// maxEnumValues and maxSliceEnumValues are the most distinct values a text or list field can have to be
+	// proposed as a list of allowed values
+	maxEnumValues      = 10
+	maxSliceEnumValues = 20
+)
+
+// ValueCount represents a value of a field and the number of posts it was seen in
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FieldStats represents what was observed about a frontmatter field across the sampled posts
+type FieldStats struct {
+	Name string `json:"name"`
+
+	// Types counts the posts the field was seen with each type in
+	Types map[string]int `json:"types"`
+
+	// Count is the number of posts the field is set in, Frequency the share of sampled posts
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+
+	// CommonValues are the most common values of text, boolean and list fields
+	CommonValues []ValueCount `json:"common_values"`
+
+	// DateFormat is the most common layout of dates, in Go's reference time notation
+	DateFormat string `json:"date_format,omitempty"`
+}
+
+// Inference represents the fields observed in a sample of posts and the template proposed from them
+type Inference struct {
+	Sampled int          `json:"sampled"`
+	Fields  []FieldStats `json:"fields"`
+
+	// TemplateFields are the proposed fields, leaving out fields whose type no template field can have
+	TemplateFields []database.TemplateField `json:"-"`
+}
+
+// fieldObservations accumulates the values of a field while posts are sampled
+type fieldObservations struct {
+	stats   FieldStats
+	empty   int
+	values  map[string]int
+	layouts map[string]int
+
+	// total counts the values of the field, which list fields have several of per post
+	total int
+}
+
+// Infer aggregates the frontmatter of sampled posts and proposes a template for them. Fields set in every
+// post are required, text and list fields reusing a few values, such as categories and tags, are limited
+// to these values, and boolean fields default to their most common value.
+func Infer(posts [][]markdown.FrontmatterField) Inference {
+	observed := map[string]*fieldObservations{}
+	order := []string{}
+	for _, fields := range posts {
+		for _, field := range fields {
+			observations, ok := observed[field.Name]
+			if !ok {
+				observations = &fieldObservations{
+					stats:   FieldStats{Name: field.Name, Types: map[string]int{}},
+					values:  map[string]int{},
+					layouts: map[string]int{},
+				}
+				observed[field.Name] = observations
+				order = append(order, field.Name)
+			}
+			observations.observe(field)
+		}
+	}
+
+	result := Inference{Sampled: len(posts), Fields: []FieldStats{}, TemplateFields: []database.TemplateField{}}
+	for _, name := range order {
+		observations := observed[name]
+		stats := observations.stats
+		if len(posts) > 0 {
+			stats.Frequency = float64(stats.Count) / float64(len(posts))
+		}
+		stats.CommonValues = mostCommon(observations.values, maxCommonValues)
+		if layout := mostCommon(observations.layouts, 1); len(layout) > 0 {
+			stats.DateFormat = layout[0].Value
+		}
+		result.Fields = append(result.Fields, stats)
+
+		if field, ok := observations.propose(len(posts)); ok {
+			result.TemplateFields = append(result.TemplateFields, field)
+		}
+	}
+	return result
+}
+
+// observe records a value of the field
+func (o *fieldObservations) observe(field markdown.FrontmatterField) {
+	field.Type = templateType(field.Type)
+	o.stats.Types[field.Type]++
+	o.stats.Count++
+	if isEmpty(field) {
+		o.empty++
+		return
+	}
+
+	switch field.Type {
+	case "string":
+		o.count(field.StringValue)
+		if layout, ok := markdown.DateLayout(field.StringValue); ok {
+			o.layouts[layout]++
+		}
+	case "bool":
+		o.count(strconv.FormatBool(field.BoolValue))
+	case "stringSlice":
+		for _, value := range field.StringSliceValue {
+			o.count(value)
+		}
+	case "dateTime":
+		o.layouts[dateTimeLayout(field.DateTimeValue)]++
+	}
+}
+
+// count records one occurrence of a value
+func (o *fieldObservations) count(value string) {
+	o.values[value]++
+	o.total++
+}
+
+// propose returns the template field proposed for the observations, false when the field has no template type
+func (o *fieldObservations) propose(sampled int) (database.TemplateField, bool) {
+	fieldType := mostCommon(o.stats.Types, 1)[0].Value
+	if !fieldTypes[fieldType] {
+		return database.TemplateField{}, false
+	}
+
+	field := database.TemplateField{
+		Name:             o.stats.Name,
+		Type:             fieldType,
+		StringSliceValue: database.StringSliceValue{},
+		AllowedValues:    database.StringSliceValue{},
+		Required:         sampled > 1 && o.stats.Count == sampled && o.empty == 0,
+	}
+
+	switch fieldType {
+	case "string":
+		// text reused across posts, such as a layout or a category, is proposed as a choice
+		if len(o.layouts) == 0 && len(o.values) <= maxEnumValues && o.total >= 2*len(o.values) {
+			field.AllowedValues = sortedValues(o.values)
+		}
+	case "stringSlice":
+		if len(o.values) <= maxSliceEnumValues && o.total >= 2*len(o.values) {
+			field.AllowedValues = sortedValues(o.values)
+		}
+	case "bool":
+		field.BoolValue = mostCommon(o.values, 1)[0].Value == "true"
+	}
+	return field, true
+}
+
+// fieldTypes are the types template fields can have
+var fieldTypes = map[string]bool{
+	"string":      true,
+	"bool":        true,
+	"number":      true,
+	"dateTime":    true,
+	"stringSlice": true,
+}
+
+// templateType returns the template type of a frontmatter field, whose numbers are typed after their Go type
+func templateType(fieldType string) string {
+	switch fieldType {
+	case "int", "float64":
+		return "number"
+	}
+	return fieldType
+}
+
+// dateTimeLayout guesses the layout of a parsed date from the precision it was written with
+func dateTimeLayout(date time.Time) string {
+	layout := "2006-01-02 15:04:05"
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		layout = "2006-01-02"
+	} else if date.Second() == 0 {
+		layout = "2006-01-02 15:04"
+	}
+	if date.Location() != time.UTC {
+		layout += " -0700"
+	}
+	return layout
+}
+
+// mostCommon returns the most common values of a count, ties broken alphabetically
+func mostCommon(counts map[string]int, limit int) []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > limit {
+		values = values[:limit]
+	}
+	return values
+}
+
+// sortedValues returns the distinct values of a count in alphabetical order
+func sortedValues(counts map[string]int) database.StringSliceValue {
+	values := make(database.StringSliceValue, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,308 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"static-admin/database"
+	"static-admin/markdown"
+)
+
+// repeat returns posts with a single field, one post per value, each value repeated the given number of times
+func repeat(times int, field func(value string) markdown.FrontmatterField, values ...string) [][]markdown.FrontmatterField {
+	var posts [][]markdown.FrontmatterField
+	for range times {
+		for _, value := range values {
+			posts = append(posts, []markdown.FrontmatterField{field(value)})
+		}
+	}
+	return posts
+}
+
+// distinct returns n distinct values
+func distinct(n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = fmt.Sprintf("value-%02d", i)
+	}
+	return values
+}
+
+func TestInferProposal(t *testing.T) {
+	layout := func(value string) markdown.FrontmatterField { return stringField("layout", value) }
+	tags := func(value string) markdown.FrontmatterField { return sliceField("tags", value, "shared") }
+
+	tests := []struct {
+		name  string
+		posts [][]markdown.FrontmatterField
+		field string
+		want  database.TemplateField
+		// proposed is false when no template field is expected
+		proposed bool
+	}{
+		{
+			name:     "single post is not required",
+			posts:    [][]markdown.FrontmatterField{{stringField("title", "Hello")}},
+			field:    "title",
+			want:     database.TemplateField{Name: "title", Type: "string"},
+			proposed: true,
+		},
+		{
+			name:     "set in every post is required",
+			posts:    repeat(1, func(v string) markdown.FrontmatterField { return stringField("title", v) }, "Hello", "World"),
+			field:    "title",
+			want:     database.TemplateField{Name: "title", Type: "string", Required: true},
+			proposed: true,
+		},
+		{
+			name: "missing from a post is not required",
+			posts: [][]markdown.FrontmatterField{
+				{stringField("title", "Hello"), stringField("subtitle", "One")},
+				{stringField("title", "World")},
+			},
+			field:    "subtitle",
+			want:     database.TemplateField{Name: "subtitle", Type: "string"},
+			proposed: true,
+		},
+		{
+			name:     "empty in a post is not required",
+			posts:    repeat(1, func(v string) markdown.FrontmatterField { return stringField("title", v) }, "Hello", ""),
+			field:    "title",
+			want:     database.TemplateField{Name: "title", Type: "string"},
+			proposed: true,
+		},
+		{
+			name:     "reused values become allowed values",
+			posts:    repeat(2, layout, "post", "page"),
+			field:    "layout",
+			want:     database.TemplateField{Name: "layout", Type: "string", Required: true, AllowedValues: database.StringSliceValue{"page", "post"}},
+			proposed: true,
+		},
+		{
+			name:     "values used once are free text",
+			posts:    repeat(1, layout, "post", "page", "home"),
+			field:    "layout",
+			want:     database.TemplateField{Name: "layout", Type: "string", Required: true},
+			proposed: true,
+		},
+		{
+			name:     "max enum values become allowed values",
+			posts:    repeat(2, layout, distinct(maxEnumValues)...),
+			field:    "layout",
+			want:     database.TemplateField{Name: "layout", Type: "string", Required: true, AllowedValues: database.StringSliceValue(distinct(maxEnumValues))},
+			proposed: true,
+		},
+		{
+			name:     "more than max enum values are free text",
+			posts:    repeat(2, layout, distinct(maxEnumValues+1)...),
+			field:    "layout",
+			want:     database.TemplateField{Name: "layout", Type: "string", Required: true},
+			proposed: true,
+		},
+		{
+			name:     "dates written as text are not allowed values",
+			posts:    repeat(3, func(v string) markdown.FrontmatterField { return stringField("updated", v) }, "2024-01-01", "2024-02-01"),
+			field:    "updated",
+			want:     database.TemplateField{Name: "updated", Type: "string", Required: true},
+			proposed: true,
+		},
+		{
+			name:     "reused list items become allowed values",
+			posts:    repeat(1, tags, "go", "go", "rust"),
+			field:    "tags",
+			want:     database.TemplateField{Name: "tags", Type: "stringSlice", Required: true, AllowedValues: database.StringSliceValue{"go", "rust", "shared"}},
+			proposed: true,
+		},
+		{
+			name:     "lists allow more values than text",
+			posts:    repeat(2, tags, distinct(maxSliceEnumValues-1)...),
+			field:    "tags",
+			want:     database.TemplateField{Name: "tags", Type: "stringSlice", Required: true, AllowedValues: append(database.StringSliceValue{"shared"}, distinct(maxSliceEnumValues-1)...)},
+			proposed: true,
+		},
+		{
+			name:     "more than max list values are free",
+			posts:    repeat(2, tags, distinct(maxSliceEnumValues)...),
+			field:    "tags",
+			want:     database.TemplateField{Name: "tags", Type: "stringSlice", Required: true},
+			proposed: true,
+		},
+		{
+			name: "booleans default to the most common value",
+			posts: [][]markdown.FrontmatterField{
+				{boolField("draft", true)},
+				{boolField("draft", true)},
+				{boolField("draft", false)},
+			},
+			field:    "draft",
+			want:     database.TemplateField{Name: "draft", Type: "bool", Required: true, BoolValue: true},
+			proposed: true,
+		},
+		{
+			name: "parsed numbers are numbers",
+			posts: [][]markdown.FrontmatterField{
+				{{Name: "weight", Type: "int"}},
+				{{Name: "weight", Type: "float64", NumberValue: 1.5}},
+			},
+			field:    "weight",
+			want:     database.TemplateField{Name: "weight", Type: "number", Required: true},
+			proposed: true,
+		},
+		{
+			name: "most common type wins",
+			posts: [][]markdown.FrontmatterField{
+				{sliceField("tags", "go")},
+				{sliceField("tags", "rust")},
+				{stringField("tags", "go")},
+			},
+			field:    "tags",
+			want:     database.TemplateField{Name: "tags", Type: "stringSlice", Required: true},
+			proposed: true,
+		},
+		{
+			name: "nested values are not proposed",
+			posts: [][]markdown.FrontmatterField{
+				{{Name: "author", Type: "map[interface {}]interface {}"}},
+			},
+			field: "author",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inference := Infer(tt.posts)
+
+			var got *database.TemplateField
+			for i := range inference.TemplateFields {
+				if inference.TemplateFields[i].Name == tt.field {
+					got = &inference.TemplateFields[i]
+				}
+			}
+			if got == nil {
+				if tt.proposed {
+					t.Fatalf("Infer() did not propose %s", tt.field)
+				}
+				return
+			}
+			if !tt.proposed {
+				t.Fatalf("Infer() proposed %+v", *got)
+			}
+
+			if tt.want.AllowedValues == nil {
+				tt.want.AllowedValues = database.StringSliceValue{}
+			}
+			tt.want.StringSliceValue = database.StringSliceValue{}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Infer() proposed %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferDateFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		posts [][]markdown.FrontmatterField
+		want  string
+	}{
+		{
+			name:  "date only",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) }, "2024-01-01T00:00:00Z"),
+			want:  "2006-01-02",
+		},
+		{
+			name:  "minutes",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) }, "2024-01-01T10:30:00Z"),
+			want:  "2006-01-02 15:04",
+		},
+		{
+			name:  "seconds",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) }, "2024-01-01T10:30:15Z"),
+			want:  "2006-01-02 15:04:05",
+		},
+		{
+			name:  "time zone",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) }, "2024-01-01T10:30:00+02:00"),
+			want:  "2006-01-02 15:04 -0700",
+		},
+		{
+			name: "most common layout",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) },
+				"2024-01-01T00:00:00Z", "2024-01-02T10:30:00Z", "2024-01-03T11:45:00Z"),
+			want: "2006-01-02 15:04",
+		},
+		{
+			name:  "dates written as text",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return stringField("date", v) }, "2024-01-01 10:30:15 +0200", "2024-02-01 08:00:00 +0200"),
+			want:  "2006-01-02 15:04:05 -0700",
+		},
+		{
+			name:  "text that is not a date",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return stringField("date", v) }, "yesterday"),
+			want:  "",
+		},
+		{
+			name:  "empty dates",
+			posts: repeat(1, func(v string) markdown.FrontmatterField { return dateField("date", v) }, ""),
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inference := Infer(tt.posts)
+			if len(inference.Fields) != 1 {
+				t.Fatalf("Infer() fields = %+v, want one", inference.Fields)
+			}
+			if got := inference.Fields[0].DateFormat; got != tt.want {
+				t.Errorf("DateFormat = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferStats(t *testing.T) {
+	posts := [][]markdown.FrontmatterField{
+		{stringField("title", "One"), stringField("layout", "post"), boolField("draft", false)},
+		{stringField("title", "Two"), stringField("layout", "post")},
+		{stringField("title", "Three"), stringField("layout", "page")},
+		{stringField("title", "Four"), sliceField("layout", "post")},
+	}
+
+	inference := Infer(posts)
+	if inference.Sampled != 4 {
+		t.Errorf("Sampled = %d, want 4", inference.Sampled)
+	}
+
+	want := []FieldStats{
+		{
+			Name:         "title",
+			Types:        map[string]int{"string": 4},
+			Count:        4,
+			Frequency:    1,
+			CommonValues: []ValueCount{{"Four", 1}, {"One", 1}, {"Three", 1}, {"Two", 1}},
+		},
+		{
+			Name:         "layout",
+			Types:        map[string]int{"string": 3, "stringSlice": 1},
+			Count:        4,
+			Frequency:    1,
+			CommonValues: []ValueCount{{"post", 3}, {"page", 1}},
+		},
+		{
+			Name:         "draft",
+			Types:        map[string]int{"bool": 1},
+			Count:        1,
+			Frequency:    0.25,
+			CommonValues: []ValueCount{{"false", 1}},
+		},
+	}
+	if !reflect.DeepEqual(inference.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", inference.Fields, want)
+	}
+
+	if empty := Infer(nil); empty.Sampled != 0 || len(empty.Fields) != 0 || len(empty.TemplateFields) != 0 {
+		t.Errorf("Infer(nil) = %+v, want an empty inference", empty)
+	}
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"static-admin/database"
+	"static-admin/markdown"
+)
+
+// typeNames are the names of field types in error messages
+var typeNames = map[string]string{
+	"string":      "string",
+	"bool":        "boolean",
+	"number":      "number",
+	"dateTime":    "date",
+	"stringSlice": "list",
+}
+
+// FieldError describes why a frontmatter field does not match the field of its template
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Check returns an error if the constraints of a template field contradict each other
+func Check(field database.TemplateField) error {
+	if field.Pattern != "" {
+		if _, err := regexp.Compile(field.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if field.MaxLength < 0 {
+		return errors.New("max length cannot be negative")
+	}
+	if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+		return errors.New("min cannot be greater than max")
+	}
+	if field.MinDate != nil && field.MaxDate != nil && field.MinDate.After(*field.MaxDate) {
+		return errors.New("min date cannot be after max date")
+	}
+	return nil
+}
+
+// Validate checks the frontmatter of a post against the fields of its template and returns an error for every
+// field that does not match. Fields the template does not define are not checked.
+func Validate(templateFields []database.TemplateField, frontmatter []markdown.FrontmatterField) []FieldError {
+	values := make(map[string]markdown.FrontmatterField, len(frontmatter))
+	for _, field := range frontmatter {
+		values[field.Name] = field
+	}
+
+	errs := []FieldError{}
+	for _, templateField := range templateFields {
+		value, ok := values[templateField.Name]
+		if !ok || isEmpty(value) {
+			if templateField.Required {
+				errs = append(errs, FieldError{Field: templateField.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		if message := validateField(templateField, value); message != "" {
+			errs = append(errs, FieldError{Field: templateField.Name, Message: message})
+		}
+	}
+	return errs
+}
+
+// isEmpty returns true if a frontmatter field has no value, booleans always have one
+func isEmpty(field markdown.FrontmatterField) bool {
+	switch field.Type {
+	case "string":
+		return field.StringValue == ""
+	case "dateTime":
+		return field.DateTimeValue.IsZero()
+	case "stringSlice":
+		return len(field.StringSliceValue) == 0
+	}
+	return false
+}
+
+// validateField returns the first constraint of a template field a frontmatter value breaks, empty if none
+func validateField(templateField database.TemplateField, value markdown.FrontmatterField) string {
+	if value.Type != templateField.Type {
+		return fmt.Sprintf("must be a %s", typeNames[templateField.Type])
+	}
+
+	switch templateField.Type {
+	case "string":
+		return validateString(templateField, value.StringValue)
+	case "stringSlice":
+		for _, item := range value.StringSliceValue {
+			if message := validateString(templateField, item); message != "" {
+				return fmt.Sprintf("%q %s", item, message)
+			}
+		}
+	case "number":
+		if templateField.Min != nil && value.NumberValue < *templateField.Min {
+			return fmt.Sprintf("must be at least %g", *templateField.Min)
+		}
+		if templateField.Max != nil && value.NumberValue > *templateField.Max {
+			return fmt.Sprintf("must be at most %g", *templateField.Max)
+		}
+	case "dateTime":
+		if templateField.MinDate != nil && value.DateTimeValue.Before(*templateField.MinDate) {
+			return fmt.Sprintf("must be on or after %s", formatDate(*templateField.MinDate))
+		}
+		if templateField.MaxDate != nil && value.DateTimeValue.After(*templateField.MaxDate) {
+			return fmt.Sprintf("must be on or before %s", formatDate(*templateField.MaxDate))
+		}
+	}
+	return ""
+}
+
+// validateString checks a string value against the allowed values, maximum length and pattern of a template field
+func validateString(templateField database.TemplateField, value string) string {
+	if len(templateField.AllowedValues) > 0 {
+		allowed := false
+		for _, allowedValue := range templateField.AllowedValues {
+			if value == allowedValue {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("must be one of %s", strings.Join(templateField.AllowedValues, ", "))
+		}
+	}
+	if templateField.MaxLength > 0 && utf8.RuneCountInString(value) > templateField.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", templateField.MaxLength)
+	}
+	if templateField.Pattern != "" {
+		// patterns are checked when templates are saved, an invalid one does not block posts
+		if pattern, err := regexp.Compile(templateField.Pattern); err == nil && !pattern.MatchString(value) {
+			return fmt.Sprintf("must match %s", templateField.Pattern)
+		}
+	}
+	return ""
+}
+
+// formatDate formats a date bound, leaving out the time of bounds at midnight
+func formatDate(date time.Time) string {
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.Format(time.RFC3339)
+}
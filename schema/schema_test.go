@@ -0,0 +1,288 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"static-admin/database"
+	"static-admin/markdown"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func date(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func stringField(name, value string) markdown.FrontmatterField {
+	return markdown.FrontmatterField{Name: name, Type: "string", StringValue: value}
+}
+
+func sliceField(name string, values ...string) markdown.FrontmatterField {
+	return markdown.FrontmatterField{Name: name, Type: "stringSlice", StringSliceValue: values}
+}
+
+func numberField(name string, value float64) markdown.FrontmatterField {
+	return markdown.FrontmatterField{Name: name, Type: "number", NumberValue: value}
+}
+
+func boolField(name string, value bool) markdown.FrontmatterField {
+	return markdown.FrontmatterField{Name: name, Type: "bool", BoolValue: value}
+}
+
+func dateField(name, value string) markdown.FrontmatterField {
+	field := markdown.FrontmatterField{Name: name, Type: "dateTime"}
+	if value != "" {
+		field.DateTimeValue = *date(value)
+	}
+	return field
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []database.TemplateField
+		frontmatter []markdown.FrontmatterField
+		want        []FieldError
+	}{
+		{
+			name:        "no template fields",
+			frontmatter: []markdown.FrontmatterField{stringField("title", "Hello")},
+			want:        []FieldError{},
+		},
+		{
+			name:   "required field missing",
+			fields: []database.TemplateField{{Name: "title", Type: "string", Required: true}},
+			want:   []FieldError{{Field: "title", Message: "is required"}},
+		},
+		{
+			name:        "required string empty",
+			fields:      []database.TemplateField{{Name: "title", Type: "string", Required: true}},
+			frontmatter: []markdown.FrontmatterField{stringField("title", "")},
+			want:        []FieldError{{Field: "title", Message: "is required"}},
+		},
+		{
+			name:        "required list empty",
+			fields:      []database.TemplateField{{Name: "tags", Type: "stringSlice", Required: true}},
+			frontmatter: []markdown.FrontmatterField{sliceField("tags")},
+			want:        []FieldError{{Field: "tags", Message: "is required"}},
+		},
+		{
+			name:        "required date zero",
+			fields:      []database.TemplateField{{Name: "date", Type: "dateTime", Required: true}},
+			frontmatter: []markdown.FrontmatterField{dateField("date", "")},
+			want:        []FieldError{{Field: "date", Message: "is required"}},
+		},
+		{
+			name:        "required false boolean is set",
+			fields:      []database.TemplateField{{Name: "draft", Type: "bool", Required: true}},
+			frontmatter: []markdown.FrontmatterField{boolField("draft", false)},
+			want:        []FieldError{},
+		},
+		{
+			name:        "required zero number is set",
+			fields:      []database.TemplateField{{Name: "weight", Type: "number", Required: true}},
+			frontmatter: []markdown.FrontmatterField{numberField("weight", 0)},
+			want:        []FieldError{},
+		},
+		{
+			name: "optional empty values skip constraints",
+			fields: []database.TemplateField{
+				{Name: "layout", Type: "string", AllowedValues: database.StringSliceValue{"post"}, Pattern: "^p"},
+				{Name: "tags", Type: "stringSlice", AllowedValues: database.StringSliceValue{"go"}},
+				{Name: "subtitle", Type: "string", MaxLength: 3},
+			},
+			frontmatter: []markdown.FrontmatterField{stringField("layout", ""), sliceField("tags")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "allowed string value",
+			fields:      []database.TemplateField{{Name: "layout", Type: "string", AllowedValues: database.StringSliceValue{"post", "page"}}},
+			frontmatter: []markdown.FrontmatterField{stringField("layout", "page")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "disallowed string value",
+			fields:      []database.TemplateField{{Name: "layout", Type: "string", AllowedValues: database.StringSliceValue{"post", "page"}}},
+			frontmatter: []markdown.FrontmatterField{stringField("layout", "home")},
+			want:        []FieldError{{Field: "layout", Message: "must be one of post, page"}},
+		},
+		{
+			name:        "allowed values are case sensitive",
+			fields:      []database.TemplateField{{Name: "layout", Type: "string", AllowedValues: database.StringSliceValue{"post"}}},
+			frontmatter: []markdown.FrontmatterField{stringField("layout", "Post")},
+			want:        []FieldError{{Field: "layout", Message: "must be one of post"}},
+		},
+		{
+			name:        "allowed list items",
+			fields:      []database.TemplateField{{Name: "tags", Type: "stringSlice", AllowedValues: database.StringSliceValue{"go", "rust"}}},
+			frontmatter: []markdown.FrontmatterField{sliceField("tags", "rust", "go")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "disallowed list item",
+			fields:      []database.TemplateField{{Name: "tags", Type: "stringSlice", AllowedValues: database.StringSliceValue{"go", "rust"}}},
+			frontmatter: []markdown.FrontmatterField{sliceField("tags", "go", "zig", "c")},
+			want:        []FieldError{{Field: "tags", Message: `"zig" must be one of go, rust`}},
+		},
+		{
+			name:        "string within max length",
+			fields:      []database.TemplateField{{Name: "title", Type: "string", MaxLength: 5}},
+			frontmatter: []markdown.FrontmatterField{stringField("title", "héllo")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "string over max length",
+			fields:      []database.TemplateField{{Name: "title", Type: "string", MaxLength: 5}},
+			frontmatter: []markdown.FrontmatterField{stringField("title", "hello!")},
+			want:        []FieldError{{Field: "title", Message: "must be at most 5 characters"}},
+		},
+		{
+			name:        "string matching pattern",
+			fields:      []database.TemplateField{{Name: "slug", Type: "string", Pattern: "^[a-z-]+$"}},
+			frontmatter: []markdown.FrontmatterField{stringField("slug", "hello-world")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "string not matching pattern",
+			fields:      []database.TemplateField{{Name: "slug", Type: "string", Pattern: "^[a-z-]+$"}},
+			frontmatter: []markdown.FrontmatterField{stringField("slug", "Hello World")},
+			want:        []FieldError{{Field: "slug", Message: "must match ^[a-z-]+$"}},
+		},
+		{
+			name:        "invalid pattern is ignored",
+			fields:      []database.TemplateField{{Name: "slug", Type: "string", Pattern: "["}},
+			frontmatter: []markdown.FrontmatterField{stringField("slug", "anything")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "number within bounds",
+			fields:      []database.TemplateField{{Name: "rating", Type: "number", Min: float(1), Max: float(5)}},
+			frontmatter: []markdown.FrontmatterField{numberField("rating", 5)},
+			want:        []FieldError{},
+		},
+		{
+			name:        "number below min",
+			fields:      []database.TemplateField{{Name: "rating", Type: "number", Min: float(1), Max: float(5)}},
+			frontmatter: []markdown.FrontmatterField{numberField("rating", 0.5)},
+			want:        []FieldError{{Field: "rating", Message: "must be at least 1"}},
+		},
+		{
+			name:        "number above max",
+			fields:      []database.TemplateField{{Name: "rating", Type: "number", Max: float(4.5)}},
+			frontmatter: []markdown.FrontmatterField{numberField("rating", 5)},
+			want:        []FieldError{{Field: "rating", Message: "must be at most 4.5"}},
+		},
+		{
+			name:        "date within bounds",
+			fields:      []database.TemplateField{{Name: "date", Type: "dateTime", MinDate: date("2024-01-01T00:00:00Z"), MaxDate: date("2024-12-31T00:00:00Z")}},
+			frontmatter: []markdown.FrontmatterField{dateField("date", "2024-01-01T00:00:00Z")},
+			want:        []FieldError{},
+		},
+		{
+			name:        "date before min",
+			fields:      []database.TemplateField{{Name: "date", Type: "dateTime", MinDate: date("2024-01-01T00:00:00Z")}},
+			frontmatter: []markdown.FrontmatterField{dateField("date", "2023-12-31T23:59:00Z")},
+			want:        []FieldError{{Field: "date", Message: "must be on or after 2024-01-01"}},
+		},
+		{
+			name:        "date after max",
+			fields:      []database.TemplateField{{Name: "date", Type: "dateTime", MaxDate: date("2024-06-30T12:30:00Z")}},
+			frontmatter: []markdown.FrontmatterField{dateField("date", "2024-07-01T00:00:00Z")},
+			want:        []FieldError{{Field: "date", Message: "must be on or before 2024-06-30T12:30:00Z"}},
+		},
+		{
+			name: "wrong types",
+			fields: []database.TemplateField{
+				{Name: "title", Type: "string"},
+				{Name: "draft", Type: "bool"},
+				{Name: "weight", Type: "number"},
+				{Name: "date", Type: "dateTime"},
+				{Name: "tags", Type: "stringSlice"},
+			},
+			frontmatter: []markdown.FrontmatterField{
+				sliceField("title", "Hello"),
+				stringField("draft", "yes"),
+				stringField("weight", "3"),
+				stringField("date", "2024-01-01"),
+				stringField("tags", "go"),
+			},
+			want: []FieldError{
+				{Field: "title", Message: "must be a string"},
+				{Field: "draft", Message: "must be a boolean"},
+				{Field: "weight", Message: "must be a number"},
+				{Field: "date", Message: "must be a date"},
+				{Field: "tags", Message: "must be a list"},
+			},
+		},
+		{
+			name: "mixed types",
+			fields: []database.TemplateField{
+				{Name: "title", Type: "string", Required: true, MaxLength: 20},
+				{Name: "date", Type: "dateTime", Required: true},
+				{Name: "layout", Type: "string", AllowedValues: database.StringSliceValue{"post"}},
+				{Name: "tags", Type: "stringSlice", Required: true, AllowedValues: database.StringSliceValue{"go"}},
+				{Name: "rating", Type: "number", Min: float(1)},
+				{Name: "draft", Type: "bool"},
+				{Name: "summary", Type: "string", Required: true},
+			},
+			frontmatter: []markdown.FrontmatterField{
+				stringField("title", "Hello"),
+				dateField("date", "2024-01-01T10:00:00Z"),
+				boolField("layout", true),
+				sliceField("tags", "go", "python"),
+				numberField("rating", 0),
+				boolField("draft", true),
+				stringField("extra", "not in the template"),
+			},
+			want: []FieldError{
+				{Field: "layout", Message: "must be a string"},
+				{Field: "tags", Message: `"python" must be one of go`},
+				{Field: "rating", Message: "must be at least 1"},
+				{Field: "summary", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(tt.fields, tt.frontmatter)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   database.TemplateField
+		wantErr bool
+	}{
+		{"no constraints", database.TemplateField{Name: "title", Type: "string"}, false},
+		{"valid pattern", database.TemplateField{Pattern: "^[a-z]+$"}, false},
+		{"invalid pattern", database.TemplateField{Pattern: "[a-z"}, true},
+		{"negative max length", database.TemplateField{MaxLength: -1}, true},
+		{"equal bounds", database.TemplateField{Min: float(1), Max: float(1)}, false},
+		{"min above max", database.TemplateField{Min: float(2), Max: float(1)}, true},
+		{"only min", database.TemplateField{Min: float(2)}, false},
+		{"date bounds", database.TemplateField{MinDate: date("2024-01-01T00:00:00Z"), MaxDate: date("2024-01-02T00:00:00Z")}, false},
+		{"min date after max date", database.TemplateField{MinDate: date("2024-01-02T00:00:00Z"), MaxDate: date("2024-01-01T00:00:00Z")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.field); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package siteconfig
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"static-admin/database"
+	"static-admin/generator"
+	"static-admin/provider"
+	"static-admin/schema"
+
+	"github.com/gosimple/slug"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Directory is the directory of a site holding its admin configuration, relative to the site root
+	Directory = ".static-admin"
+
+	// SettingsPath is the file holding the settings of a site
+	SettingsPath = Directory + "/config.yml"
+
+	// TemplatesDirectory is the directory holding one file per template
+	TemplatesDirectory = Directory + "/templates"
+)
+
+// fieldTypes are the types a template field can have
+var fieldTypes = map[string]bool{
+	"string":      true,
+	"bool":        true,
+	"number":      true,
+	"dateTime":    true,
+	"stringSlice": true,
+}
+
+// Settings represents the per-site settings of the configuration file, empty values keep the setting of the site
+type Settings struct {
+	PublishingMode string `yaml:"publishing_mode,omitempty"`
+	StagingBranch  string `yaml:"staging_branch,omitempty"`
+	Generator      string `yaml:"generator,omitempty"`
+}
+
+// Template represents a template defined in a file of the templates directory
+type Template struct {
+	// Path is the file the template is defined in, relative to the site root
+	Path string `yaml:"-"`
+
+	Name       string  `yaml:"name"`
+	Collection string  `yaml:"collection,omitempty"`
+	Default    bool    `yaml:"default,omitempty"`
+	Fields     []Field `yaml:"fields"`
+}
+
+// Field represents a field of a template, whose value is the default value of the field in new posts.
+// The other keys constrain the values of the field in the frontmatter of posts.
+type Field struct {
+	Name  string      `yaml:"name"`
+	Type  string      `yaml:"type"`
+	Value interface{} `yaml:"value,omitempty"`
+
+	Required      bool        `yaml:"required,omitempty"`
+	AllowedValues []string    `yaml:"allowed_values,omitempty"`
+	Min           *float64    `yaml:"min,omitempty"`
+	Max           *float64    `yaml:"max,omitempty"`
+	Pattern       string      `yaml:"pattern,omitempty"`
+	MaxLength     int         `yaml:"max_length,omitempty"`
+	MinDate       interface{} `yaml:"min_date,omitempty"`
+	MaxDate       interface{} `yaml:"max_date,omitempty"`
+	Help          string      `yaml:"help,omitempty"`
+}
+
+// Config represents the configuration of a site read from its repository
+type Config struct {
+	// Settings is nil when the repository has no settings file
+	Settings *Settings
+
+	// Managed is true when the repository defines the templates of the site, which is the case once it has
+	// a settings file or a template file. Templates of other sites are only managed in the admin.
+	Managed   bool
+	Templates []Template
+}
+
+// ValidationError describes a problem with a configuration file
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned when configuration files are invalid
+type ValidationErrors []ValidationError
+
+// Error joins the problems of every configuration file
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Path + ": " + err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// IsConfigFile returns true if a path relative to the site root is read as part of the configuration
+func IsConfigFile(filePath string) bool {
+	if filePath == SettingsPath {
+		return true
+	}
+	ext := path.Ext(filePath)
+	return path.Dir(filePath) == TemplatesDirectory && (ext == ".yml" || ext == ".yaml")
+}
+
+// TemplatePath returns the file a template created in the admin is written to
+func TemplatePath(name string) string {
+	return TemplatesDirectory + "/" + slug.Make(name) + ".yml"
+}
+
+// Load reads and validates the configuration of a site at a commit. The provider must be scoped to the site root.
+func Load(ctx context.Context, p provider.Provider, repo provider.Repo, commitSHA string) (Config, error) {
+	tree, err := p.Tree(ctx, repo, commitSHA)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	entries := []provider.TreeEntry{}
+	for _, entry := range tree {
+		if entry.Type == "blob" && IsConfigFile(entry.Path) {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return Config{}, nil
+	}
+
+	blobs, err := p.ReadBlobs(ctx, repo, commitSHA, entries)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read configuration files: %w", err)
+	}
+
+	files := make(map[string]string, len(blobs))
+	for filePath, blob := range blobs {
+		files[filePath] = blob.Text
+	}
+	return Parse(files)
+}
+
+// Parse reads the configuration of a site from the contents of its configuration files, keyed by their path
+// relative to the site root. Every problem found is returned as ValidationErrors.
+func Parse(files map[string]string) (Config, error) {
+	config := Config{}
+	var errs ValidationErrors
+
+	paths := make([]string, 0, len(files))
+	for filePath := range files {
+		if IsConfigFile(filePath) {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+
+	names := map[string]string{}
+	defaults := map[string]string{}
+	for _, filePath := range paths {
+		config.Managed = true
+
+		if filePath == SettingsPath {
+			var settings Settings
+			if err := yaml.UnmarshalStrict([]byte(files[filePath]), &settings); err != nil {
+				errs = append(errs, ValidationError{Path: filePath, Message: err.Error()})
+				continue
+			}
+			errs = append(errs, validateSettings(filePath, settings)...)
+			config.Settings = &settings
+			continue
+		}
+
+		var template Template
+		if err := yaml.UnmarshalStrict([]byte(files[filePath]), &template); err != nil {
+			errs = append(errs, ValidationError{Path: filePath, Message: err.Error()})
+			continue
+		}
+		template.Path = filePath
+
+		collection, ok := database.NormalizeRootPath(template.Collection)
+		if !ok {
+			errs = append(errs, ValidationError{Path: filePath, Message: "collection must be a directory of the site"})
+		}
+		template.Collection = collection
+
+		if template.Name == "" {
+			errs = append(errs, ValidationError{Path: filePath, Message: "name is required"})
+		} else if other, ok := names[template.Name]; ok {
+			errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("name %q is already used by %s", template.Name, other)})
+		}
+		names[template.Name] = filePath
+
+		if template.Default {
+			if other, ok := defaults[template.Collection]; ok {
+				errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("%s is already the default template of this collection", other)})
+			}
+			defaults[template.Collection] = filePath
+		}
+
+		errs = append(errs, validateFields(filePath, template.Fields)...)
+		config.Templates = append(config.Templates, template)
+	}
+
+	if len(errs) > 0 {
+		return config, errs
+	}
+	return config, nil
+}
+
+// validateSettings checks the values of the settings file
+func validateSettings(filePath string, settings Settings) []ValidationError {
+	var errs []ValidationError
+	if settings.PublishingMode != "" && !database.ValidPublishingMode(settings.PublishingMode) {
+		errs = append(errs, ValidationError{Path: filePath, Message: "publishing_mode must be one of pull_request, direct or staging"})
+	}
+	if settings.Generator != "" && !generator.Valid(settings.Generator) {
+		errs = append(errs, ValidationError{Path: filePath, Message: "generator must be one of jekyll, hugo, eleventy or other"})
+	}
+	return errs
+}
+
+// validateFields checks that the fields of a template have unique names, known types and values of their type
+func validateFields(filePath string, fields []Field) []ValidationError {
+	var errs []ValidationError
+	seen := map[string]bool{}
+	for i, field := range fields {
+		if field.Name == "" {
+			errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("fields[%d]: name is required", i)})
+			continue
+		}
+		if seen[field.Name] {
+			errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("field %s is defined twice", field.Name)})
+		}
+		seen[field.Name] = true
+
+		if !fieldTypes[field.Type] {
+			errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("field %s: type must be one of string, bool, number, dateTime or stringSlice", field.Name)})
+			continue
+		}
+		templateField, err := field.TemplateField()
+		if err == nil {
+			err = schema.Check(templateField)
+		}
+		if err != nil {
+			errs = append(errs, ValidationError{Path: filePath, Message: fmt.Sprintf("field %s: %v", field.Name, err)})
+		}
+	}
+	return errs
+}
+
+// TemplateField converts a field to the database representation of template fields
+func (f Field) TemplateField() (database.TemplateField, error) {
+	field := database.TemplateField{
+		Name:             f.Name,
+		Type:             f.Type,
+		StringSliceValue: database.StringSliceValue{},
+		Required:         f.Required,
+		AllowedValues:    database.StringSliceValue(f.AllowedValues),
+		Min:              f.Min,
+		Max:              f.Max,
+		Pattern:          f.Pattern,
+		MaxLength:        f.MaxLength,
+		HelpText:         f.Help,
+	}
+	if field.AllowedValues == nil {
+		field.AllowedValues = database.StringSliceValue{}
+	}
+	if f.MinDate != nil {
+		minDate, err := parseDateTime(f.MinDate)
+		if err != nil {
+			return field, fmt.Errorf("min_date: %w", err)
+		}
+		field.MinDate = &minDate
+	}
+	if f.MaxDate != nil {
+		maxDate, err := parseDateTime(f.MaxDate)
+		if err != nil {
+			return field, fmt.Errorf("max_date: %w", err)
+		}
+		field.MaxDate = &maxDate
+	}
+	if f.Value == nil {
+		return field, nil
+	}
+
+	switch f.Type {
+	case "string":
+		value, ok := f.Value.(string)
+		if !ok {
+			return field, fmt.Errorf("value must be a string")
+		}
+		field.StringValue = value
+	case "bool":
+		value, ok := f.Value.(bool)
+		if !ok {
+			return field, fmt.Errorf("value must be true or false")
+		}
+		field.BoolValue = value
+	case "number":
+		switch value := f.Value.(type) {
+		case int:
+			field.NumberValue = float64(value)
+		case int64:
+			field.NumberValue = float64(value)
+		case uint64:
+			field.NumberValue = float64(value)
+		case float64:
+			field.NumberValue = value
+		default:
+			return field, fmt.Errorf("value must be a number")
+		}
+	case "dateTime":
+		value, err := parseDateTime(f.Value)
+		if err != nil {
+			return field, err
+		}
+		field.DateTimeValue = value
+	case "stringSlice":
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return field, fmt.Errorf("value must be a list of strings")
+		}
+		for _, item := range values {
+			value, ok := item.(string)
+			if !ok {
+				return field, fmt.Errorf("value must be a list of strings")
+			}
+			field.StringSliceValue = append(field.StringSliceValue, value)
+		}
+	}
+	return field, nil
+}
+
+// parseDateTime reads a date and time value written as a date or an RFC 3339 timestamp
+func parseDateTime(value interface{}) (time.Time, error) {
+	switch typed := value.(type) {
+	case time.Time:
+		return typed, nil
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+			if parsed, err := time.Parse(layout, typed); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("value must be a date such as 2006-01-02 or 2006-01-02T15:04:05Z")
+}
+
+// NewField converts a template field from the database to its representation in a template file,
+// leaving out values and constraints that are the zero value of their type
+func NewField(field database.TemplateField) Field {
+	result := Field{
+		Name:          field.Name,
+		Type:          field.Type,
+		Required:      field.Required,
+		AllowedValues: []string(field.AllowedValues),
+		Min:           field.Min,
+		Max:           field.Max,
+		Pattern:       field.Pattern,
+		MaxLength:     field.MaxLength,
+		Help:          field.HelpText,
+	}
+	if field.MinDate != nil {
+		result.MinDate = field.MinDate.Format(time.RFC3339)
+	}
+	if field.MaxDate != nil {
+		result.MaxDate = field.MaxDate.Format(time.RFC3339)
+	}
+	switch field.Type {
+	case "string":
+		if field.StringValue != "" {
+			result.Value = field.StringValue
+		}
+	case "bool":
+		if field.BoolValue {
+			result.Value = true
+		}
+	case "number":
+		if field.NumberValue != 0 {
+			result.Value = field.NumberValue
+		}
+	case "dateTime":
+		if !field.DateTimeValue.IsZero() {
+			result.Value = field.DateTimeValue.Format(time.RFC3339)
+		}
+	case "stringSlice":
+		if len(field.StringSliceValue) > 0 {
+			result.Value = []string(field.StringSliceValue)
+		}
+	}
+	return result
+}
+
+// Encode writes a template as the contents of a template file
+func Encode(template Template) (string, error) {
+	content, err := yaml.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,126 @@
+package siteconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"static-admin/database"
+	"static-admin/provider"
+
+	"gorm.io/gorm"
+)
+
+// Sync loads the configuration of a site at the head of its branch and stores it in the database. Invalid
+// configurations are recorded on the site and leave the previously stored configuration in place.
+func Sync(ctx context.Context, db *gorm.DB, site *database.Site, token string) error {
+	p, repo, err := provider.RepoForSite(*site, token)
+	if err != nil {
+		return err
+	}
+
+	head, err := p.HeadCommit(ctx, repo, site.Branch())
+	if err != nil {
+		return fmt.Errorf("failed to fetch head commit: %w", err)
+	}
+
+	config, err := Load(ctx, p, repo, head)
+	var validationErrors ValidationErrors
+	if errors.As(err, &validationErrors) {
+		if updateErr := db.Model(site).Update("config_error", validationErrors.Error()).Error; updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return Apply(db, site, config)
+}
+
+// Apply stores a valid configuration in the database, which caches the configuration of the repository.
+// The settings of the configuration override the settings of the site. The templates of a site whose
+// repository defines templates are replaced by the templates of the repository, matched by file so
+// that the IDs of unchanged templates are kept.
+func Apply(db *gorm.DB, site *database.Site, config Config) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"config_error": ""}
+		if settings := config.Settings; settings != nil {
+			if settings.PublishingMode != "" {
+				updates["publishing_mode"] = settings.PublishingMode
+				if settings.PublishingMode == database.PublishingModeStaging && settings.StagingBranch == "" && site.StagingBranch == "" {
+					updates["staging_branch"] = "staging"
+				}
+			}
+			if settings.StagingBranch != "" {
+				updates["staging_branch"] = settings.StagingBranch
+			}
+			if settings.Generator != "" {
+				updates["generator"] = settings.Generator
+			}
+		}
+		if err := tx.Model(site).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if !config.Managed {
+			return nil
+		}
+
+		var existing []database.Template
+		if err := database.SiteTemplates(tx, site.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byPath := make(map[string]database.Template, len(existing))
+		for _, template := range existing {
+			if template.Path != "" {
+				byPath[template.Path] = template
+			}
+		}
+
+		kept := map[uint]bool{}
+		for _, template := range config.Templates {
+			record, ok := byPath[template.Path]
+			if !ok {
+				record = database.Template{UserID: site.UserID, SiteID: &site.ID, Path: template.Path}
+			}
+			record.Name = template.Name
+			record.Collection = template.Collection
+			record.Default = template.Default
+			if err := tx.Save(&record).Error; err != nil {
+				return err
+			}
+			kept[record.ID] = true
+
+			if err := tx.Where("template_id = ?", record.ID).Delete(&database.TemplateField{}).Error; err != nil {
+				return err
+			}
+			for _, field := range template.Fields {
+				templateField, err := field.TemplateField()
+				if err != nil {
+					return err
+				}
+				templateField.TemplateID = record.ID
+				if err := tx.Create(&templateField).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// templates missing from the repository, including templates created in the admin whose
+		// pull request is not merged yet, are removed
+		for _, record := range existing {
+			if kept[record.ID] {
+				continue
+			}
+			if err := tx.Where("template_id = ?", record.ID).Delete(&database.TemplateField{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
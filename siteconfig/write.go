@@ -0,0 +1,71 @@
+package siteconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"static-admin/database"
+	"static-admin/provider"
+	"static-admin/publisher"
+)
+
+// WriteInput represents a template changed in the admin to write back to the repository of its site
+type WriteInput struct {
+	Site     database.Site
+	Token    string
+	Template database.Template
+	Fields   []database.TemplateField
+
+	// Delete removes the file of the template instead of writing it
+	Delete bool
+}
+
+// WriteTemplate opens a pull request writing a template changed in the admin to its file in the repository.
+// Configuration changes always go through a pull request, whatever the publishing mode of the site.
+func WriteTemplate(ctx context.Context, input WriteInput) (publisher.CommitResult, error) {
+	owner, repo, ok := input.Site.OwnerAndRepo()
+	if !ok {
+		return publisher.CommitResult{}, errors.New("invalid repository URL")
+	}
+
+	filePath := input.Template.Path
+	if filePath == "" {
+		filePath = TemplatePath(input.Template.Name)
+	}
+
+	change := provider.FileChange{Path: filePath, Delete: input.Delete}
+	action := "Delete"
+	if !input.Delete {
+		template := Template{
+			Name:       input.Template.Name,
+			Collection: input.Template.Collection,
+			Default:    input.Template.Default,
+			Fields:     make([]Field, len(input.Fields)),
+		}
+		for i, field := range input.Fields {
+			template.Fields[i] = NewField(field)
+		}
+
+		content, err := Encode(template)
+		if err != nil {
+			return publisher.CommitResult{}, err
+		}
+		change.Content = content
+		action = "Update"
+	}
+
+	site := input.Site
+	site.PublishingMode = database.PublishingModePullRequest
+	return publisher.Commit(ctx, publisher.CommitInput{
+		Site:         site,
+		Owner:        owner,
+		Repo:         repo,
+		Token:        input.Token,
+		ReviewBranch: publisher.ReviewBranch(site, filePath),
+		Title:        fmt.Sprintf("%s template %s", action, input.Template.Name),
+		Body:         fmt.Sprintf("%ss the `%s` template in `%s`, changed in the admin.", action, input.Template.Name, site.RepositoryPath(filePath)),
+		CommitMsg:    fmt.Sprintf("%s template %s", action, input.Template.Name),
+		Changes:      []provider.FileChange{change},
+	})
+}